@@ -4,10 +4,13 @@ import (
 	"Backend/internal/db"
 	"Backend/internal/schema"
 	"Backend/internal/helpers"
+	"Backend/internal/notify"
 	"context"
+	"errors"
 	"net/http"
 	"strconv"
 	"fmt"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -29,44 +32,28 @@ func CreateApplicationHandler(c *gin.Context) {
 	// Step 2: Assign the generated ID to the application struct
 	application.ID = newID
 
-	// Step 3: Create the application in the database
-	result, err := db.CreateApplication(context.Background(), application)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create application"})
+	// Step 3: Create the application in the database, along with the job seeker's notification
+	result, err := db.CreateApplication(context.Background(), application, func(created schema.Application) (schema.Outbox, error) {
+		return notify.Prepare(context.Background(), notify.Notification{
+			Event:    notify.EventApplicationSubmitted,
+			UserType: "job_seeker",
+			UserID:   created.JobSeekerID,
+			Message:  fmt.Sprintf("Your application %d has been created successfully.", created.ID),
+		})
+	})
+	if errors.Is(err, db.ErrResumeNotFound) || errors.Is(err, db.ErrResumeNotOwned) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-
-	// Create a notification for the job seeker
-	message := fmt.Sprintf("Your application %d has been created successfully.", application.ID)
-	notificationID, err := db.GenerateNotificationID(context.Background())
-	notification := schema.Notification{
-		ID:       notificationID,
-		UserID:   application.JobSeekerID,
-		UserType: "job_seeker",
-		Message:  message,
-		IsRead:   false,
+	if errors.Is(err, db.ErrAlreadyApplied) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
 	}
-
-	err = db.StoreNotification(context.Background(), notification)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store notification"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create application"})
 		return
 	}
 
-	// Send mail notification in a goroutine
-	go func() {
-		jobseeker, err := db.GetJobSeeker(c, application.JobSeekerID)
-		if err != nil {
-			fmt.Println("Failed to fetch job seeker:", err)
-			return
-		}
-
-		err = helpers.SendMail(jobseeker.Email, message)
-		if err != nil {
-			fmt.Println("Failed to send email:", err)
-		}
-	}()
-
 	c.JSON(http.StatusOK, gin.H{"message": "Application created successfully", "application": result})
 }
 
@@ -87,6 +74,8 @@ func GetSeekerApplicationHandler(c *gin.Context) {
 }
 
 
+// GetJobApplicationHandler returns the applications of a job. Employers
+// signed in to the hiring company also get the private notes and ratings.
 func GetJobApplicationHandler(c *gin.Context) {
 	jobID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -101,9 +90,74 @@ func GetJobApplicationHandler(c *gin.Context) {
 		return
 	}
 
+	if employerID := c.GetInt("user_id"); employerID != 0 && c.GetString("user_type") == "employer" {
+		reviewer, err := db.EmployerCanAccessJob(context.Background(), employerID, jobID)
+		if err == nil && reviewer {
+			err = db.AddApplicationReviews(context.Background(), applications)
+		}
+		if err != nil {
+			fmt.Println(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve applications"})
+			return
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{"applications": applications})
 }
 
+// GetRankedApplicationsHandler scores the applications of a job against its
+// requirements. Supports ?min_score= to filter and ?sort=score|applied_date
+// with ?order=asc|desc (default: score, descending).
+func GetRankedApplicationsHandler(c *gin.Context) {
+	jobID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job listing ID"})
+		return
+	}
+
+	minScore := 0.0
+	if value := c.Query("min_score"); value != "" {
+		minScore, err = strconv.ParseFloat(value, 64)
+		if err != nil || minScore < 0 || minScore > 100 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "min_score must be a number between 0 and 100"})
+			return
+		}
+	}
+	sortBy := c.DefaultQuery("sort", "score")
+	order := c.DefaultQuery("order", "desc")
+	if (sortBy != "score" && sortBy != "applied_date") || (order != "asc" && order != "desc") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sort must be score or applied_date and order must be asc or desc"})
+		return
+	}
+
+	requirements, err := db.GetJobRequirements(jobID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve job requirements"})
+		return
+	}
+
+	applications, err := db.GetJobApplications(context.Background(), jobID)
+	if err != nil {
+		fmt.Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve applications"})
+		return
+	}
+
+	parsed := helpers.ParseRequirements(requirements)
+	now := time.Now()
+	ranked := []schema.RankedApplication{}
+	for _, application := range applications {
+		scored := helpers.ScoreApplication(parsed, application, now)
+		if scored.Score >= minScore {
+			ranked = append(ranked, scored)
+		}
+	}
+
+	helpers.SortRankedApplications(ranked, sortBy, order == "desc")
+
+	c.JSON(http.StatusOK, gin.H{"requirements": requirements, "applications": ranked})
+}
+
 func GetAcceptedApplicationHandler(c *gin.Context) {
 	seekerID, err := strconv.Atoi(c.Param("id"))
 	
@@ -138,6 +192,9 @@ func GetRejectedApplicationHandler(c *gin.Context) {
 }
 
 
+// UpdateApplicationStatusHandler accepts or rejects an application. Rejections
+// may carry a standard reason code, use one of the company's message
+// templates and hold the candidate's message back for delay_minutes.
 func UpdateApplicationStatusHandler(c *gin.Context) {
 	// Parse application ID from URL parameter
 	applicationID, err := strconv.Atoi(c.Param("id"))
@@ -148,7 +205,11 @@ func UpdateApplicationStatusHandler(c *gin.Context) {
 
 	// Parse request body for new status
 	var requestBody struct {
-		Status string `json:"status"`
+		Status       string  `json:"status"`
+		Reason       *string `json:"reason"`
+		ReasonCode   *string `json:"reason_code"`
+		TemplateID   *int    `json:"template_id"`
+		DelayMinutes int     `json:"delay_minutes"`
 	}
 	if err := c.ShouldBindJSON(&requestBody); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
@@ -160,53 +221,104 @@ func UpdateApplicationStatusHandler(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status. Allowed values: 'Accepted' or 'Rejected'"})
 		return
 	}
-
-	// Update application status in the database
-	updatedApplication, err := db.UpdateApplicationStatus(context.Background(), applicationID, requestBody.Status)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update application status"})
-		fmt.Println(1,err)
+	if requestBody.Status != "Rejected" && (requestBody.ReasonCode != nil || requestBody.TemplateID != nil || requestBody.DelayMinutes != 0) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reason_code, template_id and delay_minutes apply to rejections only"})
 		return
 	}
-
-	// Delete the interview related to the application
-	err = db.DeleteInterview(context.Background(), applicationID)
-	if err != nil {
-		fmt.Println(2,err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete interview"})
+	if !validRejectionOptions(c, requestBody.ReasonCode, requestBody.DelayMinutes) {
 		return
 	}
 
-	// Create a notification for the job seeker
-	message := fmt.Sprintf("Your application %d has been %s.", applicationID, requestBody.Status)
-	notificationID, err := db.GenerateNotificationID(context.Background())
-	notification := schema.Notification{
-		ID:       notificationID,
-		UserID:   updatedApplication.JobSeekerID,
-		UserType: "job_seeker",
-		Message:  message,
-		IsRead:   false,
-	}
-
-	err = db.StoreNotification(context.Background(), notification)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store notification"})
-		return
+	// A signed-in employer of the hiring company is recorded as the actor and
+	// may use the company's templates
+	var employerID *int
+	if signedIn := c.GetInt("user_id"); signedIn != 0 && c.GetString("user_type") == "employer" {
+		if !authorizeApplicationReviewer(c, signedIn, applicationID) {
+			return
+		}
+		employerID = &signedIn
 	}
 
-	// Send mail notification in a goroutine
-	go func() {
-		jobseeker, err := db.GetJobSeeker(c, updatedApplication.JobSeekerID)
+	// Resolve the message template before anything is changed
+	var template *schema.MessageTemplate
+	if requestBody.TemplateID != nil {
+		if employerID == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Sign in as an employer to use a template"})
+			return
+		}
+		stored, err := db.GetMessageTemplate(context.Background(), *requestBody.TemplateID, *employerID)
+		if errors.Is(err, db.ErrTemplateNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
 		if err != nil {
-			fmt.Println("Failed to fetch job seeker:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve message template"})
 			return
 		}
+		template = &stored
+	}
 
-		err = helpers.SendMail(jobseeker.Email, message)
+	// Render the job seeker's message before anything is changed
+	message := fmt.Sprintf("Your application %d has been %s.", applicationID, requestBody.Status)
+	if template != nil {
+		contacts, err := db.GetApplicationContacts(context.Background(), []int{applicationID})
 		if err != nil {
-			fmt.Println("Failed to send email:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render message template"})
+			return
 		}
-	}()
+		message = helpers.RenderTemplate(template.Body, helpers.ContactTemplateValues(contacts[applicationID]))
+	}
+
+	// The message and the cancellations of pending interviews are stored with
+	// the decision; a delayed message is held for the scheduled notification worker
+	actor := schema.Actor{Type: "employer", ID: employerID}
+	sendAt := time.Now().Add(time.Duration(requestBody.DelayMinutes) * time.Minute)
+	var cancelled []schema.Interview
+	updatedApplication, err := db.UpdateApplicationStatus(context.Background(), applicationID, requestBody.Status, actor, requestBody.Reason, requestBody.ReasonCode,
+		func(application schema.Application, interviews []schema.Interview) (schema.Outbox, error) {
+			cancelled = interviews
+			outbox, err := interviewCancellations(interviews)
+			if err != nil {
+				return outbox, err
+			}
+			if requestBody.DelayMinutes > 0 {
+				outbox.Scheduled = append(outbox.Scheduled, schema.ScheduledNotification{
+					ApplicationID: &application.ID,
+					UserID:        application.JobSeekerID,
+					UserType:      "job_seeker",
+					Message:       message,
+					SendAt:        sendAt,
+				})
+				return outbox, nil
+			}
+			prepared, err := notify.Prepare(context.Background(), notify.Notification{
+				Event:    notify.EventApplicationStatusChanged,
+				UserType: "job_seeker",
+				UserID:   application.JobSeekerID,
+				Message:  message,
+			})
+			outbox.Add(prepared)
+			return outbox, err
+		})
+	if errors.Is(err, db.ErrApplicationWithdrawn) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Application has been withdrawn"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update application status"})
+		fmt.Println(1,err)
+		return
+	}
+	revokeInterviewMeetings(cancelled)
+
+	if requestBody.DelayMinutes > 0 {
+		c.JSON(http.StatusOK, gin.H{
+			"message":             "Application status updated successfully",
+			"application":         updatedApplication,
+			"notification_send_at": sendAt,
+		})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"message":     "Application status updated successfully",
@@ -214,6 +326,47 @@ func UpdateApplicationStatusHandler(c *gin.Context) {
 	})
 }
 
+// maxMessageDelayMinutes caps how long a rejection message may be held back (one week)
+const maxMessageDelayMinutes = 7 * 24 * 60
+
+// validRejectionOptions checks a rejection's reason code and message delay and
+// writes an error response if either is invalid
+func validRejectionOptions(c *gin.Context, reasonCode *string, delayMinutes int) bool {
+	if reasonCode != nil && !schema.ValidRejectionReason(*reasonCode) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":         "Invalid reason_code",
+			"allowed_codes": schema.RejectionReasonCodes,
+		})
+		return false
+	}
+	if delayMinutes < 0 || delayMinutes > maxMessageDelayMinutes {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("delay_minutes must be between 0 and %d", maxMessageDelayMinutes)})
+		return false
+	}
+	return true
+}
+
+// GetRejectionReportHandler counts a job's rejections per reason code for its hiring company
+func GetRejectionReportHandler(c *gin.Context) {
+	jobID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job listing ID"})
+		return
+	}
+
+	if !authorizeSignedInJobReviewer(c, jobID) {
+		return
+	}
+
+	counts, err := db.GetRejectionReasonCounts(context.Background(), jobID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve rejection report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"reasons": counts})
+}
+
 // GetSeekerApplicationCountHandler handles the request to get the count of a seeker's applications
 func GetSeekerApplicationCountHandler(c *gin.Context) {
 	seekerID, err := strconv.Atoi(c.Param("id"))
@@ -245,4 +398,135 @@ func GetResultCountHandler(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{"job_seeker_id": seekerID, "result_count": count})
-}
\ No newline at end of file
+}
+
+// GetSeekerTimelineHandler returns the timeline of an application as seen by
+// the job seeker who submitted it
+func GetSeekerTimelineHandler(c *gin.Context) {
+	applicationID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid application ID"})
+		return
+	}
+	seekerID, ok := callerID(c)
+	if !ok {
+		return
+	}
+
+	application, err := db.GetApplication(context.Background(), applicationID)
+	if err != nil || application.JobSeekerID != seekerID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Application not found"})
+		return
+	}
+
+	events, err := db.GetApplicationHistory(context.Background(), applicationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve timeline"})
+		return
+	}
+
+	timeline := helpers.SeekerTimeline(events)
+	c.JSON(http.StatusOK, gin.H{
+		"application_id": applicationID,
+		"timeline":       timeline,
+		"time_in_stage":  helpers.ComputeTimeInStage(timeline, time.Now()),
+	})
+}
+
+// GetEmployerTimelineHandler returns the full history of an application,
+// including internal stages, actors and reasons. Only employers reviewing the
+// application's job may see it.
+func GetEmployerTimelineHandler(c *gin.Context) {
+	applicationID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid application ID"})
+		return
+	}
+
+	employerID, ok := callerID(c)
+	if !ok || !authorizeApplicationReviewer(c, employerID, applicationID) {
+		return
+	}
+
+	events, err := db.GetApplicationHistory(context.Background(), applicationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve timeline"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"application_id": applicationID,
+		"timeline":       events,
+		"time_in_stage":  helpers.ComputeTimeInStage(events, time.Now()),
+	})
+}
+
+// WithdrawApplicationHandler lets a job seeker withdraw a pending application.
+// Scheduled interviews are cancelled and the employer is notified.
+func WithdrawApplicationHandler(c *gin.Context) {
+	applicationID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid application ID"})
+		return
+	}
+
+	var requestBody struct {
+		Reason       *string `json:"reason"`
+		AllowReapply bool    `json:"allow_reapply"`
+	}
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+	jobSeekerID, ok := callerID(c)
+	if !ok {
+		return
+	}
+
+	// The employer is notified and pending interviews are cancelled in the
+	// same transaction as the withdrawal
+	var cancelled []schema.Interview
+	withdrawn, err := db.WithdrawApplication(context.Background(), applicationID, jobSeekerID, requestBody.Reason, requestBody.AllowReapply,
+		func(application schema.Application, interviews []schema.Interview) (schema.Outbox, error) {
+			cancelled = interviews
+			outbox, err := interviewCancellations(interviews)
+			if err != nil {
+				return outbox, err
+			}
+			employerID, err := db.GetApplicationEmployerID(context.Background(), application.ID)
+			if err != nil {
+				return outbox, err
+			}
+			message := fmt.Sprintf("Application %d for job %d has been withdrawn by the candidate.", application.ID, application.JobListingID)
+			if requestBody.Reason != nil && *requestBody.Reason != "" {
+				message += " Reason: " + *requestBody.Reason
+			}
+			prepared, err := notify.Prepare(context.Background(), notify.Notification{
+				Event:    notify.EventApplicationWithdrawn,
+				UserType: "employer",
+				UserID:   employerID,
+				Message:  message,
+			})
+			outbox.Add(prepared)
+			return outbox, err
+		})
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrNotApplicationOwner):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, db.ErrCannotWithdraw):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			fmt.Println(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to withdraw application"})
+		}
+		return
+	}
+	revokeInterviewMeetings(cancelled)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Application withdrawn successfully",
+		"application":   withdrawn,
+		"allow_reapply": requestBody.AllowReapply,
+	})
+}
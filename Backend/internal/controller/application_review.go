@@ -0,0 +1,173 @@
+package controller
+
+import (
+	"Backend/internal/db"
+	"Backend/internal/helpers"
+	"Backend/internal/notify"
+	"Backend/internal/schema"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// authorizeApplicationReviewer checks that the employer belongs to the hiring
+// company of the application and writes an error response if not
+func authorizeApplicationReviewer(c *gin.Context, employerID, applicationID int) bool {
+	allowed, err := db.EmployerCanAccessApplication(context.Background(), employerID, applicationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify employer access"})
+		return false
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Employer cannot access this application"})
+		return false
+	}
+	return true
+}
+
+// AddApplicationNoteHandler adds a private note to an application and notifies
+// colleagues @mentioned in it by email address
+func AddApplicationNoteHandler(c *gin.Context) {
+	applicationID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid application ID"})
+		return
+	}
+
+	var requestBody struct {
+		Note string `json:"note" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&requestBody); err != nil || strings.TrimSpace(requestBody.Note) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	employerID, ok := callerID(c)
+	if !ok || !authorizeApplicationReviewer(c, employerID, applicationID) {
+		return
+	}
+
+	mentions, err := db.GetColleagueIDsByEmail(context.Background(), employerID, helpers.ParseMentions(requestBody.Note))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve mentions"})
+		return
+	}
+
+	note, err := db.AddApplicationNote(context.Background(), schema.ApplicationNote{
+		ApplicationID: applicationID,
+		EmployerID:    employerID,
+		Note:          strings.TrimSpace(requestBody.Note),
+		Mentions:      mentions,
+	}, func(note schema.ApplicationNote) (schema.Outbox, error) {
+		// Notify every mentioned colleague except the author
+		var outbox schema.Outbox
+		message := fmt.Sprintf("You were mentioned in a note on application %d.", applicationID)
+		for _, mentionedID := range note.Mentions {
+			if mentionedID == employerID {
+				continue
+			}
+
+			prepared, err := notify.Prepare(context.Background(), notify.Notification{
+				Event:    notify.EventNoteMention,
+				UserType: "employer",
+				UserID:   mentionedID,
+				Message:  message,
+			})
+			if err != nil {
+				return outbox, err
+			}
+			outbox.Add(prepared)
+		}
+		return outbox, nil
+	})
+	if err != nil {
+		fmt.Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add note"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Note added successfully", "note": note})
+}
+
+// GetApplicationNotesHandler returns the private notes of an application
+func GetApplicationNotesHandler(c *gin.Context) {
+	applicationID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid application ID"})
+		return
+	}
+
+	employerID, ok := callerID(c)
+	if !ok || !authorizeApplicationReviewer(c, employerID, applicationID) {
+		return
+	}
+
+	notes, err := db.GetApplicationNotes(context.Background(), applicationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve notes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"notes": notes})
+}
+
+// RateApplicationHandler records a reviewer's 1-5 rating of an application
+func RateApplicationHandler(c *gin.Context) {
+	applicationID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid application ID"})
+		return
+	}
+
+	var requestBody struct {
+		Rating int `json:"rating" binding:"required,min=1,max=5"`
+	}
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data. Rating must be between 1 and 5"})
+		return
+	}
+
+	employerID, ok := callerID(c)
+	if !ok || !authorizeApplicationReviewer(c, employerID, applicationID) {
+		return
+	}
+
+	if err := db.RateApplication(context.Background(), applicationID, employerID, requestBody.Rating); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rate application"})
+		return
+	}
+
+	summary, err := db.GetRatingSummary(context.Background(), applicationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve ratings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Application rated successfully", "rating": summary})
+}
+
+// GetApplicationRatingsHandler returns all ratings of an application and their average
+func GetApplicationRatingsHandler(c *gin.Context) {
+	applicationID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid application ID"})
+		return
+	}
+
+	employerID, ok := callerID(c)
+	if !ok || !authorizeApplicationReviewer(c, employerID, applicationID) {
+		return
+	}
+
+	summary, err := db.GetRatingSummary(context.Background(), applicationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve ratings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rating": summary})
+}
@@ -0,0 +1,104 @@
+package controller
+
+import (
+	"Backend/internal/db"
+	"Backend/internal/schema"
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// authorizeJobReviewer checks that the employer belongs to the company that
+// posted the job and writes an error response if not
+func authorizeJobReviewer(c *gin.Context, employerID, jobID int) bool {
+	allowed, err := db.EmployerCanAccessJob(context.Background(), employerID, jobID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify employer access"})
+		return false
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Employer cannot access this job"})
+		return false
+	}
+	return true
+}
+
+// SetBlindReviewHandler turns blind review on or off for a job and sets the
+// pipeline stage from which candidate identity is revealed
+func SetBlindReviewHandler(c *gin.Context) {
+	jobID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job listing ID"})
+		return
+	}
+
+	var requestBody struct {
+		BlindReview   bool `json:"blind_review"`
+		RevealStageID *int `json:"reveal_stage_id"`
+	}
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	employerID, ok := callerID(c)
+	if !ok || !authorizeJobReviewer(c, employerID, jobID) {
+		return
+	}
+
+	settings := schema.BlindReviewSettings{
+		JobListingID:  jobID,
+		BlindReview:   requestBody.BlindReview,
+		RevealStageID: requestBody.RevealStageID,
+	}
+	actor := schema.Actor{Type: "employer", ID: &employerID}
+	if err := db.SetBlindReviewSettings(context.Background(), settings, actor); err != nil {
+		respondPipelineError(c, err, "Failed to update blind review settings")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Blind review settings updated successfully", "settings": settings})
+}
+
+// GetBlindReviewHandler returns a job's blind review settings
+func GetBlindReviewHandler(c *gin.Context) {
+	jobID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job listing ID"})
+		return
+	}
+	if !authorizeSignedInJobReviewer(c, jobID) {
+		return
+	}
+
+	settings, err := db.GetBlindReviewSettings(context.Background(), jobID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve blind review settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"settings": settings})
+}
+
+// GetIdentityRevealsHandler returns the audit trail of identity reveals for a
+// job to the hiring company
+func GetIdentityRevealsHandler(c *gin.Context) {
+	jobID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job listing ID"})
+		return
+	}
+	if !authorizeSignedInJobReviewer(c, jobID) {
+		return
+	}
+
+	reveals, err := db.GetIdentityReveals(context.Background(), jobID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve identity reveals"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"reveals": reveals})
+}
@@ -0,0 +1,327 @@
+package controller
+
+import (
+	"Backend/internal/db"
+	"Backend/internal/helpers"
+	"Backend/internal/notify"
+	"Backend/internal/schema"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxAvailabilityWindow   = 14 * 24 * time.Hour
+	defaultBookingLinkHours = 72
+	maxBookingLinkHours     = 30 * 24
+)
+
+// respondBookingError maps availability and booking errors to client error responses
+func respondBookingError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, db.ErrAvailabilityNotFound), errors.Is(err, db.ErrBookingLinkNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, db.ErrBookingLinkExpired), errors.Is(err, db.ErrBookingLinkUsed), errors.Is(err, db.ErrApplicationClosed):
+		c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+	case errors.Is(err, db.ErrSlotUnavailable):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		respondInterviewError(c, err, fallback)
+	}
+}
+
+// AddAvailabilityHandler publishes windows in which a team member can interview
+func AddAvailabilityHandler(c *gin.Context) {
+	employerID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid employer ID"})
+		return
+	}
+	if !authorizeSelf(c, employerID) {
+		return
+	}
+
+	var requestBody struct {
+		Windows []schema.AvailabilityWindow `json:"windows" binding:"required,min=1,dive"`
+	}
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+
+	now := time.Now()
+	for _, window := range requestBody.Windows {
+		if !window.EndsAt.After(window.StartsAt) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Each window must end after it starts"})
+			return
+		}
+		if window.EndsAt.Sub(window.StartsAt) > maxAvailabilityWindow {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A window cannot be longer than 14 days"})
+			return
+		}
+		if !window.EndsAt.After(now) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Windows must end in the future"})
+			return
+		}
+	}
+
+	windows, err := db.AddAvailability(context.Background(), employerID, requestBody.Windows)
+	if err != nil {
+		respondBookingError(c, err, "Failed to add availability")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Availability added successfully", "windows": windows})
+}
+
+// GetAvailabilityHandler lists a team member's availability windows between
+// the from and to query parameters, defaulting to the next 14 days. Only
+// colleagues at the same company may see them.
+func GetAvailabilityHandler(c *gin.Context) {
+	employerID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid employer ID"})
+		return
+	}
+	member, err := db.GetEmployer(context.Background(), employerID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Employer not found"})
+		return
+	}
+	if !authorizeCompanyEmployer(c, member.CompanyID) {
+		return
+	}
+
+	from := time.Now()
+	if value := c.Query("from"); value != "" {
+		if from, err = time.Parse(time.RFC3339, value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from. Use RFC 3339."})
+			return
+		}
+	}
+	to := from.Add(maxAvailabilityWindow)
+	if value := c.Query("to"); value != "" {
+		if to, err = time.Parse(time.RFC3339, value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to. Use RFC 3339."})
+			return
+		}
+	}
+
+	windows, err := db.GetAvailability(context.Background(), employerID, from, to)
+	if err != nil {
+		respondBookingError(c, err, "Failed to retrieve availability")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"windows": windows})
+}
+
+// DeleteAvailabilityHandler removes one of the signed-in team member's availability windows
+func DeleteAvailabilityHandler(c *gin.Context) {
+	windowID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid availability ID"})
+		return
+	}
+	employerID, ok := callerID(c)
+	if !ok {
+		return
+	}
+
+	if err := db.DeleteAvailability(context.Background(), windowID, employerID); err != nil {
+		respondBookingError(c, err, "Failed to delete availability")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Availability deleted successfully"})
+}
+
+// CreateBookingLinkHandler creates an expiring link the candidate of an
+// application uses to book a slot in the interviewer's availability
+func CreateBookingLinkHandler(c *gin.Context) {
+	applicationID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid application ID"})
+		return
+	}
+
+	var requestBody struct {
+		InterviewerID   int     `json:"interviewer_id"`
+		RoundID         *int    `json:"round_id"`
+		DurationMinutes int     `json:"duration_minutes" binding:"omitempty,min=15,max=480"`
+		InterviewMode   string  `json:"interview_mode" binding:"required"`
+		InterviewLink   *string `json:"interview_link"`
+		ExpiresInHours  int     `json:"expires_in_hours" binding:"omitempty,min=1"`
+	}
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+	employerID, ok := callerID(c)
+	if !ok || !authorizeApplicationReviewer(c, employerID, applicationID) {
+		return
+	}
+
+	if requestBody.InterviewerID == 0 {
+		requestBody.InterviewerID = employerID
+	}
+	if requestBody.InterviewerID != employerID {
+		allowed, err := db.EmployerCanAccessApplication(context.Background(), requestBody.InterviewerID, applicationID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify interviewer"})
+			return
+		}
+		if !allowed {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Interviewer must be a member of the hiring team"})
+			return
+		}
+	}
+	if requestBody.DurationMinutes == 0 {
+		requestBody.DurationMinutes = 60
+	}
+	if requestBody.ExpiresInHours == 0 {
+		requestBody.ExpiresInHours = defaultBookingLinkHours
+	}
+	if requestBody.ExpiresInHours > maxBookingLinkHours {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A booking link cannot stay open longer than 30 days"})
+		return
+	}
+
+	token, err := helpers.URLToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create booking link"})
+		return
+	}
+
+	link, err := db.CreateBookingLink(context.Background(), schema.BookingLink{
+		Token:           token,
+		ApplicationID:   applicationID,
+		RoundID:         requestBody.RoundID,
+		InterviewerID:   requestBody.InterviewerID,
+		CreatedBy:       &employerID,
+		DurationMinutes: requestBody.DurationMinutes,
+		InterviewMode:   requestBody.InterviewMode,
+		InterviewLink:   requestBody.InterviewLink,
+		ExpiresAt:       time.Now().Add(time.Duration(requestBody.ExpiresInHours) * time.Hour),
+	}, func(link schema.BookingLink) (schema.Outbox, error) {
+		seekerZone, err := db.GetUserTimeZone(context.Background(), "job_seeker", link.JobSeekerID)
+		if err != nil {
+			fmt.Println("Failed to fetch job seeker time zone:", err)
+		}
+		message := fmt.Sprintf("Please pick a time for your interview for %s before %s: %s",
+			link.JobTitle, helpers.FormatInZone(link.ExpiresAt, seekerZone), bookingURL(link.Token))
+		return notify.Prepare(context.Background(), notify.Notification{
+			Event:    notify.EventBookingLinkSent,
+			UserType: "job_seeker",
+			UserID:   link.JobSeekerID,
+			Message:  message,
+		})
+	})
+	if err != nil {
+		respondBookingError(c, err, "Failed to create booking link")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":      "Booking link created successfully",
+		"booking_link": link,
+		"booking_url":  bookingURL(link.Token),
+	})
+}
+
+// GetBookingSlotsHandler shows the candidate the slots still open on a
+// booking link, in the candidate's time zone unless time_zone is given
+func GetBookingSlotsHandler(c *gin.Context) {
+	link, slots, err := db.GetBookingSlots(context.Background(), c.Param("token"), time.Now())
+	if err != nil {
+		respondBookingError(c, err, "Failed to retrieve slots")
+		return
+	}
+
+	timeZone := c.Query("time_zone")
+	if timeZone == "" {
+		if timeZone, err = db.GetUserTimeZone(context.Background(), "job_seeker", link.JobSeekerID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch job seeker time zone"})
+			return
+		}
+	} else if !helpers.ValidTimeZone(timeZone) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid time_zone. Use an IANA name such as Europe/Berlin."})
+		return
+	}
+
+	location := helpers.LoadTimeZone(timeZone)
+	for i := range slots {
+		slots[i].StartsAt = slots[i].StartsAt.In(location)
+		slots[i].EndsAt = slots[i].EndsAt.In(location)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"job_title":        link.JobTitle,
+		"round_name":       link.RoundName,
+		"duration_minutes": link.DurationMinutes,
+		"interview_mode":   link.InterviewMode,
+		"expires_at":       link.ExpiresAt.In(location),
+		"time_zone":        location.String(),
+		"slots":            slots,
+	})
+}
+
+// bookingURL is the page where the candidate picks a slot of a booking link
+func bookingURL(token string) string {
+	return fmt.Sprintf("%s/booking/%s", os.Getenv("WEB_URL"), token)
+}
+
+// bookingNotifications builds the notification of a booked interview to the
+// interviewer and the calendar invites to everyone attending. The
+// interviewer is emailed with the invite, so bookings are not routed to email.
+func bookingNotifications(interview schema.Interview) (schema.Outbox, error) {
+	var outbox schema.Outbox
+	if interview.InterviewerID != nil {
+		interviewerZone, err := db.GetUserTimeZone(context.Background(), "employer", *interview.InterviewerID)
+		if err != nil {
+			fmt.Println("Failed to fetch interviewer time zone:", err)
+		}
+		outbox, err = notify.Prepare(context.Background(), notify.Notification{
+			Event:    notify.EventInterviewBooked,
+			UserType: "employer",
+			UserID:   *interview.InterviewerID,
+			Message: fmt.Sprintf("An interview for application %d was booked for %s.",
+				interview.ApplicationID, helpers.FormatInZone(interview.ScheduledDate, interviewerZone)),
+		})
+		if err != nil {
+			return outbox, err
+		}
+	}
+
+	invites, err := interviewCalendar(interview, helpers.ICSMethodRequest, func(when string) string {
+		return fmt.Sprintf("The interview for application <strong>#%d</strong> is booked for %s.", interview.ApplicationID, when)
+	})
+	outbox.Add(invites)
+	return outbox, err
+}
+
+// BookSlotHandler books one of a booking link's slots and notifies the interviewer
+func BookSlotHandler(c *gin.Context) {
+	var requestBody struct {
+		StartsAt time.Time `json:"starts_at" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+
+	interview, err := db.BookSlot(context.Background(), c.Param("token"), requestBody.StartsAt, time.Now(), bookingNotifications)
+	if err != nil {
+		respondBookingError(c, err, "Failed to book slot")
+		return
+	}
+	interview = syncInterviewMeeting(schema.Interview{}, interview, false)
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Interview booked successfully", "interview": interview})
+}
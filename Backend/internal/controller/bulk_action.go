@@ -0,0 +1,172 @@
+package controller
+
+import (
+	"Backend/internal/db"
+	"Backend/internal/helpers"
+	"Backend/internal/notify"
+	"Backend/internal/schema"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxBulkApplications caps how many applications one bulk action may touch
+const maxBulkApplications = 200
+
+// BulkApplicationActionHandler moves, rejects or tags many applications of a
+// job in one transaction. The notifications of each affected job seeker are
+// stored with the change of their application.
+func BulkApplicationActionHandler(c *gin.Context) {
+	var request schema.BulkActionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+	employerID, ok := callerID(c)
+	if !ok {
+		return
+	}
+	request.EmployerID = employerID
+
+	// Drop duplicate IDs while keeping the requested order
+	seen := make(map[int]bool)
+	ids := make([]int, 0, len(request.ApplicationIDs))
+	for _, id := range request.ApplicationIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	request.ApplicationIDs = ids
+
+	if len(request.ApplicationIDs) == 0 || len(request.ApplicationIDs) > maxBulkApplications {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Between 1 and %d application IDs are required", maxBulkApplications)})
+		return
+	}
+
+	switch request.Action {
+	case "move":
+		if request.StageID <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "stage_id is required to move applications"})
+			return
+		}
+	case "reject":
+		if !validRejectionOptions(c, request.ReasonCode, request.DelayMinutes) {
+			return
+		}
+	case "tag":
+		var tags []string
+		for _, tag := range request.Tags {
+			if tag = strings.TrimSpace(tag); tag != "" && len(tag) <= 50 {
+				tags = append(tags, tag)
+			}
+		}
+		if len(tags) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "At least one tag of up to 50 characters is required"})
+			return
+		}
+		request.Tags = tags
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid action. Allowed values: 'move', 'reject' or 'tag'"})
+		return
+	}
+
+	allowed, err := db.EmployerCanAccessJob(context.Background(), request.EmployerID, request.JobListingID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify employer access"})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Employer cannot manage applications of this job"})
+		return
+	}
+
+	// A stored template replaces any inline one
+	if request.Action == "reject" && request.TemplateID != nil {
+		template, err := db.GetMessageTemplate(context.Background(), *request.TemplateID, request.EmployerID)
+		if errors.Is(err, db.ErrTemplateNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve message template"})
+			return
+		}
+		request.Template = template.Body
+	}
+
+	contacts := map[int]schema.ApplicationContact{}
+	if request.Action == "reject" && request.Template != "" {
+		contacts, err = db.GetApplicationContacts(context.Background(), request.ApplicationIDs)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render message template"})
+			return
+		}
+	}
+
+	cancelled := map[int][]schema.Interview{}
+	sendAt := time.Now().Add(time.Duration(request.DelayMinutes) * time.Minute)
+	result, err := db.BulkApplicationAction(context.Background(), request, func(application schema.Application, interviews []schema.Interview) (schema.Outbox, error) {
+		cancelled[application.ID] = interviews
+		return bulkChangeNotification(request, contacts, sendAt, application, interviews)
+	})
+	if err != nil {
+		fmt.Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to apply bulk action"})
+		return
+	}
+	// Applications that failed were rolled back with their interviews
+	for _, applicationID := range result.Succeeded {
+		revokeInterviewMeetings(cancelled[applicationID])
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Bulk action completed",
+		"result":  result,
+	})
+}
+
+// bulkChangeNotification builds the notification of one application whose
+// status a bulk action changed, with the cancellations of the interviews it
+// cancelled. Delayed rejections are held for the scheduled notification
+// worker until sendAt.
+func bulkChangeNotification(request schema.BulkActionRequest, contacts map[int]schema.ApplicationContact, sendAt time.Time,
+	application schema.Application, cancelled []schema.Interview) (schema.Outbox, error) {
+	outbox, err := interviewCancellations(cancelled)
+	if err != nil {
+		return outbox, err
+	}
+
+	message := fmt.Sprintf("The status of your application %d is now %s.", application.ID, application.ApplicationStatus)
+	if request.Action == "reject" {
+		message = fmt.Sprintf("Your application %d has been Rejected.", application.ID)
+		if request.Template != "" {
+			message = helpers.RenderTemplate(request.Template, helpers.ContactTemplateValues(contacts[application.ID]))
+		}
+
+		if request.DelayMinutes > 0 {
+			outbox.Scheduled = append(outbox.Scheduled, schema.ScheduledNotification{
+				ApplicationID: &application.ID,
+				UserID:        application.JobSeekerID,
+				UserType:      "job_seeker",
+				Message:       message,
+				SendAt:        sendAt,
+			})
+			return outbox, nil
+		}
+	}
+
+	prepared, err := notify.Prepare(context.Background(), notify.Notification{
+		Event:    notify.EventApplicationStatusChanged,
+		UserType: "job_seeker",
+		UserID:   application.JobSeekerID,
+		Message:  message,
+	})
+	outbox.Add(prepared)
+	return outbox, err
+}
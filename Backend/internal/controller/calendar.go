@@ -0,0 +1,187 @@
+package controller
+
+import (
+	"Backend/internal/db"
+	"Backend/internal/helpers"
+	"Backend/internal/notify"
+	"Backend/internal/schema"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// interviewInvite renders an interview as an .ics attachment for one attendee
+func interviewInvite(interview schema.Interview, method, attendee string) helpers.MailAttachment {
+	return helpers.MailAttachment{
+		FileName:    "invite.ics",
+		ContentType: "text/calendar; charset=UTF-8; method=" + method,
+		Data:        helpers.BuildInterviewICS(method, "", attendee, []schema.Interview{interview}, time.Now()),
+	}
+}
+
+// interviewCalendar builds the emails to the candidate and the interview
+// panel with the interview attached as a calendar invite or cancellation. The
+// message is built per recipient with the time shown in their time zone.
+func interviewCalendar(interview schema.Interview, method string, message func(when string) string) (schema.Outbox, error) {
+	var outbox schema.Outbox
+	application, err := db.GetApplication(context.Background(), interview.ApplicationID)
+	if err != nil {
+		return outbox, err
+	}
+
+	type recipient struct {
+		userType string
+		userID   int
+	}
+	recipients := []recipient{{"job_seeker", application.JobSeekerID}}
+	for _, interviewerID := range interview.InterviewerIDs {
+		recipients = append(recipients, recipient{"employer", interviewerID})
+	}
+
+	for _, to := range recipients {
+		timeZone, err := db.GetUserTimeZone(context.Background(), to.userType, to.userID)
+		if err != nil {
+			fmt.Println("Failed to fetch time zone:", err)
+		}
+		prepared, err := notify.Prepare(context.Background(), notify.Notification{
+			Event:    notify.EventInterviewCalendar,
+			UserType: to.userType,
+			UserID:   to.userID,
+			Message:  message(helpers.FormatInZone(interview.ScheduledDate, timeZone)),
+			Attachments: func(email string) []helpers.MailAttachment {
+				return []helpers.MailAttachment{interviewInvite(interview, method, email)}
+			},
+		})
+		if err != nil {
+			return outbox, err
+		}
+		outbox.Add(prepared)
+	}
+	return outbox, nil
+}
+
+// interviewCancellations builds the calendar cancellations of interviews a
+// decision or withdrawal cancelled
+func interviewCancellations(interviews []schema.Interview) (schema.Outbox, error) {
+	var outbox schema.Outbox
+	for _, interview := range interviews {
+		applicationID := interview.ApplicationID
+		cancellation, err := interviewCalendar(interview, helpers.ICSMethodCancel, func(when string) string {
+			return fmt.Sprintf("The interview for application <strong>#%d</strong> on %s has been cancelled.", applicationID, when)
+		})
+		if err != nil {
+			return outbox, err
+		}
+		outbox.Add(cancellation)
+	}
+	return outbox, nil
+}
+
+// revokeInterviewMeetings revokes the generated meeting links of cancelled
+// interviews once the cancellation is committed
+func revokeInterviewMeetings(interviews []schema.Interview) {
+	for _, interview := range interviews {
+		syncInterviewMeeting(interview, interview, false)
+	}
+}
+
+// calendarFeedURL builds the subscription URL of a calendar feed token on
+// the host the request was made to
+func calendarFeedURL(c *gin.Context, token string) string {
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s/calendar/feed/%s.ics", scheme, c.Request.Host, token)
+}
+
+// GetCalendarFeedHandler returns the caller's private iCal subscription URL,
+// or a null feed_url if they have not created one yet
+func GetCalendarFeedHandler(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	if !authorizeSelf(c, userID) {
+		return
+	}
+
+	token, err := db.GetCalendarFeedToken(context.Background(), c.GetString("user_type"), userID)
+	if errors.Is(err, db.ErrCalendarFeedNotFound) {
+		c.JSON(http.StatusOK, gin.H{"feed_url": nil})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve calendar feed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"feed_url": calendarFeedURL(c, token)})
+}
+
+// RotateCalendarFeedHandler creates the caller's iCal subscription URL, or
+// replaces it, for example after it was shared by mistake
+func RotateCalendarFeedHandler(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	if !authorizeSelf(c, userID) {
+		return
+	}
+
+	newToken, err := helpers.URLToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create calendar feed"})
+		return
+	}
+
+	token, err := db.RotateCalendarFeedToken(context.Background(), c.GetString("user_type"), userID, newToken)
+	if err != nil {
+		fmt.Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create calendar feed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"feed_url": calendarFeedURL(c, token)})
+}
+
+// CalendarFeedHandler serves all interviews of a feed token's owner as an
+// iCalendar subscription. The token in the URL is the only credential, as
+// calendar clients cannot send an Authorization header.
+func CalendarFeedHandler(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+	userType, userID, err := db.GetCalendarFeedOwner(context.Background(), token)
+	if errors.Is(err, db.ErrCalendarFeedNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve calendar feed"})
+		return
+	}
+
+	var interviews []schema.Interview
+	if userType == "employer" {
+		interviews, err = db.GetEmployerInterviews(context.Background(), userID)
+	} else {
+		interviews, err = db.GetSeekerInterviews(context.Background(), userID)
+	}
+	if err != nil {
+		fmt.Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve interviews"})
+		return
+	}
+
+	c.Header("Cache-Control", "private, max-age=300")
+	c.Data(http.StatusOK, "text/calendar; charset=UTF-8",
+		helpers.BuildInterviewICS(helpers.ICSMethodPublish, "DazzleDate interviews", "", interviews, time.Now()))
+}
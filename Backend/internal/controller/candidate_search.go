@@ -0,0 +1,273 @@
+package controller
+
+import (
+	"Backend/internal/db"
+	"Backend/internal/notify"
+	"Backend/internal/schema"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultCandidatePageSize = 20
+	maxCandidatePageSize     = 100
+)
+
+// candidatePage reads ?page= and ?page_size= and writes an error response if
+// they are invalid
+func candidatePage(c *gin.Context) (int, int, bool) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page"})
+		return 0, 0, false
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", strconv.Itoa(defaultCandidatePageSize)))
+	if err != nil || pageSize < 1 || pageSize > maxCandidatePageSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("page_size must be between 1 and %d", maxCandidatePageSize)})
+		return 0, 0, false
+	}
+	return page, pageSize, true
+}
+
+// validCandidateCriteria writes an error response if the criteria are invalid
+func validCandidateCriteria(c *gin.Context, criteria schema.CandidateSearchCriteria) bool {
+	switch criteria.MinSkillLevel {
+	case "", "Beginner", "Intermediate", "Advanced", "Expert":
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid skill level: " + criteria.MinSkillLevel})
+		return false
+	}
+	if criteria.MinExperienceYears < 0 || (criteria.MaxExperienceYears != nil && *criteria.MaxExperienceYears < criteria.MinExperienceYears) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid experience range"})
+		return false
+	}
+	return true
+}
+
+// SearchCandidatesHandler searches discoverable job seekers. Supports
+// ?page= and ?page_size= for pagination.
+func SearchCandidatesHandler(c *gin.Context) {
+	var criteria schema.CandidateSearchCriteria
+	if err := c.ShouldBindJSON(&criteria); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+	if !validCandidateCriteria(c, criteria) {
+		return
+	}
+	page, pageSize, ok := candidatePage(c)
+	if !ok {
+		return
+	}
+
+	result, err := db.SearchCandidates(context.Background(), criteria, page, pageSize)
+	if err != nil {
+		fmt.Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search candidates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// SaveCandidateSearchHandler stores a named candidate search for an employer
+func SaveCandidateSearchHandler(c *gin.Context) {
+	var search schema.SavedCandidateSearch
+	if err := c.ShouldBindJSON(&search); err != nil || strings.TrimSpace(search.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+	if !validCandidateCriteria(c, search.Criteria) {
+		return
+	}
+	employerID, ok := callerID(c)
+	if !ok {
+		return
+	}
+	search.EmployerID = employerID
+
+	search.Name = strings.TrimSpace(search.Name)
+	result, err := db.SaveCandidateSearch(context.Background(), search)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save search"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Search saved successfully", "search": result})
+}
+
+// GetSavedCandidateSearchesHandler lists the signed-in employer's saved searches
+func GetSavedCandidateSearchesHandler(c *gin.Context) {
+	employerID, ok := callerID(c)
+	if !ok {
+		return
+	}
+
+	searches, err := db.GetSavedCandidateSearches(context.Background(), employerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve saved searches"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"searches": searches})
+}
+
+// RunSavedCandidateSearchHandler runs one of the signed-in employer's saved
+// searches with the same pagination as a regular search
+func RunSavedCandidateSearchHandler(c *gin.Context) {
+	searchID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid search ID"})
+		return
+	}
+	employerID, ok := callerID(c)
+	if !ok {
+		return
+	}
+	page, pageSize, ok := candidatePage(c)
+	if !ok {
+		return
+	}
+
+	search, err := db.GetSavedCandidateSearch(context.Background(), searchID, employerID)
+	if errors.Is(err, db.ErrSavedSearchNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve saved search"})
+		return
+	}
+
+	result, err := db.SearchCandidates(context.Background(), search.Criteria, page, pageSize)
+	if err != nil {
+		fmt.Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search candidates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// DeleteSavedCandidateSearchHandler removes one of the signed-in employer's saved searches
+func DeleteSavedCandidateSearchHandler(c *gin.Context) {
+	searchID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid search ID"})
+		return
+	}
+	employerID, ok := callerID(c)
+	if !ok {
+		return
+	}
+
+	err = db.DeleteSavedCandidateSearch(context.Background(), searchID, employerID)
+	if errors.Is(err, db.ErrSavedSearchNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete saved search"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Saved search deleted successfully"})
+}
+
+// InviteCandidateHandler invites a discoverable job seeker to apply to one of
+// the employer's open jobs and notifies them
+func InviteCandidateHandler(c *gin.Context) {
+	jobSeekerID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job seeker ID"})
+		return
+	}
+
+	var requestBody struct {
+		JobListingID int     `json:"job_listing_id" binding:"required"`
+		Message      *string `json:"message"`
+	}
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	employerID, ok := callerID(c)
+	if !ok || !authorizeJobReviewer(c, employerID, requestBody.JobListingID) {
+		return
+	}
+
+	invitation, err := db.InviteCandidate(context.Background(), schema.CandidateInvitation{
+		EmployerID:   employerID,
+		JobSeekerID:  jobSeekerID,
+		JobListingID: requestBody.JobListingID,
+		Message:      requestBody.Message,
+	}, func(invitation schema.CandidateInvitation) (schema.Outbox, error) {
+		// Create a notification for the job seeker
+		message := fmt.Sprintf("You have been invited to apply for %s (job %d).", invitation.JobTitle, invitation.JobListingID)
+		if invitation.Message != nil && strings.TrimSpace(*invitation.Message) != "" {
+			message += " Message from the employer: " + strings.TrimSpace(*invitation.Message)
+		}
+		return notify.Prepare(context.Background(), notify.Notification{
+			Event:    notify.EventCandidateInvited,
+			UserType: "job_seeker",
+			UserID:   invitation.JobSeekerID,
+			Message:  message,
+		})
+	})
+	switch {
+	case errors.Is(err, db.ErrCandidateNotDiscoverable):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, db.ErrJobNotOpen):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, db.ErrAlreadyInvited):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		fmt.Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to invite candidate"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Candidate invited successfully", "invitation": invitation})
+}
+
+// SetDiscoverableHandler lets a job seeker opt in to or out of candidate search
+func SetDiscoverableHandler(c *gin.Context) {
+	jobSeekerID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job seeker ID"})
+		return
+	}
+	if !authorizeSelf(c, jobSeekerID) {
+		return
+	}
+
+	var requestBody struct {
+		Discoverable *bool `json:"discoverable" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	err = db.SetDiscoverable(context.Background(), jobSeekerID, *requestBody.Discoverable)
+	if errors.Is(err, db.ErrJobSeekerNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update search visibility"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Search visibility updated successfully", "discoverable": *requestBody.Discoverable})
+}
@@ -4,21 +4,36 @@ import (
 	"Backend/internal/db"
 	"Backend/internal/schema"
 	"Backend/internal/helpers"
+	"Backend/internal/notify"
 	"context"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 	"fmt"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 // ScheduleInterviewHandler schedules an interview and sends an email notification
 func ScheduleInterviewHandler(c *gin.Context) {
-	var interview schema.Interview
-	if err := c.ShouldBindJSON(&interview); err != nil {
+	var request struct {
+		schema.Interview
+		EmployerID        *int `json:"employer_id"`
+		OverrideConflicts bool `json:"override_conflicts"` // Schedule even if the candidate or an interviewer is busy
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
 		return
 	}
+	interview := request.Interview
+	if !validInterviewTime(c, &interview, request.EmployerID) {
+		return
+	}
+	if !validInterviewPanel(c, interview.ApplicationID, interview) {
+		return
+	}
 	// Step 1: Generate a new application ID
 	// newID, err := db.GenerateInterviewID(context.Background())
 	// if err != nil {
@@ -30,73 +45,54 @@ func ScheduleInterviewHandler(c *gin.Context) {
 	// Step 2: Assign the generated ID to the application struct
 	// interview.ID = newID
 
-	// Step 2: Schedule the interview
-	result, err := db.ScheduleInterview(context.Background(), interview)
+	// Step 3: Schedule the interview; the job seeker is notified in the same transaction
+	result, err := db.ScheduleInterview(context.Background(), interview, schema.Actor{Type: "employer", ID: request.EmployerID}, request.OverrideConflicts,
+		scheduledInterviewNotification)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to schedule interview"})
+		respondInterviewError(c, err, "Failed to schedule interview")
 		return
 	}
+	result = syncInterviewMeeting(schema.Interview{}, result, false)
+
+	// Step 4: Return success response
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Interview scheduled successfully",
+		"interview": result,
+	})
+}
 
-	// Step 3: Fetch the application details
+// scheduledInterviewNotification builds the notification of a new interview
+// to the job seeker, in their time zone. The email carries a calendar invite.
+func scheduledInterviewNotification(interview schema.Interview) (schema.Outbox, error) {
 	application, err := db.GetApplication(context.Background(), interview.ApplicationID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch application details"})
-		return
+		return schema.Outbox{}, err
 	}
 
-	// Step 4: Create a notification for the job seeker
-	message := fmt.Sprintf("Your interview for application %d has been scheduled.", application.ID)
-
-	notificationID, err := db.GenerateNotificationID(context.Background())
+	seekerZone, err := db.GetUserTimeZone(context.Background(), "job_seeker", application.JobSeekerID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate notification ID"})
-		return
+		fmt.Println("Failed to fetch job seeker time zone:", err)
+	}
+	when := helpers.FormatInZone(interview.ScheduledDate, seekerZone)
+	message := fmt.Sprintf("Your interview for application %d has been scheduled for %s.", application.ID, when)
+	if interview.RoundName != nil {
+		message = fmt.Sprintf("Your %s interview for application %d has been scheduled for %s.", *interview.RoundName, application.ID, when)
 	}
 
-	notification := schema.Notification{
-		ID:       notificationID,
-		UserID:   application.JobSeekerID,
+	return notify.Prepare(context.Background(), notify.Notification{
+		Event:    notify.EventInterviewScheduled,
 		UserType: "job_seeker",
+		UserID:   application.JobSeekerID,
 		Message:  message,
-		IsRead:   false,
-	}
-
-	err = db.StoreNotification(context.Background(), notification)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store notification"})
-		return
-	}
-
-	// Step 5: Send email notification in a goroutine
-	go func() {
-		// Fetch job seeker details
-		jobseeker, err := db.GetJobSeeker(context.Background(), application.JobSeekerID)
-		if err != nil {
-			fmt.Println("Failed to fetch job seeker details:", err)
-			return
-		}
-
-		// Prepare email content
-		emailMessage := fmt.Sprintf(`
+		EmailBody: fmt.Sprintf(`
 Your interview for application <strong>#%d</strong> has been scheduled.<br>
 📅 <strong>Date:</strong> %s<br>
 ⏰ <strong>Mode:</strong> %s<br>
 We look forward to meeting you!
-`, application.ID, interview.ScheduledDate,interview.InterviewMode)
-
-		// Send the email
-		err = helpers.SendMail(jobseeker.Email, emailMessage)
-		if err != nil {
-			fmt.Println("Failed to send email:", err)
-		} else {
-			fmt.Println("Email sent successfully to:", jobseeker.Email)
-		}
-	}()
-
-	// Step 6: Return success response
-	c.JSON(http.StatusOK, gin.H{
-		"message":   "Interview scheduled successfully",
-		"interview": result,
+`, application.ID, when, interview.InterviewMode),
+		Attachments: func(email string) []helpers.MailAttachment {
+			return []helpers.MailAttachment{interviewInvite(interview, helpers.ICSMethodRequest, email)}
+		},
 	})
 }
 
@@ -135,18 +131,41 @@ func GetInterviewHandler(c *gin.Context) {
 
 
 func UpdateInterviewHandler(c *gin.Context) {
-	var interview schema.Interview
-	if err := c.ShouldBindJSON(&interview); err != nil {
+	var request struct {
+		schema.Interview
+		OverrideConflicts bool `json:"override_conflicts"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
 		return
 	}
+	interview := request.Interview
+	if !validInterviewTime(c, &interview, nil) {
+		return
+	}
 
-	result, err := db.UpdateInterview(context.Background(), interview)
+	current, err := db.GetInterview(context.Background(), interview.ID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update interview"})
+		respondInterviewError(c, err, "Failed to update interview")
+		return
+	}
+	if !validInterviewPanel(c, current.ApplicationID, interview) {
 		return
 	}
 
+	// The raised sequence makes calendar clients replace the earlier invite
+	result, err := db.UpdateInterview(context.Background(), interview, request.OverrideConflicts, func(updated schema.Interview) (schema.Outbox, error) {
+		return interviewCalendar(updated, helpers.ICSMethodRequest, func(when string) string {
+			return fmt.Sprintf("Your interview for application <strong>#%d</strong> has been rescheduled to %s (%s).",
+				updated.ApplicationID, when, updated.InterviewMode)
+		})
+	})
+	if err != nil {
+		respondInterviewError(c, err, "Failed to update interview")
+		return
+	}
+	result = syncInterviewMeeting(current, result, !result.ScheduledDate.Equal(current.ScheduledDate))
+
 	c.JSON(http.StatusOK, gin.H{"message": "Interview updated successfully", "interview": result})
 }
 
@@ -165,4 +184,179 @@ func GetSeekerInterviewCountHandler(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{"job_seeker_id": seekerID, "interview_count": count})
-}
\ No newline at end of file
+}
+
+// validInterviewTime rejects interviews without a future time or with an
+// unknown time zone. Without a zone, new interviews take the scheduling
+// employer's zone; updates keep the zone they had.
+func validInterviewTime(c *gin.Context, interview *schema.Interview, employerID *int) bool {
+	if interview.ScheduledDate.IsZero() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "scheduled_date is required"})
+		return false
+	}
+	if !interview.ScheduledDate.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Interviews cannot be scheduled in the past"})
+		return false
+	}
+
+	if interview.TimeZone == "" && employerID != nil {
+		zone, err := db.GetUserTimeZone(context.Background(), "employer", *employerID)
+		if err == nil {
+			interview.TimeZone = zone
+		}
+	}
+	if interview.TimeZone != "" && !helpers.ValidTimeZone(interview.TimeZone) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid time_zone. Use an IANA name such as Europe/Berlin."})
+		return false
+	}
+	return true
+}
+
+// validInterviewPanel checks that every interviewer is on the hiring team of the application
+func validInterviewPanel(c *gin.Context, applicationID int, interview schema.Interview) bool {
+	members := interview.InterviewerIDs
+	if interview.InterviewerID != nil {
+		members = append([]int{*interview.InterviewerID}, members...)
+	}
+	for _, employerID := range members {
+		allowed, err := db.EmployerCanAccessApplication(context.Background(), employerID, applicationID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify interviewer"})
+			return false
+		}
+		if !allowed {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Interviewer %d is not on the hiring team", employerID)})
+			return false
+		}
+	}
+	return true
+}
+
+// respondInterviewError maps interview errors to client error responses
+func respondInterviewError(c *gin.Context, err error, fallback string) {
+	var conflict *db.InterviewConflictError
+	switch {
+	case errors.As(err, &conflict):
+		c.JSON(http.StatusConflict, gin.H{
+			"error":     err.Error(),
+			"conflicts": conflict.Conflicts,
+		})
+	case errors.Is(err, db.ErrInterviewNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, db.ErrRoundNotInJob):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, db.ErrInterviewNotScheduled),
+		errors.Is(err, db.ErrRoundAlreadyScheduled),
+		errors.Is(err, db.ErrRoundInUse):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		fmt.Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// CompleteInterviewHandler records that a scheduled interview took place and
+// its outcome: Passed, Failed or Undecided
+func CompleteInterviewHandler(c *gin.Context) {
+	interviewID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid interview ID"})
+		return
+	}
+
+	var requestBody struct {
+		Outcome  string  `json:"outcome" binding:"required"`
+		Feedback *string `json:"feedback"`
+	}
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+	switch requestBody.Outcome {
+	case schema.OutcomePassed, schema.OutcomeFailed, schema.OutcomeUndecided:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid outcome. Allowed values: 'Passed', 'Failed' or 'Undecided'"})
+		return
+	}
+
+	interview, err := db.GetInterview(context.Background(), interviewID)
+	if err != nil {
+		respondInterviewError(c, err, "Failed to retrieve interview")
+		return
+	}
+	employerID, ok := callerID(c)
+	if !ok || !authorizeApplicationReviewer(c, employerID, interview.ApplicationID) {
+		return
+	}
+
+	actor := schema.Actor{Type: "employer", ID: &employerID}
+	result, err := db.CompleteInterview(context.Background(), interviewID, requestBody.Outcome, requestBody.Feedback, actor, func(completed schema.Interview) (schema.Outbox, error) {
+		return counterpartNotifications(completed, "employer", notify.EventInterviewCompleted, func(when string) string {
+			return fmt.Sprintf("Thank you for attending the %s on %s. The hiring team will be in touch about next steps.", interviewLabel(completed), when)
+		})
+	})
+	if err != nil {
+		respondInterviewError(c, err, "Failed to complete interview")
+		return
+	}
+	result = syncInterviewMeeting(result, result, false)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Interview completed successfully", "interview": result})
+}
+
+// SetInterviewPlanHandler replaces the ordered interview rounds of a job
+func SetInterviewPlanHandler(c *gin.Context) {
+	jobID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job listing ID"})
+		return
+	}
+
+	var requestBody struct {
+		Rounds []schema.InterviewRound `json:"rounds" binding:"required,dive"`
+	}
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	seen := make(map[string]bool)
+	for i := range requestBody.Rounds {
+		requestBody.Rounds[i].Name = strings.TrimSpace(requestBody.Rounds[i].Name)
+		name := strings.ToLower(requestBody.Rounds[i].Name)
+		if name == "" || seen[name] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Round names must be non-empty and unique"})
+			return
+		}
+		seen[name] = true
+	}
+
+	if !authorizeSignedInJobReviewer(c, jobID) {
+		return
+	}
+
+	rounds, err := db.SetInterviewPlan(context.Background(), jobID, requestBody.Rounds)
+	if err != nil {
+		respondInterviewError(c, err, "Failed to update interview plan")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Interview plan updated successfully", "rounds": rounds})
+}
+
+// GetInterviewPlanHandler returns the interview rounds of a job
+func GetInterviewPlanHandler(c *gin.Context) {
+	jobID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job listing ID"})
+		return
+	}
+
+	rounds, err := db.GetInterviewPlan(context.Background(), jobID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve interview plan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rounds": rounds})
+}
@@ -0,0 +1,412 @@
+package controller
+
+import (
+	"Backend/internal/db"
+	"Backend/internal/helpers"
+	"Backend/internal/notify"
+	"Backend/internal/schema"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxProposedTimes = 5
+
+// respondInterviewWorkflowError maps cancellation, reschedule and no-show errors to client error responses
+func respondInterviewWorkflowError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, db.ErrNotApplicationOwner):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, db.ErrRescheduleRequestNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, db.ErrInterviewNotStarted), errors.Is(err, db.ErrReschedulePending):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		respondInterviewError(c, err, fallback)
+	}
+}
+
+// authorizeInterviewParty loads an interview and checks the signed-in caller
+// is its candidate or on the hiring team of its application. It also returns
+// the caller's ID.
+func authorizeInterviewParty(c *gin.Context, interviewID int) (schema.Interview, int, bool) {
+	userID, ok := callerID(c)
+	if !ok {
+		return schema.Interview{}, 0, false
+	}
+	interview, err := db.GetInterview(context.Background(), interviewID)
+	if err != nil {
+		respondInterviewError(c, err, "Failed to retrieve interview")
+		return interview, 0, false
+	}
+
+	if c.GetString("user_type") == "employer" {
+		return interview, userID, authorizeApplicationReviewer(c, userID, interview.ApplicationID)
+	}
+
+	participants, err := db.GetApplicationParticipants(context.Background(), interview.ApplicationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify participants"})
+		return interview, 0, false
+	}
+	if participants.JobSeekerID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the candidate and the hiring team can access this interview"})
+		return interview, 0, false
+	}
+	return interview, userID, true
+}
+
+// counterpartNotifications builds the notifications telling the other side
+// of an interview about a change made by actorType: the candidate when the
+// hiring team acted, and the panel (or the job's employer if there is no
+// panel) when the candidate did. Times are shown in each recipient's zone.
+func counterpartNotifications(interview schema.Interview, actorType string, event notify.Event, message func(when string) string) (schema.Outbox, error) {
+	var outbox schema.Outbox
+	participants, err := db.GetApplicationParticipants(context.Background(), interview.ApplicationID)
+	if err != nil {
+		return outbox, err
+	}
+
+	userType, recipients := "job_seeker", []int{participants.JobSeekerID}
+	if actorType == "job_seeker" {
+		userType, recipients = "employer", interview.InterviewerIDs
+		if len(recipients) == 0 {
+			recipients = []int{participants.EmployerID}
+		}
+	}
+
+	for _, userID := range recipients {
+		timeZone, err := db.GetUserTimeZone(context.Background(), userType, userID)
+		if err != nil {
+			fmt.Println("Failed to fetch time zone:", err)
+		}
+		prepared, err := notify.Prepare(context.Background(), notify.Notification{
+			Event:    event,
+			UserType: userType,
+			UserID:   userID,
+			Message:  message(helpers.FormatInZone(interview.ScheduledDate, timeZone)),
+		})
+		if err != nil {
+			return outbox, err
+		}
+		outbox.Add(prepared)
+	}
+	return outbox, nil
+}
+
+// interviewLabel names an interview in notifications, e.g. "Technical interview for Backend Engineer"
+func interviewLabel(interview schema.Interview) string {
+	label := "interview"
+	if interview.RoundName != nil {
+		label = *interview.RoundName + " interview"
+	}
+	if interview.JobTitle != "" {
+		label += " for " + interview.JobTitle
+	}
+	return label
+}
+
+// CancelInterviewHandler cancels a scheduled interview on behalf of the
+// candidate or the hiring team, with a reason for the other side
+func CancelInterviewHandler(c *gin.Context) {
+	interviewID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid interview ID"})
+		return
+	}
+
+	var requestBody struct {
+		Reason string `json:"reason" binding:"required,max=1000"`
+	}
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+	requestBody.Reason = strings.TrimSpace(requestBody.Reason)
+	if requestBody.Reason == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A reason is required"})
+		return
+	}
+	_, userID, ok := authorizeInterviewParty(c, interviewID)
+	if !ok {
+		return
+	}
+
+	actor := schema.Actor{Type: c.GetString("user_type"), ID: &userID}
+	interview, err := db.CancelInterview(context.Background(), interviewID, actor, requestBody.Reason, func(cancelled schema.Interview) (schema.Outbox, error) {
+		outbox, err := counterpartNotifications(cancelled, actor.Type, notify.EventInterviewCancelled, func(when string) string {
+			return fmt.Sprintf("The %s on %s has been cancelled: %s", interviewLabel(cancelled), when, requestBody.Reason)
+		})
+		if err != nil {
+			return outbox, err
+		}
+		cancellations, err := interviewCancellations([]schema.Interview{cancelled})
+		outbox.Add(cancellations)
+		return outbox, err
+	})
+	if err != nil {
+		respondInterviewWorkflowError(c, err, "Failed to cancel interview")
+		return
+	}
+	interview = syncInterviewMeeting(interview, interview, false)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Interview cancelled successfully", "interview": interview})
+}
+
+// RescheduleInterviewHandler moves a scheduled interview to a new time. A
+// pending reschedule request from the candidate is accepted with it.
+func RescheduleInterviewHandler(c *gin.Context) {
+	interviewID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid interview ID"})
+		return
+	}
+
+	var requestBody struct {
+		ScheduledDate     time.Time `json:"scheduled_date"`
+		TimeZone          string    `json:"time_zone"`
+		DurationMinutes   int       `json:"duration_minutes" binding:"omitempty,min=5,max=480"`
+		Reason            *string   `json:"reason"`
+		OverrideConflicts bool      `json:"override_conflicts"`
+	}
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+	moved := schema.Interview{ScheduledDate: requestBody.ScheduledDate, TimeZone: requestBody.TimeZone}
+	if !validInterviewTime(c, &moved, nil) {
+		return
+	}
+
+	current, err := db.GetInterview(context.Background(), interviewID)
+	if err != nil {
+		respondInterviewError(c, err, "Failed to retrieve interview")
+		return
+	}
+	employerID, ok := callerID(c)
+	if !ok || !authorizeApplicationReviewer(c, employerID, current.ApplicationID) {
+		return
+	}
+
+	actor := schema.Actor{Type: "employer", ID: &employerID}
+	interview, err := db.RescheduleInterview(context.Background(), interviewID, moved.ScheduledDate, moved.TimeZone,
+		requestBody.DurationMinutes, actor, requestBody.Reason, requestBody.OverrideConflicts, func(rescheduled schema.Interview) (schema.Outbox, error) {
+			outbox, err := counterpartNotifications(rescheduled, actor.Type, notify.EventInterviewRescheduled, func(when string) string {
+				return fmt.Sprintf("Your %s has been moved to %s.", interviewLabel(rescheduled), when)
+			})
+			if err != nil {
+				return outbox, err
+			}
+			// The raised sequence makes calendar clients replace the earlier invite
+			invites, err := interviewCalendar(rescheduled, helpers.ICSMethodRequest, func(when string) string {
+				return fmt.Sprintf("The interview for application <strong>#%d</strong> has been rescheduled to %s (%s).",
+					rescheduled.ApplicationID, when, rescheduled.InterviewMode)
+			})
+			outbox.Add(invites)
+			return outbox, err
+		})
+	if err != nil {
+		respondInterviewWorkflowError(c, err, "Failed to reschedule interview")
+		return
+	}
+	interview = syncInterviewMeeting(current, interview, true)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Interview rescheduled successfully", "interview": interview})
+}
+
+// RequestRescheduleHandler lets the candidate ask the hiring team to move an
+// interview, optionally proposing up to five times that would suit them
+func RequestRescheduleHandler(c *gin.Context) {
+	interviewID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid interview ID"})
+		return
+	}
+
+	var requestBody struct {
+		Reason        string      `json:"reason" binding:"required,max=1000"`
+		ProposedTimes []time.Time `json:"proposed_times"`
+	}
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+	requestBody.Reason = strings.TrimSpace(requestBody.Reason)
+	if requestBody.Reason == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A reason is required"})
+		return
+	}
+	if len(requestBody.ProposedTimes) > maxProposedTimes {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "At most 5 times can be proposed"})
+		return
+	}
+	for _, proposed := range requestBody.ProposedTimes {
+		if !proposed.After(time.Now()) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Proposed times must be in the future"})
+			return
+		}
+	}
+
+	interview, jobSeekerID, ok := authorizeInterviewParty(c, interviewID)
+	if !ok {
+		return
+	}
+
+	request, err := db.RequestReschedule(context.Background(), interviewID, jobSeekerID, requestBody.Reason, requestBody.ProposedTimes,
+		func(request schema.RescheduleRequest) (schema.Outbox, error) {
+			return counterpartNotifications(interview, "job_seeker", notify.EventRescheduleRequested, func(when string) string {
+				return fmt.Sprintf("The candidate of application %d asked to reschedule the %s on %s: %s",
+					interview.ApplicationID, interviewLabel(interview), when, request.Reason)
+			})
+		})
+	if err != nil {
+		respondInterviewWorkflowError(c, err, "Failed to request reschedule")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Reschedule requested successfully", "request": request})
+}
+
+// DeclineRescheduleHandler turns down the candidate's pending reschedule
+// request; the interview keeps its time
+func DeclineRescheduleHandler(c *gin.Context) {
+	interviewID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid interview ID"})
+		return
+	}
+
+	var requestBody struct {
+		Response *string `json:"response" binding:"omitempty,max=1000"`
+	}
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+
+	interview, err := db.GetInterview(context.Background(), interviewID)
+	if err != nil {
+		respondInterviewError(c, err, "Failed to retrieve interview")
+		return
+	}
+	employerID, ok := callerID(c)
+	if !ok || !authorizeApplicationReviewer(c, employerID, interview.ApplicationID) {
+		return
+	}
+
+	actor := schema.Actor{Type: "employer", ID: &employerID}
+	request, err := db.DeclineReschedule(context.Background(), interviewID, actor, requestBody.Response, func(request schema.RescheduleRequest) (schema.Outbox, error) {
+		return counterpartNotifications(interview, actor.Type, notify.EventRescheduleDeclined, func(when string) string {
+			message := fmt.Sprintf("Your request to reschedule the %s was declined; it stays on %s.", interviewLabel(interview), when)
+			if request.Response != nil && *request.Response != "" {
+				message += " " + *request.Response
+			}
+			return message
+		})
+	})
+	if err != nil {
+		respondInterviewWorkflowError(c, err, "Failed to decline reschedule request")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Reschedule request declined", "request": request})
+}
+
+// GetRescheduleRequestsHandler lists the reschedule requests of an interview
+func GetRescheduleRequestsHandler(c *gin.Context) {
+	interviewID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid interview ID"})
+		return
+	}
+	if _, _, ok := authorizeInterviewParty(c, interviewID); !ok {
+		return
+	}
+
+	requests, err := db.GetRescheduleRequests(context.Background(), interviewID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve reschedule requests"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"requests": requests})
+}
+
+// MarkNoShowHandler records that the candidate or the interviewer did not
+// attend an interview. The candidate can only report the interviewer; their
+// report is passed on to the hiring team and the interview stays scheduled
+// until an employer records the no-show.
+func MarkNoShowHandler(c *gin.Context) {
+	interviewID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid interview ID"})
+		return
+	}
+
+	var requestBody struct {
+		Party  string  `json:"party" binding:"required"` // Who did not attend: job_seeker or employer
+		Reason *string `json:"reason" binding:"omitempty,max=1000"`
+	}
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+	if requestBody.Party != "job_seeker" && requestBody.Party != "employer" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid party. Allowed values: 'job_seeker' or 'employer'"})
+		return
+	}
+
+	userType := c.GetString("user_type")
+	if userType == "job_seeker" && requestBody.Party != "employer" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Candidates can only report that the interviewer did not attend"})
+		return
+	}
+	_, userID, ok := authorizeInterviewParty(c, interviewID)
+	if !ok {
+		return
+	}
+
+	if userType == "job_seeker" {
+		interview, err := db.ReportNoShow(context.Background(), interviewID, userID, requestBody.Reason, func(interview schema.Interview) (schema.Outbox, error) {
+			return counterpartNotifications(interview, userType, notify.EventNoShowReported, func(when string) string {
+				message := fmt.Sprintf("The candidate of application %d reported that nobody attended the %s on %s. Please record a no-show or follow up with them.",
+					interview.ApplicationID, interviewLabel(interview), when)
+				if requestBody.Reason != nil && *requestBody.Reason != "" {
+					message += " " + *requestBody.Reason
+				}
+				return message
+			})
+		})
+		if err != nil {
+			respondInterviewWorkflowError(c, err, "Failed to report no-show")
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "No-show reported to the hiring team", "interview": interview})
+		return
+	}
+
+	actor := schema.Actor{Type: userType, ID: &userID}
+	interview, err := db.MarkNoShow(context.Background(), interviewID, requestBody.Party, actor, requestBody.Reason, func(interview schema.Interview) (schema.Outbox, error) {
+		return counterpartNotifications(interview, actor.Type, notify.EventInterviewNoShow, func(when string) string {
+			if requestBody.Party == "job_seeker" {
+				return fmt.Sprintf("You were recorded as not attending the %s on %s.", interviewLabel(interview), when)
+			}
+			return fmt.Sprintf("We are sorry the interviewer could not attend the %s on %s. The hiring team will be in touch to reschedule.",
+				interviewLabel(interview), when)
+		})
+	})
+	if err != nil {
+		respondInterviewWorkflowError(c, err, "Failed to record no-show")
+		return
+	}
+	interview = syncInterviewMeeting(interview, interview, false)
+
+	c.JSON(http.StatusOK, gin.H{"message": "No-show recorded successfully", "interview": interview})
+}
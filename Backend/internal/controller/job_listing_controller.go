@@ -3,10 +3,11 @@ package controller
 import (
 	"Backend/internal/db"
 	"Backend/internal/schema"
-	"Backend/internal/helpers"
+	"Backend/internal/notify"
 
 	// "fmt"
 	"context"
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
@@ -264,9 +265,25 @@ func ApplyJob(c *gin.Context) {
 	}
 
 	//  Call DB function to apply for job
-	applicationID, err := db.ApplyJob(application.JobSeekerID, application.JobListingID, application.CoverLetter)
+	applicationID, err := db.ApplyJob(application.JobSeekerID, application.JobListingID, application.CoverLetter, application.ResumeID,
+		func(applicationID int) (schema.Outbox, error) {
+			return notify.Prepare(context.Background(), notify.Notification{
+				Event:    notify.EventApplicationSubmitted,
+				UserType: "job_seeker",
+				UserID:   application.JobSeekerID,
+				Message:  fmt.Sprintf("Your application %d has been created successfully.", applicationID),
+			})
+		})
 	if err != nil {
-		if err.Error() == "you have already applied for this job" {
+		if errors.Is(err, db.ErrResumeNotFound) || errors.Is(err, db.ErrResumeNotOwned) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"message": "Invalid resume",
+				"error":   err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, db.ErrAlreadyApplied) {
 			log.Printf("[WARN] JobSeekerID=%d has already applied for JobListingID=%d\n", application.JobSeekerID, application.JobListingID)
 			c.JSON(http.StatusConflict, gin.H{
 				"success": false,
@@ -284,38 +301,6 @@ func ApplyJob(c *gin.Context) {
 		return
 	}
 
-	// Create a notification for the job seeker
-	message := fmt.Sprintf("Your application %d has been created successfully.", applicationID)
-	notificationID, err := db.GenerateNotificationID(context.Background())
-	notification := schema.Notification{
-		ID:       notificationID,
-		UserID:   application.JobSeekerID,
-		UserType: "job_seeker",
-		Message:  message,
-		IsRead:   false,
-	}
-
-	err = db.StoreNotification(context.Background(), notification)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store notification"})
-		return
-	}
-
-	// Send mail notification in a goroutine
-	go func() {
-		jobseeker, err := db.GetJobSeeker(c, application.JobSeekerID)
-		if err != nil {
-			fmt.Println("Failed to fetch job seeker:", err)
-			return
-		}
-
-		err = helpers.SendMail(jobseeker.Email, message)
-		if err != nil {
-			fmt.Println("Failed to send email:", err)
-		}
-	}()
-
-
 	c.JSON(http.StatusCreated, gin.H{
 		"success": true,
 		"message": "Application submitted successfully",
@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"Backend/internal/db"
+	"Backend/internal/helpers"
+	"Backend/internal/meeting"
+	"Backend/internal/schema"
+	"context"
+	"fmt"
+)
+
+// storedMeeting returns the generated meeting of an interview, if it has one
+func storedMeeting(interview schema.Interview) (meeting.Meeting, bool) {
+	if interview.MeetingProvider == nil || interview.MeetingID == nil {
+		return meeting.Meeting{}, false
+	}
+	stored := meeting.Meeting{Provider: *interview.MeetingProvider, ID: *interview.MeetingID}
+	if interview.InterviewLink != nil {
+		stored.JoinURL = *interview.InterviewLink
+	}
+	return stored, true
+}
+
+func revokeMeeting(stored meeting.Meeting) {
+	if err := meeting.Revoke(context.Background(), stored); err != nil {
+		fmt.Println("Failed to revoke meeting:", err)
+	}
+}
+
+// syncInterviewMeeting brings the generated meeting link of an interview in
+// line with its state after a change; previous is the interview before it.
+// Scheduled Online interviews without a link get one from the default
+// provider, and with regenerate (after a reschedule) a generated link is
+// replaced by a fresh one; a new link is sent to the candidate and the panel
+// as an updated calendar invite. Generated links are revoked once the
+// interview is no longer a scheduled Online interview or the employer typed
+// in their own.
+// Provider failures are logged and leave the interview as it is, since it
+// can still go ahead with a link added by hand.
+func syncInterviewMeeting(previous, interview schema.Interview, regenerate bool) schema.Interview {
+	if old, ok := storedMeeting(previous); ok {
+		if current, ok := storedMeeting(interview); !ok || current.ID != old.ID {
+			revokeMeeting(old)
+		}
+	}
+
+	current, generated := storedMeeting(interview)
+	if interview.Status != "Scheduled" || !meeting.IsOnline(interview.InterviewMode) {
+		if !generated {
+			return interview
+		}
+		cleared, err := db.SetInterviewMeeting(context.Background(), interview.ID, interview.MeetingID, nil, nil, nil, nil)
+		if err != nil {
+			fmt.Println("Failed to clear meeting link:", err)
+			return interview
+		}
+		revokeMeeting(current)
+		return cleared
+	}
+
+	hasLink := interview.InterviewLink != nil && *interview.InterviewLink != ""
+	if hasLink && !(generated && regenerate) {
+		return interview
+	}
+
+	provider, err := meeting.Default()
+	if err != nil {
+		fmt.Println("Failed to load meeting provider:", err)
+		return interview
+	}
+	if provider == nil {
+		return interview
+	}
+
+	created, err := provider.CreateMeeting(context.Background(), meeting.Request{
+		InterviewID:     interview.ID,
+		Title:           interviewLabel(interview),
+		StartsAt:        interview.ScheduledDate,
+		DurationMinutes: interview.DurationMinutes,
+	})
+	if err != nil {
+		fmt.Println("Failed to create meeting:", err)
+		return interview
+	}
+
+	updated, err := db.SetInterviewMeeting(context.Background(), interview.ID, interview.MeetingID,
+		&created.JoinURL, &created.Provider, &created.ID, func(updated schema.Interview) (schema.Outbox, error) {
+			return interviewCalendar(updated, helpers.ICSMethodRequest, func(when string) string {
+				return fmt.Sprintf("The interview for application <strong>#%d</strong> on %s will take place at %s.",
+					updated.ApplicationID, when, created.JoinURL)
+			})
+		})
+	if err != nil {
+		fmt.Println("Failed to store meeting link:", err)
+		revokeMeeting(created)
+		return interview
+	}
+	if generated {
+		revokeMeeting(current)
+	}
+	return updated
+}
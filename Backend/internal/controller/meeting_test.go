@@ -0,0 +1,208 @@
+package controller
+
+import (
+	"Backend/config"
+	"Backend/internal/db"
+	"Backend/internal/dbtest"
+	"Backend/internal/meeting"
+	"Backend/internal/notify"
+	"Backend/internal/schema"
+	"context"
+	"testing"
+	"time"
+)
+
+// useStubMeetings makes a fresh stub provider the default for the test
+func useStubMeetings(t *testing.T) *meeting.StubProvider {
+	t.Helper()
+	stub := meeting.NewStubProvider()
+	meeting.Register(stub)
+	t.Setenv("MEETING_PROVIDER", "stub")
+	t.Cleanup(func() { meeting.Register(meeting.NewStubProvider()) })
+	return stub
+}
+
+// scheduleOnline schedules an Online interview tomorrow without a link and
+// syncs its meeting as ScheduleInterviewHandler does
+func scheduleOnline(t *testing.T) (schema.Interview, schema.Actor) {
+	t.Helper()
+	interviewerID := dbtest.Employer(t, dbtest.Company(t))
+	applicationID := dbtest.Application(t, dbtest.JobSeeker(t), dbtest.Job(t, interviewerID), "Applied")
+	actor := schema.Actor{Type: "employer", ID: &interviewerID}
+
+	interview, err := db.ScheduleInterview(context.Background(), schema.Interview{
+		ApplicationID: applicationID,
+		ScheduledDate: time.Now().Add(24 * time.Hour).Truncate(time.Minute),
+		InterviewMode: meeting.ModeOnline,
+		InterviewerID: &interviewerID,
+	}, actor, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return syncInterviewMeeting(schema.Interview{}, interview, false), actor
+}
+
+// generatedMeeting checks an interview carries a live stub meeting, also as
+// stored, and returns its ID
+func generatedMeeting(t *testing.T, stub *meeting.StubProvider, interview schema.Interview) string {
+	t.Helper()
+	stored, err := db.GetInterview(context.Background(), interview.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, got := range []schema.Interview{interview, stored} {
+		if got.MeetingProvider == nil || *got.MeetingProvider != "stub" || got.MeetingID == nil {
+			t.Fatalf("interview has no stub meeting: provider %v, meeting %v", got.MeetingProvider, got.MeetingID)
+		}
+		if got.InterviewLink == nil || *got.InterviewLink != "https://meet.example.invalid/"+*got.MeetingID {
+			t.Fatalf("link = %v, want the link of meeting %s", got.InterviewLink, *got.MeetingID)
+		}
+	}
+	if !stub.Active(*interview.MeetingID) {
+		t.Fatalf("meeting %s is not active", *interview.MeetingID)
+	}
+	return *interview.MeetingID
+}
+
+// noMeeting checks an interview, also as stored, has no generated meeting
+func noMeeting(t *testing.T, interview schema.Interview) schema.Interview {
+	t.Helper()
+	stored, err := db.GetInterview(context.Background(), interview.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, got := range []schema.Interview{interview, stored} {
+		if got.MeetingProvider != nil || got.MeetingID != nil {
+			t.Errorf("interview still has meeting %v of %v", got.MeetingID, got.MeetingProvider)
+		}
+	}
+	return stored
+}
+
+func TestSyncInterviewMeetingSchedule(t *testing.T) {
+	dbtest.Require(t)
+	stub := useStubMeetings(t)
+
+	interview, _ := scheduleOnline(t)
+	generatedMeeting(t, stub, interview)
+
+	// The link is sent in a calendar update with a raised sequence
+	if interview.Sequence == 0 {
+		t.Errorf("sequence = 0, want it raised for the new link")
+	}
+	application, err := db.GetApplication(context.Background(), interview.ApplicationID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var queued int
+	err = config.DB.QueryRow(context.Background(), `
+		SELECT COUNT(*) FROM outbox_messages
+		WHERE event = $1 AND user_type = 'job_seeker' AND user_id = $2 AND payload->>'message' LIKE '%' || $3 || '%'`,
+		string(notify.EventInterviewCalendar), application.JobSeekerID, *interview.InterviewLink).Scan(&queued)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if queued != 1 {
+		t.Errorf("got %d calendar emails with the link, want 1", queued)
+	}
+}
+
+func TestSyncInterviewMeetingReschedule(t *testing.T) {
+	dbtest.Require(t)
+	stub := useStubMeetings(t)
+	current, actor := scheduleOnline(t)
+	oldID := generatedMeeting(t, stub, current)
+
+	moved, err := db.RescheduleInterview(context.Background(), current.ID, current.ScheduledDate.Add(2*time.Hour), "", 0, actor, nil, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	moved = syncInterviewMeeting(current, moved, true)
+
+	if newID := generatedMeeting(t, stub, moved); newID == oldID {
+		t.Errorf("meeting %s was kept, want a new one", oldID)
+	}
+	if stub.Active(oldID) {
+		t.Errorf("old meeting %s is still active", oldID)
+	}
+}
+
+func TestSyncInterviewMeetingCancel(t *testing.T) {
+	dbtest.Require(t)
+	stub := useStubMeetings(t)
+	interview, actor := scheduleOnline(t)
+	meetingID := generatedMeeting(t, stub, interview)
+
+	cancelled, err := db.CancelInterview(context.Background(), interview.ID, actor, "Position filled", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	syncInterviewMeeting(cancelled, cancelled, false)
+
+	if stored := noMeeting(t, interview); stored.InterviewLink != nil {
+		t.Errorf("link = %q, want none", *stored.InterviewLink)
+	}
+	if stub.Active(meetingID) {
+		t.Errorf("meeting %s is still active", meetingID)
+	}
+}
+
+func TestSyncInterviewMeetingEnded(t *testing.T) {
+	dbtest.Require(t)
+	stub := useStubMeetings(t)
+
+	tests := []struct {
+		name string
+		end  func(interview schema.Interview, actor schema.Actor) (schema.Interview, error)
+	}{
+		{"completed", func(interview schema.Interview, actor schema.Actor) (schema.Interview, error) {
+			return db.CompleteInterview(context.Background(), interview.ID, schema.OutcomePassed, nil, actor, nil)
+		}},
+		{"no show", func(interview schema.Interview, actor schema.Actor) (schema.Interview, error) {
+			if _, err := db.RescheduleInterview(context.Background(), interview.ID, time.Now().Add(-time.Hour), "", 0, actor, nil, true, nil); err != nil {
+				return schema.Interview{}, err
+			}
+			return db.MarkNoShow(context.Background(), interview.ID, "job_seeker", actor, nil, nil)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interview, actor := scheduleOnline(t)
+			meetingID := generatedMeeting(t, stub, interview)
+
+			ended, err := tt.end(interview, actor)
+			if err != nil {
+				t.Fatal(err)
+			}
+			noMeeting(t, syncInterviewMeeting(ended, ended, false))
+			if stub.Active(meetingID) {
+				t.Errorf("meeting %s is still active", meetingID)
+			}
+		})
+	}
+}
+
+func TestSyncInterviewMeetingManualLink(t *testing.T) {
+	dbtest.Require(t)
+	stub := useStubMeetings(t)
+	current, _ := scheduleOnline(t)
+	meetingID := generatedMeeting(t, stub, current)
+
+	manual := "https://video.example.com/our-room"
+	update := current
+	update.InterviewLink = &manual
+	updated, err := db.UpdateInterview(context.Background(), update, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	updated = syncInterviewMeeting(current, updated, false)
+
+	stored := noMeeting(t, updated)
+	if stored.InterviewLink == nil || *stored.InterviewLink != manual {
+		t.Errorf("link = %v, want %s", stored.InterviewLink, manual)
+	}
+	if stub.Active(meetingID) {
+		t.Errorf("replaced meeting %s is still active", meetingID)
+	}
+}
@@ -0,0 +1,346 @@
+package controller
+
+import (
+	"Backend/internal/db"
+	"Backend/internal/helpers"
+	"Backend/internal/notify"
+	"Backend/internal/schema"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const attachmentDirectory = "uploads/Attachments"
+
+// authorizeMessageParticipant checks that the signed-in caller is the
+// applicant or the employer who posted the job, and writes an error response
+// if not
+func authorizeMessageParticipant(c *gin.Context, applicationID int) (schema.ApplicationParticipants, bool) {
+	userID, ok := callerID(c)
+	if !ok {
+		return schema.ApplicationParticipants{}, false
+	}
+	participants, err := db.GetApplicationParticipants(context.Background(), applicationID)
+	if errors.Is(err, db.ErrApplicationNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return participants, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify participants"})
+		return participants, false
+	}
+
+	userType := c.GetString("user_type")
+	if (userType == "job_seeker" && userID == participants.JobSeekerID) ||
+		(userType == "employer" && userID == participants.EmployerID) {
+		return participants, true
+	}
+
+	c.JSON(http.StatusForbidden, gin.H{"error": "Only the applicant and the job's employer can access these messages"})
+	return participants, false
+}
+
+// authorizeThreadParticipant loads a thread and checks the caller takes part in it
+func authorizeThreadParticipant(c *gin.Context, threadID int) (schema.MessageThread, schema.ApplicationParticipants, bool) {
+	thread, err := db.GetThread(context.Background(), threadID)
+	if errors.Is(err, db.ErrThreadNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return thread, schema.ApplicationParticipants{}, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve thread"})
+		return thread, schema.ApplicationParticipants{}, false
+	}
+
+	participants, ok := authorizeMessageParticipant(c, thread.ApplicationID)
+	return thread, participants, ok
+}
+
+// SendMessageHandler posts a message about an application. Without a
+// thread_id a new thread is started with the given subject. Files may be
+// attached in the multipart field "attachments".
+func SendMessageHandler(c *gin.Context) {
+	applicationID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid application ID"})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, helpers.MaxAttachmentCount*helpers.MaxAttachmentSize+1<<20)
+	var requestBody struct {
+		ThreadID *int   `json:"thread_id" form:"thread_id"`
+		Subject  string `json:"subject" form:"subject"`
+		Body     string `json:"body" form:"body" binding:"required"`
+	}
+	if err := c.ShouldBind(&requestBody); err != nil || strings.TrimSpace(requestBody.Body) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+	if requestBody.ThreadID == nil && strings.TrimSpace(requestBody.Subject) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A subject is required to start a thread"})
+		return
+	}
+
+	participants, ok := authorizeMessageParticipant(c, applicationID)
+	if !ok {
+		return
+	}
+	senderType, senderID := c.GetString("user_type"), c.GetInt("user_id")
+
+	if requestBody.ThreadID != nil {
+		thread, err := db.GetThread(context.Background(), *requestBody.ThreadID)
+		if errors.Is(err, db.ErrThreadNotFound) || (err == nil && thread.ApplicationID != applicationID) {
+			c.JSON(http.StatusNotFound, gin.H{"error": db.ErrThreadNotFound.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve thread"})
+			return
+		}
+		requestBody.Subject = thread.Subject
+	}
+
+	attachments, ok := saveMessageAttachments(c)
+	if !ok {
+		return
+	}
+
+	message := schema.Message{
+		SenderType:  senderType,
+		SenderID:    senderID,
+		Body:        strings.TrimSpace(requestBody.Body),
+		Attachments: attachments,
+	}
+
+	// Notify the other participant in-app and by email, with the message
+	recipientType, recipientID := "employer", participants.EmployerID
+	if senderType == "employer" {
+		recipientType, recipientID = "job_seeker", participants.JobSeekerID
+	}
+	notice := fmt.Sprintf("New message about application %d: %s", applicationID, requestBody.Subject)
+	announce := func(message schema.Message) (schema.Outbox, error) {
+		return notify.Prepare(context.Background(), notify.Notification{
+			Event:     notify.EventMessageReceived,
+			UserType:  recipientType,
+			UserID:    recipientID,
+			Message:   notice,
+			EmailBody: notice + "\n\n" + message.Body,
+		})
+	}
+
+	var thread *schema.MessageThread
+	if requestBody.ThreadID != nil {
+		message.ThreadID = *requestBody.ThreadID
+		message, err = db.AddMessage(context.Background(), message, announce)
+	} else {
+		var created schema.MessageThread
+		created, message, err = db.CreateThread(context.Background(), schema.MessageThread{
+			ApplicationID: applicationID,
+			Subject:       strings.TrimSpace(requestBody.Subject),
+			CreatedByType: senderType,
+			CreatedByID:   senderID,
+		}, message, announce)
+		thread = &created
+	}
+	if err != nil {
+		for _, attachment := range attachments {
+			os.Remove(attachment.FilePath)
+		}
+		fmt.Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send message"})
+		return
+	}
+
+	response := gin.H{"message": "Message sent successfully", "sent": message}
+	if thread != nil {
+		response["thread"] = thread
+	}
+	c.JSON(http.StatusCreated, response)
+}
+
+// saveMessageAttachments validates and stores the files of a multipart
+// request and writes an error response if any is rejected
+func saveMessageAttachments(c *gin.Context) ([]schema.MessageAttachment, bool) {
+	attachments := []schema.MessageAttachment{}
+	if !strings.HasPrefix(c.ContentType(), "multipart/") {
+		return attachments, true
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid form data"})
+		return nil, false
+	}
+	files := form.File["attachments"]
+	if len(files) > helpers.MaxAttachmentCount {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("At most %d attachments are allowed", helpers.MaxAttachmentCount)})
+		return nil, false
+	}
+
+	removeSaved := func() {
+		for _, attachment := range attachments {
+			os.Remove(attachment.FilePath)
+		}
+	}
+	for _, fileHeader := range files {
+		if fileHeader.Size > helpers.MaxAttachmentSize {
+			removeSaved()
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Attachments must not exceed 10 MB"})
+			return nil, false
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			removeSaved()
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read attachment"})
+			return nil, false
+		}
+		mimeType, err := helpers.DetectAttachmentType(file, fileHeader.Size, fileHeader.Filename)
+		file.Close()
+		if err != nil {
+			removeSaved()
+			c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": helpers.ErrUnsupportedAttachment.Error()})
+			return nil, false
+		}
+
+		fileName, err := helpers.AttachmentFileName(mimeType)
+		if err != nil {
+			removeSaved()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store attachment"})
+			return nil, false
+		}
+		path := filepath.Join(attachmentDirectory, fileName)
+		if err := c.SaveUploadedFile(fileHeader, path); err != nil {
+			removeSaved()
+			fmt.Printf("Error saving file: %v\n", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store attachment"})
+			return nil, false
+		}
+
+		attachments = append(attachments, schema.MessageAttachment{
+			FileName:  helpers.CleanFileName(fileHeader.Filename),
+			FilePath:  path,
+			MimeType:  mimeType,
+			SizeBytes: fileHeader.Size,
+		})
+	}
+	return attachments, true
+}
+
+// GetThreadsHandler lists the message threads of an application with the
+// caller's unread count per thread
+func GetThreadsHandler(c *gin.Context) {
+	applicationID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid application ID"})
+		return
+	}
+
+	if _, ok := authorizeMessageParticipant(c, applicationID); !ok {
+		return
+	}
+
+	threads, err := db.GetThreads(context.Background(), applicationID, c.GetString("user_type"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve threads"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"threads": threads})
+}
+
+// GetThreadMessagesHandler returns the messages of a thread
+func GetThreadMessagesHandler(c *gin.Context) {
+	threadID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid thread ID"})
+		return
+	}
+
+	thread, _, ok := authorizeThreadParticipant(c, threadID)
+	if !ok {
+		return
+	}
+
+	messages, err := db.GetMessages(context.Background(), threadID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve messages"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"thread": thread, "messages": messages})
+}
+
+// MarkThreadReadHandler records read receipts for the messages the caller
+// received in a thread
+func MarkThreadReadHandler(c *gin.Context) {
+	threadID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid thread ID"})
+		return
+	}
+
+	if _, _, ok := authorizeThreadParticipant(c, threadID); !ok {
+		return
+	}
+
+	marked, err := db.MarkThreadRead(context.Background(), threadID, c.GetString("user_type"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark messages as read"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Messages marked as read", "marked": marked})
+}
+
+// GetUnreadMessageCountHandler returns the caller's unread messages across all threads
+func GetUnreadMessageCountHandler(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	if !authorizeSelf(c, userID) {
+		return
+	}
+
+	count, err := db.GetUnreadMessageCount(context.Background(), c.GetString("user_type"), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve unread count"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"unread_count": count})
+}
+
+// DownloadAttachmentHandler serves a message attachment to a participant
+func DownloadAttachmentHandler(c *gin.Context) {
+	attachmentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attachment ID"})
+		return
+	}
+
+	attachment, applicationID, err := db.GetAttachment(context.Background(), attachmentID)
+	if errors.Is(err, db.ErrAttachmentNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve attachment"})
+		return
+	}
+
+	if _, ok := authorizeMessageParticipant(c, applicationID); !ok {
+		return
+	}
+
+	c.FileAttachment(attachment.FilePath, attachment.FileName)
+}
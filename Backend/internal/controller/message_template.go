@@ -0,0 +1,142 @@
+package controller
+
+import (
+	"Backend/internal/db"
+	"Backend/internal/schema"
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// templateRequest is the body used to create or change a message template
+type templateRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+	Body string `json:"body" binding:"required"`
+}
+
+// bindTemplateRequest binds and trims a template request, writing an error
+// response if it is invalid
+func bindTemplateRequest(c *gin.Context) (templateRequest, bool) {
+	var request templateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return request, false
+	}
+	request.Name = strings.TrimSpace(request.Name)
+	request.Body = strings.TrimSpace(request.Body)
+	if request.Name == "" || request.Body == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Template name and body are required"})
+		return request, false
+	}
+	return request, true
+}
+
+// respondTemplateError maps template errors to client error responses
+func respondTemplateError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, db.ErrTemplateNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, db.ErrTemplateNameTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// CreateMessageTemplateHandler adds a message template for the employer's company
+func CreateMessageTemplateHandler(c *gin.Context) {
+	request, ok := bindTemplateRequest(c)
+	if !ok {
+		return
+	}
+	employerID, ok := callerID(c)
+	if !ok {
+		return
+	}
+
+	template, err := db.CreateMessageTemplate(context.Background(), employerID, request.Name, request.Body)
+	if err != nil {
+		respondTemplateError(c, err, "Failed to create message template")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Message template created successfully", "template": template})
+}
+
+// GetMessageTemplatesHandler lists the templates of an employer's company
+func GetMessageTemplatesHandler(c *gin.Context) {
+	employerID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid employer ID"})
+		return
+	}
+	if !authorizeSelf(c, employerID) {
+		return
+	}
+
+	templates, err := db.GetMessageTemplates(context.Background(), employerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve message templates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"templates": templates})
+}
+
+// UpdateMessageTemplateHandler changes the name and body of a template
+func UpdateMessageTemplateHandler(c *gin.Context) {
+	templateID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID"})
+		return
+	}
+
+	request, ok := bindTemplateRequest(c)
+	if !ok {
+		return
+	}
+	employerID, ok := callerID(c)
+	if !ok {
+		return
+	}
+
+	template, err := db.UpdateMessageTemplate(context.Background(), templateID, employerID, request.Name, request.Body)
+	if err != nil {
+		respondTemplateError(c, err, "Failed to update message template")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Message template updated successfully", "template": template})
+}
+
+// DeleteMessageTemplateHandler removes a template of the employer's company
+func DeleteMessageTemplateHandler(c *gin.Context) {
+	templateID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID"})
+		return
+	}
+	employerID, ok := callerID(c)
+	if !ok {
+		return
+	}
+
+	if err := db.DeleteMessageTemplate(context.Background(), templateID, employerID); err != nil {
+		respondTemplateError(c, err, "Failed to delete message template")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Message template deleted successfully"})
+}
+
+// GetRejectionReasonCodesHandler lists the standard rejection reason codes
+func GetRejectionReasonCodesHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"reason_codes": schema.RejectionReasonCodes,
+		"placeholders": []string{"candidate_name", "first_name", "job_title", "company"},
+	})
+}
@@ -16,15 +16,36 @@ func GetNotificationsHandler(c *gin.Context) {
 		return
 	}
 
-	notifications, err := db.GetNotifications(context.Background(), seekerID)
+	notifications, err := db.GetNotifications(context.Background(), "job_seeker", seekerID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notifications"})
 		return
 	}
-	err = db.UpdateNotificationStatus(context.Background(), seekerID)
+	err = db.UpdateNotificationStatus(context.Background(), "job_seeker", seekerID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification status"})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"notifications": notifications})
-}
\ No newline at end of file
+}
+
+// GetEmployerNotificationsHandler returns an employer's notifications and marks them as read
+func GetEmployerNotificationsHandler(c *gin.Context) {
+	employerID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	notifications, err := db.GetNotifications(context.Background(), "employer", employerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notifications"})
+		return
+	}
+	err = db.UpdateNotificationStatus(context.Background(), "employer", employerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification status"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"notifications": notifications})
+}
@@ -0,0 +1,437 @@
+package controller
+
+import (
+	"Backend/internal/db"
+	"Backend/internal/helpers"
+	"Backend/internal/notify"
+	"Backend/internal/schema"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const offerLetterDirectory = "uploads/Offers"
+
+// respondOfferError maps offer errors to client error responses
+func respondOfferError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, db.ErrOfferNotFound), errors.Is(err, db.ErrApplicationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, db.ErrNotApplicationOwner):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, db.ErrOfferExists), errors.Is(err, db.ErrOfferStatus):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, db.ErrOfferNotAllowed), errors.Is(err, db.ErrOfferExpiryPassed):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, db.ErrOfferExpired):
+		c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+	default:
+		fmt.Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// authorizeOfferEmployer loads an offer and checks the signed-in employer can
+// manage its job, writing an error response if not. It also returns the
+// employer's ID.
+func authorizeOfferEmployer(c *gin.Context, offerID int) (schema.Offer, int, bool) {
+	employerID, ok := callerID(c)
+	if !ok {
+		return schema.Offer{}, 0, false
+	}
+	offer, err := db.GetOffer(context.Background(), offerID)
+	if err != nil {
+		respondOfferError(c, err, "Failed to retrieve offer")
+		return offer, 0, false
+	}
+	return offer, employerID, authorizeJobReviewer(c, employerID, offer.JobListingID)
+}
+
+// offerSentMessage describes a sent offer to the candidate
+func offerSentMessage(offer schema.Offer) string {
+	return fmt.Sprintf("You have received an offer for %s: %.2f %s, starting %s. Please respond before %s.",
+		offer.JobTitle, offer.Salary, offer.Currency, offer.StartDate.Format("January 2, 2006"),
+		offer.ExpiresAt.Format("January 2, 2006 15:04"))
+}
+
+// offerSentNotification builds the notification of a sent offer to the candidate
+func offerSentNotification(offer schema.Offer) (schema.Outbox, error) {
+	return notify.Prepare(context.Background(), notify.Notification{
+		Event:    notify.EventOfferSent,
+		UserType: "job_seeker",
+		UserID:   offer.JobSeekerID,
+		Message:  offerSentMessage(offer),
+	})
+}
+
+// CreateOfferHandler creates an offer on an accepted application. Fields may
+// be sent as JSON or as a multipart form with the offer letter in the field
+// "offer_letter". With "send" set the offer goes to the candidate at once,
+// otherwise it is kept as a draft.
+func CreateOfferHandler(c *gin.Context) {
+	applicationID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid application ID"})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, helpers.MaxAttachmentSize+1<<20)
+	var requestBody struct {
+		Salary    float64 `json:"salary" form:"salary" binding:"required,gt=0"`
+		Currency  string  `json:"currency" form:"currency" binding:"required,len=3,alpha"`
+		StartDate string  `json:"start_date" form:"start_date" binding:"required"`
+		ExpiresAt string  `json:"expires_at" form:"expires_at" binding:"required"`
+		Notes     string  `json:"notes" form:"notes"`
+		Send      bool    `json:"send" form:"send"`
+	}
+	if err := c.ShouldBind(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+	startDate, err := time.Parse("2006-01-02", requestBody.StartDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "start_date must be formatted as YYYY-MM-DD"})
+		return
+	}
+	expiresAt, err := time.Parse(time.RFC3339, requestBody.ExpiresAt)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expires_at must be an RFC 3339 timestamp"})
+		return
+	}
+	if !expiresAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": db.ErrOfferExpiryPassed.Error()})
+		return
+	}
+
+	employerID, ok := callerID(c)
+	if !ok || !authorizeApplicationReviewer(c, employerID, applicationID) {
+		return
+	}
+
+	offer := schema.Offer{
+		ApplicationID: applicationID,
+		EmployerID:    &employerID,
+		Salary:        requestBody.Salary,
+		Currency:      strings.ToUpper(requestBody.Currency),
+		StartDate:     startDate,
+		ExpiresAt:     expiresAt,
+	}
+	if notes := strings.TrimSpace(requestBody.Notes); notes != "" {
+		offer.Notes = &notes
+	}
+	if !saveOfferLetter(c, &offer) {
+		return
+	}
+
+	offer, err = db.CreateOffer(context.Background(), offer, requestBody.Send, func(offer schema.Offer) (schema.Outbox, error) {
+		if offer.Status != schema.OfferSent {
+			return schema.Outbox{}, nil
+		}
+		return offerSentNotification(offer)
+	})
+	if err != nil {
+		if offer.LetterPath != nil {
+			os.Remove(*offer.LetterPath)
+		}
+		respondOfferError(c, err, "Failed to create offer")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Offer created successfully", "offer": offer})
+}
+
+// saveOfferLetter stores the optional offer letter of a multipart request and
+// writes an error response if it is rejected
+func saveOfferLetter(c *gin.Context, offer *schema.Offer) bool {
+	if !strings.HasPrefix(c.ContentType(), "multipart/") {
+		return true
+	}
+	fileHeader, err := c.FormFile("offer_letter")
+	if err != nil {
+		return true
+	}
+	if fileHeader.Size > helpers.MaxAttachmentSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Offer letter must not exceed 10 MB"})
+		return false
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read offer letter"})
+		return false
+	}
+	defer file.Close()
+
+	mimeType, err := helpers.DetectResumeType(file, fileHeader.Size, fileHeader.Filename)
+	if err != nil {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Offer letter must be a PDF or DOCX file"})
+		return false
+	}
+
+	fileName, err := helpers.AttachmentFileName(mimeType)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store offer letter"})
+		return false
+	}
+	path := filepath.Join(offerLetterDirectory, fileName)
+	if err := c.SaveUploadedFile(fileHeader, path); err != nil {
+		fmt.Printf("Error saving file: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store offer letter"})
+		return false
+	}
+
+	name := helpers.CleanFileName(fileHeader.Filename)
+	offer.LetterFileName = &name
+	offer.LetterPath = &path
+	return true
+}
+
+// SendOfferHandler sends a draft offer to the candidate
+func SendOfferHandler(c *gin.Context) {
+	offerID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offer ID"})
+		return
+	}
+
+	_, employerID, ok := authorizeOfferEmployer(c, offerID)
+	if !ok {
+		return
+	}
+
+	offer, err := db.SendOffer(context.Background(), offerID, employerID, offerSentNotification)
+	if err != nil {
+		respondOfferError(c, err, "Failed to send offer")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Offer sent successfully", "offer": offer})
+}
+
+// WithdrawOfferHandler takes back an offer the candidate has not answered
+func WithdrawOfferHandler(c *gin.Context) {
+	offerID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offer ID"})
+		return
+	}
+
+	if _, _, ok := authorizeOfferEmployer(c, offerID); !ok {
+		return
+	}
+
+	offer, err := db.WithdrawOffer(context.Background(), offerID, func(offer schema.Offer) (schema.Outbox, error) {
+		// Candidates never saw drafts, so only sent offers are announced
+		if offer.SentAt == nil {
+			return schema.Outbox{}, nil
+		}
+		return notify.Prepare(context.Background(), notify.Notification{
+			Event:    notify.EventOfferWithdrawn,
+			UserType: "job_seeker",
+			UserID:   offer.JobSeekerID,
+			Message:  fmt.Sprintf("The offer for %s has been withdrawn by the employer.", offer.JobTitle),
+		})
+	})
+	if err != nil {
+		respondOfferError(c, err, "Failed to withdraw offer")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Offer withdrawn successfully", "offer": offer})
+}
+
+// RespondToOfferHandler lets the candidate accept or decline a sent offer
+func RespondToOfferHandler(c *gin.Context) {
+	offerID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offer ID"})
+		return
+	}
+
+	var requestBody struct {
+		Accept *bool   `json:"accept" binding:"required"`
+		Reason *string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+	jobSeekerID, ok := callerID(c)
+	if !ok {
+		return
+	}
+
+	offer, filled, err := db.RespondToOffer(context.Background(), offerID, jobSeekerID, *requestBody.Accept, requestBody.Reason,
+		func(offer schema.Offer, filled bool) (schema.Outbox, error) {
+			if offer.EmployerID == nil {
+				return schema.Outbox{}, nil
+			}
+			message := fmt.Sprintf("The candidate on application %d has %s your offer for %s.",
+				offer.ApplicationID, strings.ToLower(offer.Status), offer.JobTitle)
+			if filled {
+				message += " All openings for this job are now filled."
+			}
+			return notify.Prepare(context.Background(), notify.Notification{
+				Event:    notify.EventOfferResponded,
+				UserType: "employer",
+				UserID:   *offer.EmployerID,
+				Message:  message,
+			})
+		})
+	if err != nil {
+		respondOfferError(c, err, "Failed to respond to offer")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Offer " + strings.ToLower(offer.Status) + " successfully",
+		"offer":      offer,
+		"job_filled": filled,
+	})
+}
+
+// GetApplicationOffersHandler lists the offers of an application to its hiring team
+func GetApplicationOffersHandler(c *gin.Context) {
+	applicationID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid application ID"})
+		return
+	}
+
+	employerID, ok := callerID(c)
+	if !ok || !authorizeApplicationReviewer(c, employerID, applicationID) {
+		return
+	}
+
+	offers, err := db.GetApplicationOffers(context.Background(), applicationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve offers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"offers": offers})
+}
+
+// GetSeekerOffersHandler lists the offers sent to the signed-in job seeker
+func GetSeekerOffersHandler(c *gin.Context) {
+	jobSeekerID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job seeker ID"})
+		return
+	}
+	if !authorizeSelf(c, jobSeekerID) {
+		return
+	}
+
+	offers, err := db.GetSeekerOffers(context.Background(), jobSeekerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve offers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"offers": offers})
+}
+
+// DownloadOfferLetterHandler serves an offer letter to the candidate once
+// sent, or to the hiring team
+func DownloadOfferLetterHandler(c *gin.Context) {
+	offerID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offer ID"})
+		return
+	}
+
+	offer, err := db.GetOffer(context.Background(), offerID)
+	if err != nil {
+		respondOfferError(c, err, "Failed to retrieve offer")
+		return
+	}
+
+	userID, ok := callerID(c)
+	if !ok {
+		return
+	}
+	switch c.GetString("user_type") {
+	case "job_seeker":
+		if userID != offer.JobSeekerID || offer.Status == schema.OfferDraft {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Access to this offer is denied"})
+			return
+		}
+	case "employer":
+		if !authorizeJobReviewer(c, userID, offer.JobListingID) {
+			return
+		}
+	}
+
+	if offer.LetterPath == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "This offer has no offer letter"})
+		return
+	}
+	c.FileAttachment(*offer.LetterPath, *offer.LetterFileName)
+}
+
+// SetOfferSettingsHandler sets how many openings a job has and whether it is
+// marked Filled once offers for all of them are accepted
+func SetOfferSettingsHandler(c *gin.Context) {
+	jobID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job listing ID"})
+		return
+	}
+
+	var requestBody struct {
+		Openings       int  `json:"openings" binding:"required,min=1"`
+		FillOnAccepted bool `json:"fill_on_accepted"`
+	}
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	employerID, ok := callerID(c)
+	if !ok || !authorizeJobReviewer(c, employerID, jobID) {
+		return
+	}
+
+	settings := schema.OfferSettings{
+		JobListingID:   jobID,
+		Openings:       requestBody.Openings,
+		FillOnAccepted: requestBody.FillOnAccepted,
+	}
+	if err := db.SetOfferSettings(context.Background(), settings); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update offer settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Offer settings updated successfully", "settings": settings})
+}
+
+// GetOfferSettingsHandler returns a job's openings and fill setting
+func GetOfferSettingsHandler(c *gin.Context) {
+	jobID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job listing ID"})
+		return
+	}
+
+	employerID, ok := callerID(c)
+	if !ok || !authorizeJobReviewer(c, employerID, jobID) {
+		return
+	}
+
+	settings, err := db.GetOfferSettings(context.Background(), jobID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve offer settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"settings": settings})
+}
@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"Backend/internal/db"
+	"Backend/internal/schema"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxOutboxPage caps how many outbox messages are returned at once
+const maxOutboxPage = 200
+
+// GetOutboxMessagesHandler lists the newest outbox messages for inspection.
+// Supports ?status=Pending|Sent|Dead and ?limit= (default 50).
+func GetOutboxMessagesHandler(c *gin.Context) {
+	status := c.Query("status")
+	if status != "" && status != schema.OutboxPending && status != schema.OutboxSent && status != schema.OutboxDead {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be Pending, Sent or Dead"})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > maxOutboxPage {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("limit must be between 1 and %d", maxOutboxPage)})
+		return
+	}
+
+	messages, err := db.GetOutboxMessages(context.Background(), status, limit)
+	if err != nil {
+		fmt.Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve outbox messages"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"messages": messages})
+}
+
+// RetryOutboxMessageHandler queues a dead message, or a pending one whose
+// delivery stopped reporting back, for immediate delivery with a fresh set of
+// attempts
+func RetryOutboxMessageHandler(c *gin.Context) {
+	messageID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid outbox message ID"})
+		return
+	}
+
+	message, err := db.RetryOutboxMessage(context.Background(), messageID)
+	switch {
+	case errors.Is(err, db.ErrOutboxMessageNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, db.ErrOutboxMessageSent), errors.Is(err, db.ErrOutboxMessageQueued):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		fmt.Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retry outbox message"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Outbox message queued for delivery", "outbox_message": message})
+}
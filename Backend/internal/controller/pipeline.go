@@ -0,0 +1,264 @@
+package controller
+
+import (
+	"Backend/internal/db"
+	"Backend/internal/notify"
+	"Backend/internal/schema"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// mappedStatuses are the application statuses a pipeline stage can map to
+var mappedStatuses = map[string]bool{
+	"Applied":             true,
+	"Interview Scheduled": true,
+	"Rejected":            true,
+	"Accepted":            true,
+}
+
+type pipelineStagesRequest struct {
+	Stages []schema.PipelineStage `json:"stages" binding:"required,dive"`
+}
+
+// bindPipelineStages parses and validates an ordered list of stages
+func bindPipelineStages(c *gin.Context) ([]schema.PipelineStage, bool) {
+	var request pipelineStagesRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return nil, false
+	}
+	if len(request.Stages) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "At least one stage is required"})
+		return nil, false
+	}
+
+	seen := make(map[string]bool)
+	for i := range request.Stages {
+		request.Stages[i].StageName = strings.TrimSpace(request.Stages[i].StageName)
+		name := strings.ToLower(request.Stages[i].StageName)
+		if name == "" || seen[name] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Stage names must be non-empty and unique"})
+			return nil, false
+		}
+		seen[name] = true
+
+		if !mappedStatuses[request.Stages[i].MappedStatus] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid mapped_status. Allowed values: 'Applied', 'Interview Scheduled', 'Rejected' or 'Accepted'"})
+			return nil, false
+		}
+	}
+	return request.Stages, true
+}
+
+// authorizeCompanyEmployer checks that the signed-in employer works for a
+// company, writing an error response if not
+func authorizeCompanyEmployer(c *gin.Context, companyID int) bool {
+	employerID, ok := callerID(c)
+	if !ok {
+		return false
+	}
+	allowed, err := db.EmployerWorksForCompany(context.Background(), employerID, companyID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify employer access"})
+		return false
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Employer cannot access this company"})
+		return false
+	}
+	return true
+}
+
+// authorizeSignedInJobReviewer checks that the signed-in employer works for
+// the company that posted a job, writing an error response if not
+func authorizeSignedInJobReviewer(c *gin.Context, jobID int) bool {
+	employerID, ok := callerID(c)
+	return ok && authorizeJobReviewer(c, employerID, jobID)
+}
+
+// SetCompanyStagesHandler replaces the default pipeline of a company
+func SetCompanyStagesHandler(c *gin.Context) {
+	companyID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid company ID"})
+		return
+	}
+	if !authorizeCompanyEmployer(c, companyID) {
+		return
+	}
+
+	stages, ok := bindPipelineStages(c)
+	if !ok {
+		return
+	}
+
+	result, err := db.SetCompanyPipelineStages(context.Background(), companyID, stages)
+	if err != nil {
+		respondPipelineError(c, err, "Failed to update pipeline stages")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Pipeline stages updated successfully", "stages": result})
+}
+
+// GetCompanyStagesHandler returns the default pipeline of a company
+func GetCompanyStagesHandler(c *gin.Context) {
+	companyID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid company ID"})
+		return
+	}
+	if !authorizeCompanyEmployer(c, companyID) {
+		return
+	}
+
+	stages, err := db.GetCompanyPipelineStages(context.Background(), companyID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve pipeline stages"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"stages": stages})
+}
+
+// SetJobStagesHandler replaces the job-specific pipeline of a job listing
+func SetJobStagesHandler(c *gin.Context) {
+	jobID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job listing ID"})
+		return
+	}
+	if !authorizeSignedInJobReviewer(c, jobID) {
+		return
+	}
+
+	stages, ok := bindPipelineStages(c)
+	if !ok {
+		return
+	}
+
+	result, err := db.SetJobPipelineStages(context.Background(), jobID, stages)
+	if err != nil {
+		respondPipelineError(c, err, "Failed to update pipeline stages")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Pipeline stages updated successfully", "stages": result})
+}
+
+// GetJobStagesHandler returns the effective pipeline of a job listing
+func GetJobStagesHandler(c *gin.Context) {
+	jobID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job listing ID"})
+		return
+	}
+	if !authorizeSignedInJobReviewer(c, jobID) {
+		return
+	}
+
+	stages, err := db.GetPipelineStages(context.Background(), jobID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve pipeline stages"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"stages": stages})
+}
+
+// GetStageCountsHandler returns the number of applications per stage for a job
+func GetStageCountsHandler(c *gin.Context) {
+	jobID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job listing ID"})
+		return
+	}
+	if !authorizeSignedInJobReviewer(c, jobID) {
+		return
+	}
+
+	counts, err := db.GetStageCounts(context.Background(), jobID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve stage counts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"job_listing_id": jobID, "stage_counts": counts})
+}
+
+// MoveApplicationStageHandler moves an application to another pipeline stage
+// and notifies the job seeker when their visible status changes.
+func MoveApplicationStageHandler(c *gin.Context) {
+	applicationID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid application ID"})
+		return
+	}
+
+	var requestBody struct {
+		StageID int     `json:"stage_id" binding:"required"`
+		Reason  *string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	employerID, ok := callerID(c)
+	if !ok || !authorizeApplicationReviewer(c, employerID, applicationID) {
+		return
+	}
+
+	previous, err := db.GetApplication(context.Background(), applicationID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Application not found"})
+		return
+	}
+
+	updatedApplication, err := db.MoveApplicationStage(context.Background(), applicationID, requestBody.StageID,
+		schema.Actor{Type: "employer", ID: &employerID}, requestBody.Reason, func(moved schema.Application) (schema.Outbox, error) {
+			if moved.ApplicationStatus == previous.ApplicationStatus {
+				return schema.Outbox{}, nil
+			}
+			// Create a notification for the job seeker
+			message := fmt.Sprintf("The status of your application %d is now %s.", applicationID, moved.ApplicationStatus)
+			return notify.Prepare(context.Background(), notify.Notification{
+				Event:    notify.EventApplicationStatusChanged,
+				UserType: "job_seeker",
+				UserID:   moved.JobSeekerID,
+				Message:  message,
+			})
+		})
+	if err != nil {
+		respondPipelineError(c, err, "Failed to move application")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Application moved successfully",
+		"application": updatedApplication,
+	})
+}
+
+// respondPipelineError maps pipeline validation errors to client error responses
+func respondPipelineError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, db.ErrNoPipeline),
+		errors.Is(err, db.ErrStageNotInJob),
+		errors.Is(err, db.ErrAlreadyInStage),
+		errors.Is(err, db.ErrApplicationFinal),
+		errors.Is(err, db.ErrApplicationWithdrawn):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, db.ErrStageInUse):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		fmt.Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,264 @@
+package controller
+
+import (
+	"Backend/internal/db"
+	"Backend/internal/helpers"
+	"Backend/internal/schema"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const resumeDirectory = "uploads/Resumes"
+
+// UploadResumeHandler stores a new resume version for a job seeker from the
+// multipart field "resume". Set "make_default" to "true" to make it the
+// default; a seeker's first resume is always the default.
+func UploadResumeHandler(c *gin.Context) {
+	jobSeekerID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job seeker ID"})
+		return
+	}
+	if !authorizeSelf(c, jobSeekerID) {
+		return
+	}
+
+	// Reject oversized bodies before they are buffered
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, helpers.MaxResumeSize+1<<20)
+	fileHeader, err := c.FormFile("resume")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A resume file up to 5 MB is required"})
+		return
+	}
+	if fileHeader.Size > helpers.MaxResumeSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Resume must not exceed 5 MB"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read resume"})
+		return
+	}
+	defer file.Close()
+
+	mimeType, err := helpers.DetectResumeType(file, fileHeader.Size, fileHeader.Filename)
+	if err != nil {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": helpers.ErrUnsupportedResume.Error()})
+		return
+	}
+
+	fileName, err := helpers.ResumeFileName(mimeType)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store resume"})
+		return
+	}
+	path := filepath.Join(resumeDirectory, fmt.Sprintf("%d_%s", jobSeekerID, fileName))
+	if err := c.SaveUploadedFile(fileHeader, path); err != nil {
+		fmt.Printf("Error saving file: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store resume"})
+		return
+	}
+
+	resume, err := db.CreateResume(context.Background(), schema.Resume{
+		JobSeekerID: jobSeekerID,
+		FileName:    filepath.Base(fileHeader.Filename),
+		FilePath:    path,
+		MimeType:    mimeType,
+		SizeBytes:   fileHeader.Size,
+	}, c.PostForm("make_default") == "true")
+	if err != nil {
+		os.Remove(path)
+		fmt.Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save resume"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Resume uploaded successfully", "resume": resume})
+}
+
+// GetResumesHandler lists all resume versions of a job seeker
+func GetResumesHandler(c *gin.Context) {
+	jobSeekerID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job seeker ID"})
+		return
+	}
+	if !authorizeSelf(c, jobSeekerID) {
+		return
+	}
+
+	resumes, err := db.GetResumes(context.Background(), jobSeekerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve resumes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"resumes": resumes})
+}
+
+// SetDefaultResumeHandler makes a resume the default attached to new applications
+func SetDefaultResumeHandler(c *gin.Context) {
+	resumeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid resume ID"})
+		return
+	}
+
+	jobSeekerID, ok := callerID(c)
+	if !ok {
+		return
+	}
+
+	err = db.SetDefaultResume(context.Background(), jobSeekerID, resumeID)
+	if errors.Is(err, db.ErrResumeNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, db.ErrResumeNotOwned) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set default resume"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Default resume updated successfully"})
+}
+
+// DownloadResumeHandler serves a resume file to its owner or to an employer
+// whose company received it with an application
+func DownloadResumeHandler(c *gin.Context) {
+	resumeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid resume ID"})
+		return
+	}
+
+	resume, err := db.GetResume(context.Background(), resumeID)
+	if errors.Is(err, db.ErrResumeNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve resume"})
+		return
+	}
+
+	userID, ok := callerID(c)
+	if !ok {
+		return
+	}
+	allowed := false
+	switch c.GetString("user_type") {
+	case "job_seeker":
+		allowed = userID == resume.JobSeekerID
+	case "employer":
+		allowed, err = db.EmployerCanAccessResume(context.Background(), userID, resumeID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify employer access"})
+			return
+		}
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access to this resume is denied"})
+		return
+	}
+
+	c.FileAttachment(resume.FilePath, resume.FileName)
+}
+
+// ParseResumeHandler extracts a profile draft from one of the signed-in job
+// seeker's resumes. The draft is only returned, never saved.
+func ParseResumeHandler(c *gin.Context) {
+	resumeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid resume ID"})
+		return
+	}
+	jobSeekerID, ok := callerID(c)
+	if !ok {
+		return
+	}
+
+	resume, err := db.GetResume(context.Background(), resumeID)
+	if errors.Is(err, db.ErrResumeNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve resume"})
+		return
+	}
+	if resume.JobSeekerID != jobSeekerID {
+		c.JSON(http.StatusForbidden, gin.H{"error": db.ErrResumeNotOwned.Error()})
+		return
+	}
+
+	text, err := helpers.ExtractResumeText(resume.FilePath, resume.MimeType)
+	if err != nil {
+		fmt.Println("Failed to extract resume text:", err)
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Could not read text from this resume"})
+		return
+	}
+
+	draft := helpers.ParseResumeText(text, time.Now())
+	draft.ResumeID = resume.ID
+	c.JSON(http.StatusOK, gin.H{"draft": draft})
+}
+
+// AcceptProfileDraftHandler saves a reviewed draft into the job seeker's
+// profile. Entries are added to the profile unless "replace" is true.
+func AcceptProfileDraftHandler(c *gin.Context) {
+	jobSeekerID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job seeker ID"})
+		return
+	}
+	if !authorizeSelf(c, jobSeekerID) {
+		return
+	}
+
+	var requestBody struct {
+		schema.ProfileDraft
+		Replace bool `json:"replace"`
+	}
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+	for _, skill := range requestBody.Skills {
+		switch skill.SkillProficiency {
+		case "Beginner", "Intermediate", "Advanced", "Expert":
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid skill level: " + skill.SkillProficiency})
+			return
+		}
+	}
+
+	jobSeeker, err := db.GetJobSeeker(context.Background(), jobSeekerID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job seeker not found"})
+		return
+	}
+
+	helpers.MergeProfileDraft(&jobSeeker, requestBody.ProfileDraft, requestBody.Replace)
+
+	if err := db.UpdateJobSeekerDetails(context.Background(), jobSeeker); err != nil {
+		fmt.Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile"})
+		return
+	}
+
+	jobSeeker.Password = ""
+	c.JSON(http.StatusOK, gin.H{"message": "Job Seeker profile updated successfully", "profile": jobSeeker})
+}
@@ -0,0 +1,158 @@
+package controller
+
+import (
+	"Backend/internal/db"
+	"Backend/internal/schema"
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultScaleMax = 5
+
+// respondScorecardError maps scorecard and feedback errors to client error responses
+func respondScorecardError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, db.ErrCompetencyNotInJob), errors.Is(err, db.ErrInvalidRatings):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, db.ErrNotOnPanel):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, db.ErrCompetencyInUse),
+		errors.Is(err, db.ErrFeedbackNotOpen),
+		errors.Is(err, db.ErrFeedbackSubmitted):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		respondInterviewError(c, err, fallback)
+	}
+}
+
+// SetScorecardHandler replaces the competencies interviewers rate candidates
+// on for a job
+func SetScorecardHandler(c *gin.Context) {
+	jobID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job listing ID"})
+		return
+	}
+
+	var requestBody struct {
+		Competencies []schema.ScorecardCompetency `json:"competencies" binding:"required,dive"`
+	}
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	seen := make(map[string]bool)
+	for i := range requestBody.Competencies {
+		requestBody.Competencies[i].Name = strings.TrimSpace(requestBody.Competencies[i].Name)
+		name := strings.ToLower(requestBody.Competencies[i].Name)
+		if name == "" || seen[name] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Competency names must be non-empty and unique"})
+			return
+		}
+		seen[name] = true
+		if requestBody.Competencies[i].ScaleMax == 0 {
+			requestBody.Competencies[i].ScaleMax = defaultScaleMax
+		}
+	}
+
+	if !authorizeSignedInJobReviewer(c, jobID) {
+		return
+	}
+
+	competencies, err := db.SetScorecard(context.Background(), jobID, requestBody.Competencies)
+	if err != nil {
+		respondScorecardError(c, err, "Failed to update scorecard")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Scorecard updated successfully", "competencies": competencies})
+}
+
+// GetScorecardHandler returns the scorecard competencies of a job
+func GetScorecardHandler(c *gin.Context) {
+	jobID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job listing ID"})
+		return
+	}
+
+	competencies, err := db.GetScorecard(context.Background(), jobID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve scorecard"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"competencies": competencies})
+}
+
+// SubmitFeedbackHandler stores an interviewer's scorecard and hire
+// recommendation for an interview they were on
+func SubmitFeedbackHandler(c *gin.Context) {
+	interviewID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid interview ID"})
+		return
+	}
+
+	var requestBody struct {
+		Recommendation string                    `json:"recommendation" binding:"required"`
+		Notes          *string                   `json:"notes"`
+		Ratings        []schema.CompetencyRating `json:"ratings" binding:"dive"`
+	}
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data", "details": err.Error()})
+		return
+	}
+	if !slices.Contains(schema.Recommendations, requestBody.Recommendation) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recommendation. Allowed values: 'Strong Hire', 'Hire', 'No Hire' or 'Strong No Hire'"})
+		return
+	}
+	interviewerID, ok := callerID(c)
+	if !ok {
+		return
+	}
+
+	feedback, err := db.SubmitFeedback(context.Background(), schema.InterviewFeedback{
+		InterviewID:    interviewID,
+		InterviewerID:  interviewerID,
+		Recommendation: requestBody.Recommendation,
+		Notes:          requestBody.Notes,
+		Ratings:        requestBody.Ratings,
+	})
+	if err != nil {
+		respondScorecardError(c, err, "Failed to submit feedback")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Feedback submitted successfully", "feedback": feedback})
+}
+
+// GetApplicationFeedbackHandler returns the interview feedback of an
+// application with aggregated results. Interviewers who still owe feedback
+// on the application only see their own.
+func GetApplicationFeedbackHandler(c *gin.Context) {
+	applicationID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid application ID"})
+		return
+	}
+	employerID, ok := callerID(c)
+	if !ok || !authorizeApplicationReviewer(c, employerID, applicationID) {
+		return
+	}
+
+	summary, err := db.GetApplicationFeedback(context.Background(), applicationID, employerID)
+	if err != nil {
+		respondScorecardError(c, err, "Failed to retrieve feedback")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"feedback": summary})
+}
@@ -12,6 +12,33 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// callerID returns the ID of the signed-in caller from their token. It writes
+// the error response and returns false when the token names no user, as the
+// development tokens do.
+func callerID(c *gin.Context) (int, bool) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden: Access denied"})
+		return 0, false
+	}
+	return userID, true
+}
+
+// authorizeSelf checks that the caller signed in as the user whose ID is in
+// the path. It writes the error response and returns false otherwise.
+// Development tokens name no user, so they cannot pass.
+func authorizeSelf(c *gin.Context, userID int) bool {
+	signedIn, ok := callerID(c)
+	if !ok {
+		return false
+	}
+	if signedIn != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden: Access denied"})
+		return false
+	}
+	return true
+}
+
 // ------------------------------
 // Registration Handlers
 // ------------------------------
@@ -148,11 +175,18 @@ func SeekerLoginHandler(c *gin.Context) {
 	userID = jobSeeker.ID
 	firstName = jobSeeker.FirstName
 
+	token, err := helpers.GenerateJWT(userID, "job_seeker")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign in"})
+		return
+	}
+
 	// Return the login response with token and user details.
 	c.JSON(http.StatusOK, schema.LoginResponse{
 		UserID:    userID,
 		FirstName: firstName,
 		Email:     loginReq.Email,
+		Token:     token,
 	})
 }
 
@@ -179,11 +213,18 @@ func EmployerLoginHandler(c *gin.Context) {
 	}
 	userID = employer.ID
 
+	token, err := helpers.GenerateJWT(userID, "employer")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign in"})
+		return
+	}
+
 	// Return the login response with token and user details.
 	c.JSON(http.StatusOK, schema.LoginResponse{
 		UserID:    userID,
 		FirstName: firstName,
 		Email:     loginReq.Email,
+		Token:     token,
 	})
 }
 
@@ -254,6 +295,13 @@ func UpdateJobSeekerProfile(c *gin.Context) {
 	jobSeeker.LinkedinURL = jobSeekerInput.LinkedinURL
 	jobSeeker.Resume = jobSeekerInput.Resume
 	jobSeeker.ProfilePicture = jobSeekerInput.ProfilePicture
+	if jobSeekerInput.TimeZone != "" {
+		if !helpers.ValidTimeZone(jobSeekerInput.TimeZone) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid time_zone. Use an IANA name such as Europe/Berlin."})
+			return
+		}
+		jobSeeker.TimeZone = jobSeekerInput.TimeZone
+	}
 
 	// If the password is provided for update, hash it.
 	if jobSeeker.Password != "" {
@@ -294,6 +342,12 @@ func UpdateEmployerProfile(c *gin.Context) {
 	employer.ID = id
 	employer.Password = temp.Password
 	employer.CompanyID = temp.CompanyID
+	if employer.TimeZone == "" {
+		employer.TimeZone = temp.TimeZone
+	} else if !helpers.ValidTimeZone(employer.TimeZone) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid time_zone. Use an IANA name such as Europe/Berlin."})
+		return
+	}
 
 	if err := db.UpdateEmployer(context.Background(), employer); err != nil {
 		fmt.Println(2)
@@ -4,10 +4,27 @@ import (
 	"Backend/config"
 	"Backend/internal/schema"
 	"context"
+	"errors"
 	"time"
 	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrNotApplicationOwner  = errors.New("application does not belong to this job seeker")
+	ErrApplicationWithdrawn = errors.New("application has been withdrawn")
+	ErrCannotWithdraw       = errors.New("only pending applications can be withdrawn")
+	ErrAlreadyApplied       = errors.New("you have already applied for this job")
 )
 
+// isDuplicateApplication reports whether err is a violation of the index
+// allowing one active application per seeker and job
+func isDuplicateApplication(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "uq_applications_seeker_job"
+}
+
 func GenerateApplicationID(ctx context.Context) (int, error) {
 	var newID int
 	query := `SELECT generate_application_id()` // Replace with actual stored procedure name
@@ -18,14 +35,22 @@ func GenerateApplicationID(ctx context.Context) (int, error) {
 	return newID, nil
 }
 
-func CreateApplication(ctx context.Context, application schema.Application) (schema.Application, error) {
+// CreateApplication stores an application. announce, if given, builds the
+// notifications about it, which are stored in the same transaction.
+func CreateApplication(ctx context.Context, application schema.Application, announce func(schema.Application) (schema.Outbox, error)) (schema.Application, error) {
 	query := `
         INSERT INTO applications (job_seeker_id, job_listing_id, application_status, applied_date, cover_letter) 
         VALUES ($1, $2, $3, $4, $5) 
         RETURNING id, job_seeker_id, job_listing_id, application_status, applied_date, cover_letter
     `
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		return schema.Application{}, err
+	}
+	defer tx.Rollback(ctx)
+
 	var result schema.Application
-	err := config.DB.QueryRow(ctx, query, 
+	err = tx.QueryRow(ctx, query, 
 		application.JobSeekerID, 
 		application.JobListingID, 
 		application.ApplicationStatus, 
@@ -40,9 +65,43 @@ func CreateApplication(ctx context.Context, application schema.Application) (sch
 		&result.CoverLetter,
 	)
 
+	if isDuplicateApplication(err) {
+		return schema.Application{}, ErrAlreadyApplied
+	}
+	if err != nil {
+		return schema.Application{}, err
+	}
+
+	result.ResumeID, err = attachResume(ctx, tx, result.ID, result.JobSeekerID, application.ResumeID)
+	if err != nil {
+		return schema.Application{}, err
+	}
+
+	// Record the submission as the first event of the application's history
+	err = recordApplicationEvent(ctx, tx, schema.ApplicationEvent{
+		ApplicationID: result.ID,
+		EventType:     schema.EventApplied,
+		ToStatus:      &result.ApplicationStatus,
+		ActorType:     "job_seeker",
+		ActorID:       &result.JobSeekerID,
+	})
 	if err != nil {
 		return schema.Application{}, err
 	}
+
+	if announce != nil {
+		outbox, err := announce(result)
+		if err != nil {
+			return schema.Application{}, err
+		}
+		if err := storeOutbox(ctx, tx, outbox); err != nil {
+			return schema.Application{}, err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return schema.Application{}, err
+	}
 	return result, nil
 }
 
@@ -89,12 +148,18 @@ func GetJobApplications(ctx context.Context, jobListingID int) ([]schema.Applica
 	    js.last_name, 
 	    js.email, 
 	    js.phone_number, 
+	    js.profile_picture,
+	    js.linkedin_url,
 	    js.resume,
 	    a.applied_date,
 	    a.cover_letter,
-		a.application_status
+		a.application_status,
+		a.resume_id,
+		a.stage_id,
+		ps.stage_name
 	FROM applications a
 	JOIN job_seekers js ON a.job_seeker_id = js.id
+	LEFT JOIN pipeline_stages ps ON a.stage_id = ps.id
 	WHERE a.job_listing_id = $1`
 
 	rows, err := config.DB.Query(ctx, query, jobListingID)
@@ -112,7 +177,8 @@ func GetJobApplications(ctx context.Context, jobListingID int) ([]schema.Applica
 
 		err := rows.Scan(
 			&app.ApplicationID, &jobSeekerID, &app.FirstName, &app.LastName, &app.Email,
-			&app.PhoneNumber, &app.Resume, &app.AppliedDate, &app.CoverLetter,&app.ApplicationStatus,
+			&app.PhoneNumber, &app.ProfilePicture, &app.LinkedinURL, &app.Resume, &app.AppliedDate, &app.CoverLetter,&app.ApplicationStatus,
+			&app.ResumeID, &app.StageID, &app.StageName,
 		)
 		if err != nil {
 			return nil, err
@@ -190,7 +256,7 @@ func GetJobApplications(ctx context.Context, jobListingID int) ([]schema.Applica
 
 		// Fetch skills
 		skillsQuery := `
-		SELECT skill_name 
+		SELECT skill_name, skill_level 
 		FROM job_seeker_skills 
 		WHERE job_seeker_id = $1`
 
@@ -200,12 +266,36 @@ func GetJobApplications(ctx context.Context, jobListingID int) ([]schema.Applica
 		}
 		defer skillRows.Close()
 
+		applications[i].SkillLevels = make(map[string]string)
 		for skillRows.Next() {
-			var skill string
-			if err := skillRows.Scan(&skill); err != nil {
+			var skill, level string
+			if err := skillRows.Scan(&skill, &level); err != nil {
 				return nil, err
 			}
 			applications[i].Skills = append(applications[i].Skills, skill)
+			applications[i].SkillLevels[skill] = level
+		}
+
+		applications[i].Tags, err = GetApplicationTags(ctx, applications[i].ApplicationID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Hide candidate identity on blind-reviewed jobs until it is revealed
+	settings, err := GetBlindReviewSettings(ctx, jobListingID)
+	if err != nil {
+		return nil, err
+	}
+	if settings.BlindReview {
+		revealed, err := getRevealedApplicationIDs(ctx, jobListingID)
+		if err != nil {
+			return nil, err
+		}
+		for i := range applications {
+			if !revealed[applications[i].ApplicationID] {
+				redactIdentity(&applications[i])
+			}
 		}
 	}
 
@@ -217,7 +307,7 @@ func GetJobApplications(ctx context.Context, jobListingID int) ([]schema.Applica
 func GetApplication(ctx context.Context, applicationID int) (schema.Application, error) {
 	var app schema.Application
 
-	query := `SELECT id, job_seeker_id, job_listing_id, application_status, applied_date, cover_letter 
+	query := `SELECT id, job_seeker_id, job_listing_id, application_status, applied_date, cover_letter, stage_id 
 			  FROM applications WHERE id = $1`
 
 	err := config.DB.QueryRow(ctx, query, applicationID).Scan(
@@ -227,6 +317,7 @@ func GetApplication(ctx context.Context, applicationID int) (schema.Application,
 		&app.ApplicationStatus,
 		&app.AppliedDate,
 		&app.CoverLetter,
+		&app.StageID,
 	)
 
 	if err != nil {
@@ -236,22 +327,103 @@ func GetApplication(ctx context.Context, applicationID int) (schema.Application,
 	return app, nil
 }
 
-func UpdateApplicationStatus(ctx context.Context, applicationID int, status string) (schema.Application, error) {
+// UpdateApplicationStatus sets a decision on an application and records it in
+// the history. reasonCode is kept only for rejections. announce, if given,
+// builds the notifications about the decision and the interviews it
+// cancelled, which are stored in the same transaction.
+func UpdateApplicationStatus(ctx context.Context, applicationID int, status string, actor schema.Actor, reason, reasonCode *string,
+	announce func(application schema.Application, cancelled []schema.Interview) (schema.Outbox, error)) (schema.Application, error) {
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		return schema.Application{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	updatedApplication, cancelled, err := updateApplicationStatus(ctx, tx, applicationID, status, actor, reason, reasonCode)
+	if err != nil {
+		return schema.Application{}, err
+	}
+
+	if announce != nil {
+		outbox, err := announce(updatedApplication, cancelled)
+		if err != nil {
+			return schema.Application{}, err
+		}
+		if err := storeOutbox(ctx, tx, outbox); err != nil {
+			return schema.Application{}, err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return schema.Application{}, err
+	}
+	return updatedApplication, nil
+}
+
+// updateApplicationStatus sets a decision inside the caller's transaction and
+// returns the interviews it cancelled
+func updateApplicationStatus(ctx context.Context, tx querier, applicationID int, status string, actor schema.Actor, reason, reasonCode *string) (schema.Application, []schema.Interview, error) {
+	var previousStatus string
+	err := tx.QueryRow(ctx, `SELECT application_status FROM applications WHERE id = $1 FOR UPDATE`, applicationID).Scan(&previousStatus)
+	if err != nil {
+		return schema.Application{}, nil, err
+	}
+	if previousStatus == "Withdrawn" {
+		return schema.Application{}, nil, ErrApplicationWithdrawn
+	}
+
+	if status != "Rejected" {
+		reasonCode = nil
+	}
+
 	// Update the application status
-	query := `UPDATE applications SET application_status = $1 WHERE id = $2 RETURNING *`
+	query := `UPDATE applications SET application_status = $1, rejection_reason_code = $3 WHERE id = $2
+			  RETURNING id, job_seeker_id, job_listing_id, application_status, applied_date, cover_letter, stage_id, rejection_reason_code`
 	var updatedApplication schema.Application
-	err := config.DB.QueryRow(ctx, query, status, applicationID).Scan(
+	err = tx.QueryRow(ctx, query, status, applicationID, reasonCode).Scan(
 		&updatedApplication.ID,
 		&updatedApplication.JobSeekerID,
 		&updatedApplication.JobListingID,
 		&updatedApplication.ApplicationStatus,
 		&updatedApplication.AppliedDate,
 		&updatedApplication.CoverLetter,
+		&updatedApplication.StageID,
+		&updatedApplication.RejectionReasonCode,
 	)
 	if err != nil {
-		return schema.Application{}, err
+		return schema.Application{}, nil, err
 	}
-	return updatedApplication, nil
+
+	// A new decision supersedes messages still waiting to be sent about the old one
+	if err = cancelScheduledNotifications(ctx, tx, applicationID); err != nil {
+		return schema.Application{}, nil, err
+	}
+
+	// A decision ends the interview process; completed rounds stay as history
+	cancelled, err := cancelScheduledInterviews(ctx, tx, applicationID)
+	if err != nil {
+		return schema.Application{}, nil, err
+	}
+
+	err = recordApplicationEvent(ctx, tx, schema.ApplicationEvent{
+		ApplicationID: applicationID,
+		EventType:     schema.EventDecision,
+		FromStatus:    &previousStatus,
+		ToStatus:      &updatedApplication.ApplicationStatus,
+		StageID:       updatedApplication.StageID,
+		ActorType:     actor.Type,
+		ActorID:       actor.ID,
+		Reason:        reason,
+	})
+	if err != nil {
+		return schema.Application{}, nil, err
+	}
+
+	if err = revealIdentityIfDue(ctx, tx, updatedApplication, actor); err != nil {
+		return schema.Application{}, nil, err
+	}
+
+	return updatedApplication, cancelled, nil
 }
 
 func GetAcceptedResults(ctx context.Context, jobSeekerID int) ([]schema.ApplicationandJob, error) {
@@ -345,3 +517,94 @@ func GetResultCount(ctx context.Context, seekerID int) (int, error) {
 	}
 	return count, nil
 }
+
+// GetApplicationEmployerID returns the employer who posted the job an application was made for
+func GetApplicationEmployerID(ctx context.Context, applicationID int) (int, error) {
+	query := `
+		SELECT j.employer_id
+		FROM applications a
+		JOIN job_listings j ON a.job_listing_id = j.id
+		WHERE a.id = $1`
+	var employerID int
+	err := config.DB.QueryRow(ctx, query, applicationID).Scan(&employerID)
+	if err != nil {
+		return 0, err
+	}
+	return employerID, nil
+}
+
+// WithdrawApplication moves a pending application to Withdrawn and cancels its
+// scheduled interviews. The counters on job_seekers and job_listings are
+// adjusted by the triggers on the applications and interviews tables.
+// announce, if given, builds the notifications about the withdrawal, which
+// are stored in the same transaction.
+func WithdrawApplication(ctx context.Context, applicationID, jobSeekerID int, reason *string, allowReapply bool,
+	announce func(application schema.Application, cancelled []schema.Interview) (schema.Outbox, error)) (schema.Application, error) {
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		return schema.Application{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	var ownerID int
+	var previousStatus string
+	err = tx.QueryRow(ctx, `SELECT job_seeker_id, application_status FROM applications WHERE id = $1 FOR UPDATE`, applicationID).Scan(&ownerID, &previousStatus)
+	if err != nil {
+		return schema.Application{}, err
+	}
+	if ownerID != jobSeekerID {
+		return schema.Application{}, ErrNotApplicationOwner
+	}
+	if previousStatus != "Applied" && previousStatus != "Interview Scheduled" {
+		return schema.Application{}, ErrCannotWithdraw
+	}
+
+	query := `UPDATE applications SET application_status = 'Withdrawn', reapply_allowed = $1 WHERE id = $2
+			  RETURNING id, job_seeker_id, job_listing_id, application_status, applied_date, cover_letter, stage_id`
+	var withdrawn schema.Application
+	err = tx.QueryRow(ctx, query, allowReapply, applicationID).Scan(
+		&withdrawn.ID,
+		&withdrawn.JobSeekerID,
+		&withdrawn.JobListingID,
+		&withdrawn.ApplicationStatus,
+		&withdrawn.AppliedDate,
+		&withdrawn.CoverLetter,
+		&withdrawn.StageID,
+	)
+	if err != nil {
+		return schema.Application{}, err
+	}
+
+	cancelled, err := cancelScheduledInterviews(ctx, tx, applicationID)
+	if err != nil {
+		return schema.Application{}, err
+	}
+
+	err = recordApplicationEvent(ctx, tx, schema.ApplicationEvent{
+		ApplicationID: applicationID,
+		EventType:     schema.EventWithdrawn,
+		FromStatus:    &previousStatus,
+		ToStatus:      &withdrawn.ApplicationStatus,
+		ActorType:     "job_seeker",
+		ActorID:       &jobSeekerID,
+		Reason:        reason,
+	})
+	if err != nil {
+		return schema.Application{}, err
+	}
+
+	if announce != nil {
+		outbox, err := announce(withdrawn, cancelled)
+		if err != nil {
+			return schema.Application{}, err
+		}
+		if err := storeOutbox(ctx, tx, outbox); err != nil {
+			return schema.Application{}, err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return schema.Application{}, err
+	}
+	return withdrawn, nil
+}
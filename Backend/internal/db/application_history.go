@@ -0,0 +1,65 @@
+package db
+
+import (
+	"Backend/config"
+	"Backend/internal/schema"
+	"context"
+)
+
+// RecordApplicationEvent appends an event to the history of an application
+func RecordApplicationEvent(ctx context.Context, event schema.ApplicationEvent) error {
+	return recordApplicationEvent(ctx, config.DB, event)
+}
+
+func recordApplicationEvent(ctx context.Context, q querier, event schema.ApplicationEvent) error {
+	query := `
+		INSERT INTO application_history (
+			application_id, event_type, from_status, to_status, stage_id, stage_name, actor_type, actor_id, reason
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+
+	_, err := q.Exec(ctx, query,
+		event.ApplicationID,
+		event.EventType,
+		event.FromStatus,
+		event.ToStatus,
+		event.StageID,
+		event.StageName,
+		event.ActorType,
+		event.ActorID,
+		event.Reason,
+	)
+	return err
+}
+
+// GetApplicationHistory returns the events of an application, oldest first
+func GetApplicationHistory(ctx context.Context, applicationID int) ([]schema.ApplicationEvent, error) {
+	query := `
+		SELECT id, application_id, event_type, from_status, to_status, stage_id, stage_name,
+		       actor_type, actor_id, reason, created_at
+		FROM application_history
+		WHERE application_id = $1
+		ORDER BY created_at, id`
+
+	rows, err := config.DB.Query(ctx, query, applicationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []schema.ApplicationEvent
+	for rows.Next() {
+		var event schema.ApplicationEvent
+		err := rows.Scan(&event.ID, &event.ApplicationID, &event.EventType, &event.FromStatus, &event.ToStatus,
+			&event.StageID, &event.StageName, &event.ActorType, &event.ActorID, &event.Reason, &event.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
@@ -0,0 +1,217 @@
+package db
+
+import (
+	"Backend/config"
+	"Backend/internal/schema"
+	"context"
+	"strings"
+)
+
+// EmployerCanAccessApplication reports whether an employer works for the
+// company that posted the job an application was made for
+func EmployerCanAccessApplication(ctx context.Context, employerID, applicationID int) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM applications a
+			JOIN job_listings j ON a.job_listing_id = j.id
+			JOIN employers owner ON j.employer_id = owner.id
+			JOIN employers e ON e.companyid = owner.companyid
+			WHERE a.id = $1 AND e.id = $2
+		)`
+	var allowed bool
+	err := config.DB.QueryRow(ctx, query, applicationID, employerID).Scan(&allowed)
+	return allowed, err
+}
+
+// EmployerCanAccessJob reports whether an employer works for the company that posted a job
+func EmployerCanAccessJob(ctx context.Context, employerID, jobListingID int) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM job_listings j
+			JOIN employers owner ON j.employer_id = owner.id
+			JOIN employers e ON e.companyid = owner.companyid
+			WHERE j.id = $1 AND e.id = $2
+		)`
+	var allowed bool
+	err := config.DB.QueryRow(ctx, query, jobListingID, employerID).Scan(&allowed)
+	return allowed, err
+}
+
+// EmployerWorksForCompany reports whether an employer belongs to a company
+func EmployerWorksForCompany(ctx context.Context, employerID, companyID int) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM employers WHERE id = $1 AND companyid = $2)`
+	var allowed bool
+	err := config.DB.QueryRow(ctx, query, employerID, companyID).Scan(&allowed)
+	return allowed, err
+}
+
+// AddApplicationReviews fills in the hiring team's private notes and ratings
+// of applications. Only call it for employers reviewing the job.
+func AddApplicationReviews(ctx context.Context, applications []schema.ApplicationDetails) error {
+	for i := range applications {
+		notes, err := GetApplicationNotes(ctx, applications[i].ApplicationID)
+		if err != nil {
+			return err
+		}
+		rating, err := GetRatingSummary(ctx, applications[i].ApplicationID)
+		if err != nil {
+			return err
+		}
+		applications[i].Notes = notes
+		applications[i].Rating = &rating
+	}
+	return nil
+}
+
+// GetColleagueIDsByEmail resolves email addresses to the IDs of employers at
+// the same company as the given employer. Unknown emails are ignored.
+func GetColleagueIDsByEmail(ctx context.Context, employerID int, emails []string) ([]int, error) {
+	if len(emails) == 0 {
+		return nil, nil
+	}
+
+	lowered := make([]string, len(emails))
+	for i, email := range emails {
+		lowered[i] = strings.ToLower(email)
+	}
+
+	query := `
+		SELECT e.id
+		FROM employers e
+		JOIN employers me ON me.companyid = e.companyid
+		WHERE me.id = $1 AND LOWER(e.email) = ANY($2)`
+	rows, err := config.DB.Query(ctx, query, employerID, lowered)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// AddApplicationNote stores a note together with the colleagues it mentions.
+// announce, if given, builds the notifications to them, which are stored in
+// the same transaction.
+func AddApplicationNote(ctx context.Context, note schema.ApplicationNote, announce func(schema.ApplicationNote) (schema.Outbox, error)) (schema.ApplicationNote, error) {
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		return schema.ApplicationNote{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO application_notes (application_id, employer_id, note)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at`
+	err = tx.QueryRow(ctx, query, note.ApplicationID, note.EmployerID, note.Note).Scan(&note.ID, &note.CreatedAt)
+	if err != nil {
+		return schema.ApplicationNote{}, err
+	}
+
+	for _, mentionedID := range note.Mentions {
+		_, err = tx.Exec(ctx, `INSERT INTO application_note_mentions (note_id, employer_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`, note.ID, mentionedID)
+		if err != nil {
+			return schema.ApplicationNote{}, err
+		}
+	}
+	if err = announceChange(ctx, tx, announce, note); err != nil {
+		return schema.ApplicationNote{}, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return schema.ApplicationNote{}, err
+	}
+	return note, nil
+}
+
+// GetApplicationNotes returns the notes of an application, oldest first
+func GetApplicationNotes(ctx context.Context, applicationID int) ([]schema.ApplicationNote, error) {
+	query := `
+		SELECT n.id, n.application_id, n.employer_id, e.contact_person, n.note, n.created_at,
+		       COALESCE(array_agg(m.employer_id) FILTER (WHERE m.employer_id IS NOT NULL), '{}')
+		FROM application_notes n
+		JOIN employers e ON n.employer_id = e.id
+		LEFT JOIN application_note_mentions m ON m.note_id = n.id
+		WHERE n.application_id = $1
+		GROUP BY n.id, e.contact_person
+		ORDER BY n.created_at, n.id`
+
+	rows, err := config.DB.Query(ctx, query, applicationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notes := []schema.ApplicationNote{}
+	for rows.Next() {
+		var note schema.ApplicationNote
+		err := rows.Scan(&note.ID, &note.ApplicationID, &note.EmployerID, &note.AuthorName, &note.Note, &note.CreatedAt, &note.Mentions)
+		if err != nil {
+			return nil, err
+		}
+		notes = append(notes, note)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return notes, nil
+}
+
+// RateApplication sets (or replaces) a reviewer's rating of an application
+func RateApplication(ctx context.Context, applicationID, employerID, rating int) error {
+	query := `
+		INSERT INTO application_ratings (application_id, employer_id, rating)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (application_id, employer_id)
+		DO UPDATE SET rating = EXCLUDED.rating, rated_at = CURRENT_TIMESTAMP`
+	_, err := config.DB.Exec(ctx, query, applicationID, employerID, rating)
+	return err
+}
+
+// GetRatingSummary returns all ratings of an application with their average
+func GetRatingSummary(ctx context.Context, applicationID int) (schema.RatingSummary, error) {
+	query := `
+		SELECT r.application_id, r.employer_id, e.contact_person, r.rating, r.rated_at
+		FROM application_ratings r
+		JOIN employers e ON r.employer_id = e.id
+		WHERE r.application_id = $1
+		ORDER BY r.rated_at`
+
+	rows, err := config.DB.Query(ctx, query, applicationID)
+	if err != nil {
+		return schema.RatingSummary{}, err
+	}
+	defer rows.Close()
+
+	summary := schema.RatingSummary{Ratings: []schema.ApplicationRating{}}
+	total := 0
+	for rows.Next() {
+		var rating schema.ApplicationRating
+		if err := rows.Scan(&rating.ApplicationID, &rating.EmployerID, &rating.AuthorName, &rating.Rating, &rating.RatedAt); err != nil {
+			return schema.RatingSummary{}, err
+		}
+		total += rating.Rating
+		summary.Ratings = append(summary.Ratings, rating)
+	}
+	if err = rows.Err(); err != nil {
+		return schema.RatingSummary{}, err
+	}
+
+	summary.Count = len(summary.Ratings)
+	if summary.Count > 0 {
+		average := float64(total) / float64(summary.Count)
+		summary.Average = &average
+	}
+	return summary, nil
+}
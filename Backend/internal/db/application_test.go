@@ -0,0 +1,128 @@
+package db
+
+import (
+	"Backend/config"
+	"Backend/internal/dbtest"
+	"Backend/internal/schema"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// applyConcurrently runs apply from n goroutines at once and counts the
+// successes and ErrAlreadyApplied results
+func applyConcurrently(t *testing.T, n int, apply func() error) (succeeded, duplicates int) {
+	t.Helper()
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			errs <- apply()
+		}()
+	}
+	close(start)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case errors.Is(err, ErrAlreadyApplied):
+			duplicates++
+		default:
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	return succeeded, duplicates
+}
+
+func countApplications(t *testing.T, seekerID, jobID int) int {
+	t.Helper()
+	var count int
+	err := config.DB.QueryRow(context.Background(),
+		`SELECT COUNT(*) FROM applications WHERE job_seeker_id = $1 AND job_listing_id = $2`, seekerID, jobID).Scan(&count)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func TestApplyJobConcurrentDuplicates(t *testing.T) {
+	dbtest.Require(t)
+	seekerID := dbtest.JobSeeker(t)
+	jobID := dbtest.Job(t, dbtest.Employer(t, dbtest.Company(t)))
+
+	const requests = 8
+	succeeded, duplicates := applyConcurrently(t, requests, func() error {
+		_, err := ApplyJob(seekerID, jobID, "cover letter", nil, nil)
+		return err
+	})
+
+	if succeeded != 1 || duplicates != requests-1 {
+		t.Errorf("succeeded = %d, duplicates = %d; want 1 and %d", succeeded, duplicates, requests-1)
+	}
+	if count := countApplications(t, seekerID, jobID); count != 1 {
+		t.Errorf("stored applications = %d, want 1", count)
+	}
+}
+
+func TestCreateApplicationConcurrentDuplicates(t *testing.T) {
+	dbtest.Require(t)
+	seekerID := dbtest.JobSeeker(t)
+	jobID := dbtest.Job(t, dbtest.Employer(t, dbtest.Company(t)))
+
+	const requests = 8
+	succeeded, duplicates := applyConcurrently(t, requests, func() error {
+		_, err := CreateApplication(context.Background(), schema.Application{
+			JobSeekerID:       seekerID,
+			JobListingID:      jobID,
+			ApplicationStatus: "Applied",
+			AppliedDate:       time.Now(),
+		}, nil)
+		return err
+	})
+
+	if succeeded != 1 || duplicates != requests-1 {
+		t.Errorf("succeeded = %d, duplicates = %d; want 1 and %d", succeeded, duplicates, requests-1)
+	}
+	if count := countApplications(t, seekerID, jobID); count != 1 {
+		t.Errorf("stored applications = %d, want 1", count)
+	}
+}
+
+func TestApplyJobAndCreateApplicationRace(t *testing.T) {
+	dbtest.Require(t)
+	seekerID := dbtest.JobSeeker(t)
+	jobID := dbtest.Job(t, dbtest.Employer(t, dbtest.Company(t)))
+
+	var mu sync.Mutex
+	turn := 0
+	succeeded, _ := applyConcurrently(t, 8, func() error {
+		mu.Lock()
+		turn++
+		viaApply := turn%2 == 0
+		mu.Unlock()
+		if viaApply {
+			_, err := ApplyJob(seekerID, jobID, "", nil, nil)
+			return err
+		}
+		_, err := CreateApplication(context.Background(), schema.Application{
+			JobSeekerID: seekerID, JobListingID: jobID, ApplicationStatus: "Applied", AppliedDate: time.Now(),
+		}, nil)
+		return err
+	})
+
+	if succeeded != 1 {
+		t.Errorf("succeeded = %d, want 1", succeeded)
+	}
+	if count := countApplications(t, seekerID, jobID); count != 1 {
+		t.Errorf("stored applications = %d, want 1", count)
+	}
+}
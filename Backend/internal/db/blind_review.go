@@ -0,0 +1,193 @@
+package db
+
+import (
+	"Backend/config"
+	"Backend/internal/schema"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// GetBlindReviewSettings returns a job's blind review settings. Jobs without
+// stored settings are not blind reviewed.
+func GetBlindReviewSettings(ctx context.Context, jobListingID int) (schema.BlindReviewSettings, error) {
+	return getBlindReviewSettings(ctx, config.DB, jobListingID)
+}
+
+func getBlindReviewSettings(ctx context.Context, q querier, jobListingID int) (schema.BlindReviewSettings, error) {
+	settings := schema.BlindReviewSettings{JobListingID: jobListingID}
+	query := `SELECT blind_review, reveal_stage_id FROM job_listing_settings WHERE job_listing_id = $1`
+	err := q.QueryRow(ctx, query, jobListingID).Scan(&settings.BlindReview, &settings.RevealStageID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return settings, nil
+	}
+	return settings, err
+}
+
+// SetBlindReviewSettings stores a job's blind review settings. The reveal
+// stage must belong to the job's pipeline. Applications already at or past
+// the reveal stage are revealed immediately, with the change as the trigger.
+func SetBlindReviewSettings(ctx context.Context, settings schema.BlindReviewSettings, actor schema.Actor) error {
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var revealPosition int
+	if settings.RevealStageID != nil {
+		stages, err := getPipelineStages(ctx, tx, settings.JobListingID)
+		if err != nil {
+			return err
+		}
+		found := false
+		for _, stage := range stages {
+			if stage.ID == *settings.RevealStageID {
+				revealPosition, found = stage.Position, true
+				break
+			}
+		}
+		if !found {
+			return ErrStageNotInJob
+		}
+	}
+
+	query := `
+		INSERT INTO job_listing_settings (job_listing_id, blind_review, reveal_stage_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (job_listing_id)
+		DO UPDATE SET blind_review = EXCLUDED.blind_review, reveal_stage_id = EXCLUDED.reveal_stage_id`
+	_, err = tx.Exec(ctx, query, settings.JobListingID, settings.BlindReview, settings.RevealStageID)
+	if err != nil {
+		return err
+	}
+
+	if settings.BlindReview && settings.RevealStageID != nil {
+		revealQuery := `
+			INSERT INTO application_identity_reveals (application_id, stage_id, trigger, actor_type, actor_id)
+			SELECT a.id, a.stage_id, 'settings', $3, $4
+			FROM applications a
+			JOIN pipeline_stages ps ON a.stage_id = ps.id
+			WHERE a.job_listing_id = $1 AND ps.position >= $2
+			ON CONFLICT (application_id) DO NOTHING`
+		_, err = tx.Exec(ctx, revealQuery, settings.JobListingID, revealPosition, actor.Type, actor.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// revealIdentityIfDue records the reveal of a blind-reviewed candidate once
+// the application reaches the job's reveal stage or is accepted. Reveals are
+// recorded once; later calls for the same application do nothing.
+func revealIdentityIfDue(ctx context.Context, q querier, application schema.Application, actor schema.Actor) error {
+	settings, err := getBlindReviewSettings(ctx, q, application.JobListingID)
+	if err != nil || !settings.BlindReview {
+		return err
+	}
+
+	trigger := ""
+	if application.ApplicationStatus == "Accepted" {
+		trigger = "accepted"
+	} else if settings.RevealStageID != nil && application.StageID != nil {
+		stages, err := getPipelineStages(ctx, q, application.JobListingID)
+		if err != nil {
+			return err
+		}
+		positions := make(map[int]int, len(stages))
+		for _, stage := range stages {
+			positions[stage.ID] = stage.Position
+		}
+		current, inPipeline := positions[*application.StageID]
+		reveal, revealInPipeline := positions[*settings.RevealStageID]
+		if inPipeline && revealInPipeline && current >= reveal {
+			trigger = "stage"
+		}
+	}
+	if trigger == "" {
+		return nil
+	}
+
+	query := `
+		INSERT INTO application_identity_reveals (application_id, stage_id, trigger, actor_type, actor_id)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (application_id) DO NOTHING`
+	_, err = q.Exec(ctx, query, application.ID, application.StageID, trigger, actor.Type, actor.ID)
+	return err
+}
+
+// GetIdentityReveals returns the reveal audit trail of a job's applications
+func GetIdentityReveals(ctx context.Context, jobListingID int) ([]schema.IdentityReveal, error) {
+	query := `
+		SELECT r.id, r.application_id, r.stage_id, r.trigger, r.actor_type, r.actor_id, r.revealed_at
+		FROM application_identity_reveals r
+		JOIN applications a ON r.application_id = a.id
+		WHERE a.job_listing_id = $1
+		ORDER BY r.revealed_at, r.id`
+
+	rows, err := config.DB.Query(ctx, query, jobListingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reveals := []schema.IdentityReveal{}
+	for rows.Next() {
+		var reveal schema.IdentityReveal
+		err := rows.Scan(&reveal.ID, &reveal.ApplicationID, &reveal.StageID, &reveal.Trigger,
+			&reveal.ActorType, &reveal.ActorID, &reveal.RevealedAt)
+		if err != nil {
+			return nil, err
+		}
+		reveals = append(reveals, reveal)
+	}
+	return reveals, rows.Err()
+}
+
+// getRevealedApplicationIDs returns the applications of a job whose candidate
+// identity has been revealed
+func getRevealedApplicationIDs(ctx context.Context, jobListingID int) (map[int]bool, error) {
+	query := `
+		SELECT r.application_id
+		FROM application_identity_reveals r
+		JOIN applications a ON r.application_id = a.id
+		WHERE a.job_listing_id = $1`
+
+	rows, err := config.DB.Query(ctx, query, jobListingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revealed := make(map[int]bool)
+	for rows.Next() {
+		var applicationID int
+		if err := rows.Scan(&applicationID); err != nil {
+			return nil, err
+		}
+		revealed[applicationID] = true
+	}
+	return revealed, rows.Err()
+}
+
+// redactIdentity hides everything that identifies a candidate, including the
+// resume file (which carries their name) and the institutions they attended
+func redactIdentity(application *schema.ApplicationDetails) {
+	application.CandidateAlias = fmt.Sprintf("Candidate #%d", application.ApplicationID)
+	application.IdentityHidden = true
+	application.FirstName = application.CandidateAlias
+	application.LastName = ""
+	application.Email = ""
+	application.PhoneNumber = ""
+	application.ProfilePicture = nil
+	application.LinkedinURL = nil
+	application.Resume = nil
+	application.ResumeID = nil
+	for i := range application.Education {
+		application.Education[i].Institution = "Redacted"
+	}
+}
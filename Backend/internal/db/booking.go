@@ -0,0 +1,320 @@
+package db
+
+import (
+	"Backend/config"
+	"Backend/internal/helpers"
+	"Backend/internal/schema"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrAvailabilityNotFound = errors.New("availability window not found")
+	ErrBookingLinkNotFound  = errors.New("booking link not found")
+	ErrBookingLinkExpired   = errors.New("booking link has expired")
+	ErrBookingLinkUsed      = errors.New("booking link has already been used")
+	ErrSlotUnavailable      = errors.New("this slot is no longer available")
+	ErrApplicationClosed    = errors.New("the application is no longer open for interviews")
+)
+
+// AddAvailability stores availability windows of a team member
+func AddAvailability(ctx context.Context, employerID int, windows []schema.AvailabilityWindow) ([]schema.AvailabilityWindow, error) {
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO interviewer_availability (employer_id, starts_at, ends_at)
+		VALUES ($1, $2, $3)
+		RETURNING id`
+	stored := make([]schema.AvailabilityWindow, 0, len(windows))
+	for _, window := range windows {
+		window.EmployerID = employerID
+		if err := tx.QueryRow(ctx, query, employerID, window.StartsAt, window.EndsAt).Scan(&window.ID); err != nil {
+			return nil, err
+		}
+		stored = append(stored, window)
+	}
+
+	return stored, tx.Commit(ctx)
+}
+
+// GetAvailability returns a team member's availability windows that end
+// after from and start before to, earliest first
+func GetAvailability(ctx context.Context, employerID int, from, to time.Time) ([]schema.AvailabilityWindow, error) {
+	return getAvailability(ctx, config.DB, employerID, from, to)
+}
+
+func getAvailability(ctx context.Context, q querier, employerID int, from, to time.Time) ([]schema.AvailabilityWindow, error) {
+	query := `
+		SELECT id, employer_id, starts_at, ends_at
+		FROM interviewer_availability
+		WHERE employer_id = $1 AND ends_at > $2 AND starts_at < $3
+		ORDER BY starts_at`
+	rows, err := q.Query(ctx, query, employerID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	windows := []schema.AvailabilityWindow{}
+	for rows.Next() {
+		var window schema.AvailabilityWindow
+		if err := rows.Scan(&window.ID, &window.EmployerID, &window.StartsAt, &window.EndsAt); err != nil {
+			return nil, err
+		}
+		windows = append(windows, window)
+	}
+	return windows, rows.Err()
+}
+
+// DeleteAvailability removes one of a team member's availability windows.
+// Interviews already booked in it are kept.
+func DeleteAvailability(ctx context.Context, windowID, employerID int) error {
+	tag, err := config.DB.Exec(ctx, `DELETE FROM interviewer_availability WHERE id = $1 AND employer_id = $2`, windowID, employerID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrAvailabilityNotFound
+	}
+	return nil
+}
+
+// getBusyPeriods returns the scheduled interviews a team member sits on between from and to
+func getBusyPeriods(ctx context.Context, q querier, employerID int, from, to time.Time) ([]schema.TimeSlot, error) {
+	query := `
+		SELECT i.scheduled_date, i.scheduled_date + make_interval(mins => i.duration_minutes)
+		FROM interviews i
+		JOIN interview_interviewers p ON p.interview_id = i.id
+		WHERE p.employer_id = $1 AND i.status = 'Scheduled'
+		  AND i.scheduled_date < $3 AND i.scheduled_date + make_interval(mins => i.duration_minutes) > $2`
+	rows, err := q.Query(ctx, query, employerID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var busy []schema.TimeSlot
+	for rows.Next() {
+		var period schema.TimeSlot
+		if err := rows.Scan(&period.StartsAt, &period.EndsAt); err != nil {
+			return nil, err
+		}
+		busy = append(busy, period)
+	}
+	return busy, rows.Err()
+}
+
+// CreateBookingLink stores a booking link for an application. announce, if
+// given, builds the notifications sending it to the candidate, which are
+// stored in the same transaction.
+func CreateBookingLink(ctx context.Context, link schema.BookingLink, announce func(schema.BookingLink) (schema.Outbox, error)) (schema.BookingLink, error) {
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		return link, err
+	}
+	defer tx.Rollback(ctx)
+
+	if link.RoundID != nil {
+		var inPlan bool
+		query := `
+			SELECT EXISTS (
+				SELECT 1 FROM interview_plan_rounds r
+				JOIN applications a ON a.job_listing_id = r.job_listing_id
+				WHERE r.id = $1 AND a.id = $2
+			)`
+		if err := tx.QueryRow(ctx, query, *link.RoundID, link.ApplicationID).Scan(&inPlan); err != nil {
+			return link, err
+		}
+		if !inPlan {
+			return link, ErrRoundNotInJob
+		}
+	}
+
+	query := `
+		INSERT INTO interview_booking_links (token, application_id, round_id, interviewer_id, created_by,
+			duration_minutes, interview_mode, interview_link, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id`
+	err = tx.QueryRow(ctx, query, link.Token, link.ApplicationID, link.RoundID, link.InterviewerID, link.CreatedBy,
+		link.DurationMinutes, link.InterviewMode, link.InterviewLink, link.ExpiresAt).Scan(&link.ID)
+	if err != nil {
+		return link, err
+	}
+
+	stored, err := getBookingLink(ctx, tx, link.Token, false)
+	if err != nil {
+		return link, err
+	}
+	stored.Token = link.Token
+	if err = announceChange(ctx, tx, announce, stored); err != nil {
+		return link, err
+	}
+	return stored, tx.Commit(ctx)
+}
+
+// GetBookingLink returns a booking link by its token
+func GetBookingLink(ctx context.Context, token string) (schema.BookingLink, error) {
+	return getBookingLink(ctx, config.DB, token, false)
+}
+
+func getBookingLink(ctx context.Context, q querier, token string, forUpdate bool) (schema.BookingLink, error) {
+	query := `
+		SELECT l.id, l.application_id, l.round_id, l.interviewer_id, l.created_by, l.duration_minutes,
+		       l.interview_mode, l.interview_link, l.expires_at, l.used_at, l.interview_id,
+		       a.job_seeker_id, j.job_title, r.name
+		FROM interview_booking_links l
+		JOIN applications a ON l.application_id = a.id
+		JOIN job_listings j ON a.job_listing_id = j.id
+		LEFT JOIN interview_plan_rounds r ON l.round_id = r.id
+		WHERE l.token = $1`
+	if forUpdate {
+		query += ` FOR UPDATE OF l`
+	}
+
+	var link schema.BookingLink
+	err := q.QueryRow(ctx, query, token).Scan(&link.ID, &link.ApplicationID, &link.RoundID, &link.InterviewerID,
+		&link.CreatedBy, &link.DurationMinutes, &link.InterviewMode, &link.InterviewLink, &link.ExpiresAt,
+		&link.UsedAt, &link.InterviewID, &link.JobSeekerID, &link.JobTitle, &link.RoundName)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return link, ErrBookingLinkNotFound
+	}
+	return link, err
+}
+
+// checkBookingLink reports why a link can no longer be used, if it can't
+func checkBookingLink(link schema.BookingLink, now time.Time) error {
+	if link.UsedAt != nil {
+		return ErrBookingLinkUsed
+	}
+	if !link.ExpiresAt.After(now) {
+		return ErrBookingLinkExpired
+	}
+	return nil
+}
+
+// GetBookingSlots returns the slots a booking link can still book: the
+// interviewer's availability between now and the link's expiry, split into
+// slots of the link's duration, without times the interviewer is busy
+func GetBookingSlots(ctx context.Context, token string, now time.Time) (schema.BookingLink, []schema.TimeSlot, error) {
+	link, err := GetBookingLink(ctx, token)
+	if err != nil {
+		return link, nil, err
+	}
+	if err := checkBookingLink(link, now); err != nil {
+		return link, nil, err
+	}
+
+	windows, err := getAvailability(ctx, config.DB, link.InterviewerID, now, link.ExpiresAt)
+	if err != nil {
+		return link, nil, err
+	}
+	busy, err := getBusyPeriods(ctx, config.DB, link.InterviewerID, now, link.ExpiresAt)
+	if err != nil {
+		return link, nil, err
+	}
+
+	duration := time.Duration(link.DurationMinutes) * time.Minute
+	return link, helpers.GenerateSlots(windows, busy, duration, now, link.ExpiresAt), nil
+}
+
+// BookSlot schedules the interview of a booking link at the chosen start.
+// The interviewer's row is locked for the check and insert, so two
+// candidates cannot book overlapping slots with the same interviewer. The
+// application is locked too and must still be Applied or Interview
+// Scheduled; links of withdrawn or decided applications cannot be used.
+// announce, if given, builds the notifications about the booking, which are
+// stored in the same transaction.
+func BookSlot(ctx context.Context, token string, startsAt, now time.Time, announce func(schema.Interview) (schema.Outbox, error)) (schema.Interview, error) {
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		return schema.Interview{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	// The application is locked before the link, in the order the interview
+	// workflow locks them
+	link, err := getBookingLink(ctx, tx, token, false)
+	if err != nil {
+		return schema.Interview{}, err
+	}
+	status, err := lockApplication(ctx, tx, link.ApplicationID)
+	if err != nil {
+		return schema.Interview{}, err
+	}
+	if status != "Applied" && status != "Interview Scheduled" {
+		return schema.Interview{}, ErrApplicationClosed
+	}
+	link, err = getBookingLink(ctx, tx, token, true)
+	if err != nil {
+		return schema.Interview{}, err
+	}
+	if err := checkBookingLink(link, now); err != nil {
+		return schema.Interview{}, err
+	}
+
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM employers WHERE id = $1 FOR UPDATE`, link.InterviewerID); err != nil {
+		return schema.Interview{}, err
+	}
+
+	slot := schema.TimeSlot{StartsAt: startsAt, EndsAt: startsAt.Add(time.Duration(link.DurationMinutes) * time.Minute)}
+	if slot.StartsAt.Before(now) || slot.EndsAt.After(link.ExpiresAt) {
+		return schema.Interview{}, ErrSlotUnavailable
+	}
+	windows, err := getAvailability(ctx, tx, link.InterviewerID, slot.StartsAt, slot.EndsAt)
+	if err != nil {
+		return schema.Interview{}, err
+	}
+	if !helpers.SlotInWindows(slot, windows) {
+		return schema.Interview{}, ErrSlotUnavailable
+	}
+	busy, err := getBusyPeriods(ctx, tx, link.InterviewerID, slot.StartsAt, slot.EndsAt)
+	if err != nil {
+		return schema.Interview{}, err
+	}
+	if len(busy) > 0 {
+		return schema.Interview{}, ErrSlotUnavailable
+	}
+
+	// The interview keeps the candidate's zone, as the candidate picked the time
+	var interviewerName, timeZone string
+	query := `
+		SELECT e.contact_person, s.time_zone
+		FROM employers e, job_seekers s
+		WHERE e.id = $1 AND s.id = $2`
+	err = tx.QueryRow(ctx, query, link.InterviewerID, link.JobSeekerID).Scan(&interviewerName, &timeZone)
+	if err != nil {
+		return schema.Interview{}, err
+	}
+
+	interview, err := scheduleInterview(ctx, tx, schema.Interview{
+		ApplicationID:   link.ApplicationID,
+		ScheduledDate:   slot.StartsAt,
+		TimeZone:        timeZone,
+		InterviewMode:   link.InterviewMode,
+		InterviewerName: &interviewerName,
+		InterviewLink:   link.InterviewLink,
+		RoundID:         link.RoundID,
+		InterviewerID:   &link.InterviewerID,
+		DurationMinutes: link.DurationMinutes,
+	}, schema.Actor{Type: "job_seeker", ID: &link.JobSeekerID}, false)
+	if err != nil {
+		return schema.Interview{}, err
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE interview_booking_links SET used_at = NOW(), interview_id = $1 WHERE id = $2`, interview.ID, link.ID)
+	if err != nil {
+		return schema.Interview{}, err
+	}
+	if err = announceChange(ctx, tx, announce, interview); err != nil {
+		return schema.Interview{}, err
+	}
+
+	return interview, tx.Commit(ctx)
+}
@@ -0,0 +1,142 @@
+package db
+
+import (
+	"Backend/config"
+	"Backend/internal/dbtest"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// bookingFixture is an interviewer with two hours of availability tomorrow
+// and a job they interview for
+type bookingFixture struct {
+	interviewerID int
+	jobID         int
+	windowStart   time.Time
+	expiresAt     time.Time
+}
+
+func newBookingFixture(t *testing.T, now time.Time) bookingFixture {
+	t.Helper()
+	interviewerID := dbtest.Employer(t, dbtest.Company(t))
+	fixture := bookingFixture{
+		interviewerID: interviewerID,
+		jobID:         dbtest.Job(t, interviewerID),
+		windowStart:   now.Truncate(time.Hour).Add(24 * time.Hour).UTC(),
+		expiresAt:     now.Add(72 * time.Hour),
+	}
+	dbtest.Availability(t, interviewerID, fixture.windowStart, fixture.windowStart.Add(2*time.Hour))
+	return fixture
+}
+
+// link adds an application from a new candidate and a booking link for it
+func (f bookingFixture) link(t *testing.T, status string) (applicationID int, token string) {
+	t.Helper()
+	applicationID = dbtest.Application(t, dbtest.JobSeeker(t), f.jobID, status)
+	return applicationID, dbtest.BookingLink(t, applicationID, f.interviewerID, f.expiresAt)
+}
+
+func TestBookSlotPreventsDoubleBooking(t *testing.T) {
+	dbtest.Require(t)
+	now := time.Now()
+	fixture := newBookingFixture(t, now)
+
+	const candidates = 6
+	tokens := make([]string, candidates)
+	for i := range tokens {
+		_, tokens[i] = fixture.link(t, "Applied")
+	}
+
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	errs := make(chan error, candidates)
+	for _, token := range tokens {
+		wg.Add(1)
+		go func(token string) {
+			defer wg.Done()
+			<-start
+			_, err := BookSlot(context.Background(), token, fixture.windowStart, now, nil)
+			errs <- err
+		}(token)
+	}
+	close(start)
+	wg.Wait()
+	close(errs)
+
+	booked := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			booked++
+		case errors.Is(err, ErrSlotUnavailable):
+		default:
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if booked != 1 {
+		t.Fatalf("slot booked %d times, want once", booked)
+	}
+
+	var scheduled int
+	err := config.DB.QueryRow(context.Background(), `
+		SELECT COUNT(*) FROM interviews i
+		JOIN interview_interviewers p ON p.interview_id = i.id
+		WHERE p.employer_id = $1 AND i.status = 'Scheduled'`, fixture.interviewerID).Scan(&scheduled)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scheduled != 1 {
+		t.Errorf("scheduled interviews = %d, want 1", scheduled)
+	}
+}
+
+func TestBookSlotOverlappingSlot(t *testing.T) {
+	dbtest.Require(t)
+	now := time.Now()
+	fixture := newBookingFixture(t, now)
+	_, first := fixture.link(t, "Applied")
+	_, second := fixture.link(t, "Applied")
+
+	if _, err := BookSlot(context.Background(), first, fixture.windowStart, now, nil); err != nil {
+		t.Fatal(err)
+	}
+	// Half an hour later still overlaps the first hour-long interview
+	if _, err := BookSlot(context.Background(), second, fixture.windowStart.Add(30*time.Minute), now, nil); !errors.Is(err, ErrSlotUnavailable) {
+		t.Errorf("overlapping slot: err = %v, want %v", err, ErrSlotUnavailable)
+	}
+	// The next hour is free
+	if _, err := BookSlot(context.Background(), second, fixture.windowStart.Add(time.Hour), now, nil); err != nil {
+		t.Errorf("adjacent slot: err = %v, want it booked", err)
+	}
+	// A link books one interview only
+	if _, err := BookSlot(context.Background(), first, fixture.windowStart.Add(time.Hour), now, nil); !errors.Is(err, ErrBookingLinkUsed) {
+		t.Errorf("reused link: err = %v, want %v", err, ErrBookingLinkUsed)
+	}
+}
+
+func TestBookSlotRequiresOpenApplication(t *testing.T) {
+	dbtest.Require(t)
+	now := time.Now()
+	fixture := newBookingFixture(t, now)
+
+	for _, status := range []string{"Withdrawn", "Rejected", "Accepted"} {
+		t.Run(status, func(t *testing.T) {
+			_, token := fixture.link(t, status)
+			if _, err := BookSlot(context.Background(), token, fixture.windowStart, now, nil); !errors.Is(err, ErrApplicationClosed) {
+				t.Errorf("err = %v, want %v", err, ErrApplicationClosed)
+			}
+		})
+	}
+
+	applicationID, token := fixture.link(t, "Interview Scheduled")
+	interview, err := BookSlot(context.Background(), token, fixture.windowStart, now, nil)
+	if err != nil {
+		t.Fatalf("Interview Scheduled application: err = %v, want it booked", err)
+	}
+	if interview.ApplicationID != applicationID {
+		t.Errorf("interview application = %d, want %d", interview.ApplicationID, applicationID)
+	}
+}
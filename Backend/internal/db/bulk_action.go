@@ -0,0 +1,168 @@
+package db
+
+import (
+	"Backend/config"
+	"Backend/internal/schema"
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrApplicationNotFound = errors.New("application not found")
+	ErrApplicationNotInJob = errors.New("application does not belong to this job")
+)
+
+// BulkApplicationAction applies one action to many applications of a job in a
+// single transaction. Each application runs in its own savepoint so that one
+// invalid application is reported as failed without undoing the others.
+// announce builds the notifications about each application whose
+// seeker-facing status changed, given the interviews the change cancelled;
+// they are stored in the application's savepoint, so an application whose
+// notifications fail is reported as failed too.
+func BulkApplicationAction(ctx context.Context, request schema.BulkActionRequest,
+	announce func(application schema.Application, cancelled []schema.Interview) (schema.Outbox, error)) (schema.BulkActionResult, error) {
+	result := schema.BulkActionResult{Succeeded: []int{}, Failed: []schema.BulkFailure{}}
+
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback(ctx)
+
+	actor := schema.Actor{Type: "employer", ID: &request.EmployerID}
+	for _, applicationID := range request.ApplicationIDs {
+		savepoint, err := tx.Begin(ctx)
+		if err != nil {
+			return result, err
+		}
+
+		if err := applyBulkAction(ctx, savepoint, request, applicationID, actor, announce); err != nil {
+			savepoint.Rollback(ctx)
+			result.Failed = append(result.Failed, schema.BulkFailure{ApplicationID: applicationID, Error: err.Error()})
+			continue
+		}
+		if err := savepoint.Commit(ctx); err != nil {
+			return result, err
+		}
+		result.Succeeded = append(result.Succeeded, applicationID)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// applyBulkAction applies the action to one application and announces a
+// change of its status
+func applyBulkAction(ctx context.Context, tx pgx.Tx, request schema.BulkActionRequest, applicationID int, actor schema.Actor,
+	announce func(application schema.Application, cancelled []schema.Interview) (schema.Outbox, error)) error {
+	var jobListingID int
+	var status string
+	err := tx.QueryRow(ctx, `SELECT job_listing_id, application_status FROM applications WHERE id = $1 FOR UPDATE`, applicationID).Scan(&jobListingID, &status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrApplicationNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if jobListingID != request.JobListingID {
+		return ErrApplicationNotInJob
+	}
+
+	var updated schema.Application
+	var cancelled []schema.Interview
+	switch request.Action {
+	case "move":
+		updated, err = moveApplicationStage(ctx, tx, applicationID, request.StageID, actor, request.Reason)
+		if err != nil {
+			return err
+		}
+		if updated.ApplicationStatus == status {
+			return nil
+		}
+
+	case "reject":
+		if status == "Rejected" || status == "Accepted" {
+			return ErrApplicationFinal
+		}
+		updated, cancelled, err = updateApplicationStatus(ctx, tx, applicationID, "Rejected", actor, request.Reason, request.ReasonCode)
+		if err != nil {
+			return err
+		}
+
+	case "tag":
+		for _, tag := range request.Tags {
+			_, err := tx.Exec(ctx, `INSERT INTO application_tags (application_id, tag) VALUES ($1, $2) ON CONFLICT DO NOTHING`, applicationID, strings.TrimSpace(tag))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+
+	default:
+		return errors.New("unknown action: " + request.Action)
+	}
+
+	if announce == nil {
+		return nil
+	}
+	outbox, err := announce(updated, cancelled)
+	if err != nil {
+		return err
+	}
+	return storeOutbox(ctx, tx, outbox)
+}
+
+// GetApplicationTags returns the tags set on an application
+func GetApplicationTags(ctx context.Context, applicationID int) ([]string, error) {
+	rows, err := config.DB.Query(ctx, `SELECT tag FROM application_tags WHERE application_id = $1 ORDER BY tag`, applicationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []string{}
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
+
+// GetApplicationContacts returns candidate and job details used to address
+// messages about the given applications, keyed by application ID
+func GetApplicationContacts(ctx context.Context, applicationIDs []int) (map[int]schema.ApplicationContact, error) {
+	query := `
+		SELECT a.id, js.id, js.first_name, js.last_name, js.email, j.job_title, c.company_name
+		FROM applications a
+		JOIN job_seekers js ON a.job_seeker_id = js.id
+		JOIN job_listings j ON a.job_listing_id = j.id
+		JOIN employers e ON j.employer_id = e.id
+		JOIN company c ON e.companyid = c.id
+		WHERE a.id = ANY($1)`
+
+	rows, err := config.DB.Query(ctx, query, applicationIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	contacts := make(map[int]schema.ApplicationContact)
+	for rows.Next() {
+		var contact schema.ApplicationContact
+		err := rows.Scan(&contact.ApplicationID, &contact.JobSeekerID, &contact.FirstName, &contact.LastName,
+			&contact.Email, &contact.JobTitle, &contact.CompanyName)
+		if err != nil {
+			return nil, err
+		}
+		contacts[contact.ApplicationID] = contact
+	}
+	return contacts, rows.Err()
+}
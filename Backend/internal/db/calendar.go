@@ -0,0 +1,46 @@
+package db
+
+import (
+	"Backend/config"
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrCalendarFeedNotFound = errors.New("calendar feed not found")
+
+// GetCalendarFeedToken returns a user's calendar feed token, or
+// ErrCalendarFeedNotFound if the user has not created one yet
+func GetCalendarFeedToken(ctx context.Context, userType string, userID int) (string, error) {
+	var token string
+	err := config.DB.QueryRow(ctx, `SELECT token FROM calendar_feed_tokens WHERE user_id = $1 AND user_type = $2`, userID, userType).Scan(&token)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrCalendarFeedNotFound
+	}
+	return token, err
+}
+
+// RotateCalendarFeedToken creates a user's calendar feed token or replaces
+// the existing one, so the old subscription URL stops working
+func RotateCalendarFeedToken(ctx context.Context, userType string, userID int, newToken string) (string, error) {
+	query := `
+		INSERT INTO calendar_feed_tokens (user_id, user_type, token)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, user_type) DO UPDATE SET token = EXCLUDED.token, created_at = NOW()
+		RETURNING token`
+	var token string
+	err := config.DB.QueryRow(ctx, query, userID, userType, newToken).Scan(&token)
+	return token, err
+}
+
+// GetCalendarFeedOwner returns the user a calendar feed token belongs to
+func GetCalendarFeedOwner(ctx context.Context, token string) (string, int, error) {
+	var userType string
+	var userID int
+	err := config.DB.QueryRow(ctx, `SELECT user_type, user_id FROM calendar_feed_tokens WHERE token = $1`, token).Scan(&userType, &userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", 0, ErrCalendarFeedNotFound
+	}
+	return userType, userID, err
+}
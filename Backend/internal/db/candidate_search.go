@@ -0,0 +1,281 @@
+package db
+
+import (
+	"Backend/config"
+	"Backend/internal/schema"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrCandidateNotDiscoverable = errors.New("job seeker is not open to invitations")
+	ErrAlreadyInvited           = errors.New("job seeker has already been invited to this job")
+	ErrJobNotOpen               = errors.New("job listing is not open")
+	ErrSavedSearchNotFound      = errors.New("saved search not found")
+	ErrJobSeekerNotFound        = errors.New("job seeker not found")
+)
+
+// experienceYearsCTE computes each seeker's total experience in years.
+// Overlapping roles are merged with range_agg (PostgreSQL 14+) so they are
+// counted once; ongoing roles run until today.
+const experienceYearsCTE = `
+	WITH experience_years AS (
+		SELECT job_seeker_id, SUM(upper(period) - lower(period)) / 365.25 AS years
+		FROM (
+			SELECT job_seeker_id, unnest(range_agg(daterange(start_date, COALESCE(end_date, CURRENT_DATE)))) AS period
+			FROM experience
+			WHERE COALESCE(end_date, CURRENT_DATE) >= start_date
+			GROUP BY job_seeker_id
+		) merged
+		GROUP BY job_seeker_id
+	)`
+
+// SearchCandidates returns one page of discoverable job seekers matching the
+// criteria, most experienced first
+func SearchCandidates(ctx context.Context, criteria schema.CandidateSearchCriteria, page, pageSize int) (schema.CandidateSearchResult, error) {
+	result := schema.CandidateSearchResult{Candidates: []schema.CandidateSummary{}, Page: page, PageSize: pageSize}
+
+	conditions := []string{"js.discoverable"}
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	for _, skill := range criteria.Skills {
+		condition := "EXISTS (SELECT 1 FROM job_seeker_skills s WHERE s.job_seeker_id = js.id AND LOWER(s.skill_name) = LOWER(" + arg(strings.TrimSpace(skill)) + ")"
+		if criteria.MinSkillLevel != "" {
+			condition += " AND array_position(ARRAY['Beginner', 'Intermediate', 'Advanced', 'Expert']::VARCHAR[], s.skill_level) >= " +
+				"array_position(ARRAY['Beginner', 'Intermediate', 'Advanced', 'Expert']::VARCHAR[], " + arg(criteria.MinSkillLevel) + "::VARCHAR)"
+		}
+		conditions = append(conditions, condition+")")
+	}
+	if criteria.Location != "" {
+		conditions = append(conditions, "js.location ILIKE "+arg(likePattern(criteria.Location)))
+	}
+	if criteria.EducationLevel != "" || criteria.FieldOfStudy != "" {
+		// Both must hold for the same education entry
+		condition := "EXISTS (SELECT 1 FROM education e WHERE e.job_seeker_id = js.id"
+		if criteria.EducationLevel != "" {
+			condition += " AND e.education_level ILIKE " + arg(likePattern(criteria.EducationLevel))
+		}
+		if criteria.FieldOfStudy != "" {
+			condition += " AND e.field_of_study ILIKE " + arg(likePattern(criteria.FieldOfStudy))
+		}
+		conditions = append(conditions, condition+")")
+	}
+	if criteria.MinExperienceYears > 0 {
+		conditions = append(conditions, "COALESCE(ey.years, 0) >= "+arg(criteria.MinExperienceYears))
+	}
+	if criteria.MaxExperienceYears != nil {
+		conditions = append(conditions, "COALESCE(ey.years, 0) <= "+arg(*criteria.MaxExperienceYears))
+	}
+
+	query := experienceYearsCTE + `
+		SELECT js.id, js.first_name, js.last_name, js.location, COALESCE(ey.years, 0)::FLOAT8, COUNT(*) OVER ()
+		FROM job_seekers js
+		LEFT JOIN experience_years ey ON ey.job_seeker_id = js.id
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY COALESCE(ey.years, 0) DESC, js.id
+		LIMIT ` + arg(pageSize) + ` OFFSET ` + arg((page-1)*pageSize)
+
+	rows, err := config.DB.Query(ctx, query, args...)
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	index := make(map[int]int)
+	for rows.Next() {
+		var candidate schema.CandidateSummary
+		err := rows.Scan(&candidate.JobSeekerID, &candidate.FirstName, &candidate.LastName,
+			&candidate.Location, &candidate.ExperienceYears, &result.Total)
+		if err != nil {
+			return result, err
+		}
+		candidate.Skills = make(map[string]string)
+		candidate.Education = []schema.EducationDetails{}
+		index[candidate.JobSeekerID] = len(result.Candidates)
+		ids = append(ids, candidate.JobSeekerID)
+		result.Candidates = append(result.Candidates, candidate)
+	}
+	if err = rows.Err(); err != nil {
+		return result, err
+	}
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	// Attach skills and education of the candidates on this page
+	skillRows, err := config.DB.Query(ctx, `SELECT job_seeker_id, skill_name, skill_level FROM job_seeker_skills WHERE job_seeker_id = ANY($1)`, ids)
+	if err != nil {
+		return result, err
+	}
+	defer skillRows.Close()
+	for skillRows.Next() {
+		var seekerID int
+		var skill, level string
+		if err := skillRows.Scan(&seekerID, &skill, &level); err != nil {
+			return result, err
+		}
+		result.Candidates[index[seekerID]].Skills[skill] = level
+	}
+	if err = skillRows.Err(); err != nil {
+		return result, err
+	}
+
+	educationQuery := `
+		SELECT job_seeker_id, education_level, institution_name, field_of_study, start_year, end_year, grade
+		FROM education WHERE job_seeker_id = ANY($1)
+		ORDER BY end_year DESC`
+	eduRows, err := config.DB.Query(ctx, educationQuery, ids)
+	if err != nil {
+		return result, err
+	}
+	defer eduRows.Close()
+	for eduRows.Next() {
+		var seekerID int
+		var edu schema.EducationDetails
+		if err := eduRows.Scan(&seekerID, &edu.Level, &edu.Institution, &edu.FieldOfStudy, &edu.StartYear, &edu.EndYear, &edu.Grade); err != nil {
+			return result, err
+		}
+		candidate := &result.Candidates[index[seekerID]]
+		candidate.Education = append(candidate.Education, edu)
+	}
+
+	return result, eduRows.Err()
+}
+
+// likePattern wraps user input for a substring ILIKE match, escaping wildcards
+func likePattern(value string) string {
+	value = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(strings.TrimSpace(value))
+	return "%" + value + "%"
+}
+
+// SetDiscoverable opts a job seeker in to or out of candidate search
+func SetDiscoverable(ctx context.Context, jobSeekerID int, discoverable bool) error {
+	tag, err := config.DB.Exec(ctx, `UPDATE job_seekers SET discoverable = $1 WHERE id = $2`, discoverable, jobSeekerID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrJobSeekerNotFound
+	}
+	return nil
+}
+
+// SaveCandidateSearch stores a named search for an employer
+func SaveCandidateSearch(ctx context.Context, search schema.SavedCandidateSearch) (schema.SavedCandidateSearch, error) {
+	query := `
+		INSERT INTO saved_candidate_searches (employer_id, name, criteria)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at`
+	err := config.DB.QueryRow(ctx, query, search.EmployerID, search.Name, search.Criteria).Scan(&search.ID, &search.CreatedAt)
+	return search, err
+}
+
+// GetSavedCandidateSearches returns an employer's saved searches, newest first
+func GetSavedCandidateSearches(ctx context.Context, employerID int) ([]schema.SavedCandidateSearch, error) {
+	query := `
+		SELECT id, employer_id, name, criteria, created_at
+		FROM saved_candidate_searches
+		WHERE employer_id = $1
+		ORDER BY created_at DESC`
+
+	rows, err := config.DB.Query(ctx, query, employerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	searches := []schema.SavedCandidateSearch{}
+	for rows.Next() {
+		var search schema.SavedCandidateSearch
+		if err := rows.Scan(&search.ID, &search.EmployerID, &search.Name, &search.Criteria, &search.CreatedAt); err != nil {
+			return nil, err
+		}
+		searches = append(searches, search)
+	}
+	return searches, rows.Err()
+}
+
+// GetSavedCandidateSearch returns one of an employer's saved searches
+func GetSavedCandidateSearch(ctx context.Context, searchID, employerID int) (schema.SavedCandidateSearch, error) {
+	query := `
+		SELECT id, employer_id, name, criteria, created_at
+		FROM saved_candidate_searches
+		WHERE id = $1 AND employer_id = $2`
+
+	var search schema.SavedCandidateSearch
+	err := config.DB.QueryRow(ctx, query, searchID, employerID).Scan(&search.ID, &search.EmployerID, &search.Name, &search.Criteria, &search.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return search, ErrSavedSearchNotFound
+	}
+	return search, err
+}
+
+// DeleteSavedCandidateSearch removes one of an employer's saved searches
+func DeleteSavedCandidateSearch(ctx context.Context, searchID, employerID int) error {
+	tag, err := config.DB.Exec(ctx, `DELETE FROM saved_candidate_searches WHERE id = $1 AND employer_id = $2`, searchID, employerID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrSavedSearchNotFound
+	}
+	return nil
+}
+
+// InviteCandidate records an invitation for a discoverable seeker to apply
+// to an open job. announce, if given, builds the notifications sending it,
+// which are stored in the same transaction.
+func InviteCandidate(ctx context.Context, invitation schema.CandidateInvitation,
+	announce func(schema.CandidateInvitation) (schema.Outbox, error)) (schema.CandidateInvitation, error) {
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		return invitation, err
+	}
+	defer tx.Rollback(ctx)
+
+	var discoverable bool
+	err = tx.QueryRow(ctx, `SELECT discoverable FROM job_seekers WHERE id = $1`, invitation.JobSeekerID).Scan(&discoverable)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && !discoverable) {
+		return invitation, ErrCandidateNotDiscoverable
+	}
+	if err != nil {
+		return invitation, err
+	}
+
+	var status string
+	err = tx.QueryRow(ctx, `SELECT status, job_title FROM job_listings WHERE id = $1`, invitation.JobListingID).Scan(&status, &invitation.JobTitle)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && status != "Open") {
+		return invitation, ErrJobNotOpen
+	}
+	if err != nil {
+		return invitation, err
+	}
+
+	query := `
+		INSERT INTO candidate_invitations (employer_id, job_seeker_id, job_listing_id, message)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at`
+	err = tx.QueryRow(ctx, query, invitation.EmployerID, invitation.JobSeekerID, invitation.JobListingID, invitation.Message).
+		Scan(&invitation.ID, &invitation.CreatedAt)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return invitation, ErrAlreadyInvited
+	}
+	if err != nil {
+		return invitation, err
+	}
+	if err = announceChange(ctx, tx, announce, invitation); err != nil {
+		return invitation, err
+	}
+	return invitation, tx.Commit(ctx)
+}
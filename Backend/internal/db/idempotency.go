@@ -0,0 +1,72 @@
+package db
+
+import (
+	"Backend/config"
+	"Backend/internal/schema"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ReserveIdempotencyKey claims a key for the first request that uses it. When
+// the key is already taken the stored record is returned with reserved false;
+// its StatusCode is nil while that request is still running. Keys older than
+// ttl are discarded and can be reused, as are reservations still without a
+// response after lease, whose request crashed or timed out.
+func ReserveIdempotencyKey(ctx context.Context, key, scope, requestHash string, ttl, lease time.Duration) (schema.IdempotencyRecord, bool, error) {
+	record := schema.IdempotencyRecord{Key: key, Scope: scope, RequestHash: requestHash}
+
+	_, err := config.DB.Exec(ctx, `
+		DELETE FROM idempotency_keys
+		WHERE idempotency_key = $1 AND scope = $2
+		  AND (created_at < NOW() - $3::INTERVAL OR (status_code IS NULL AND created_at < NOW() - $4::INTERVAL))`,
+		key, scope, ttl, lease)
+	if err != nil {
+		return record, false, err
+	}
+
+	tag, err := config.DB.Exec(ctx, `
+		INSERT INTO idempotency_keys (idempotency_key, scope, request_hash)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING`, key, scope, requestHash)
+	if err != nil {
+		return record, false, err
+	}
+	if tag.RowsAffected() == 1 {
+		return record, true, nil
+	}
+
+	err = config.DB.QueryRow(ctx, `
+		SELECT request_hash, status_code, content_type, response_body
+		FROM idempotency_keys
+		WHERE idempotency_key = $1 AND scope = $2`, key, scope).
+		Scan(&record.RequestHash, &record.StatusCode, &record.ContentType, &record.ResponseBody)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// The holder released the key in the meantime; the client may retry
+		record.StatusCode = nil
+		return record, false, nil
+	}
+	return record, false, err
+}
+
+// CompleteIdempotencyKey stores the response of the request holding a key
+func CompleteIdempotencyKey(ctx context.Context, key, scope string, statusCode int, contentType string, body []byte) error {
+	_, err := config.DB.Exec(ctx, `
+		UPDATE idempotency_keys SET status_code = $3, content_type = $4, response_body = $5
+		WHERE idempotency_key = $1 AND scope = $2`, key, scope, statusCode, contentType, body)
+	return err
+}
+
+// ReleaseIdempotencyKey frees a key whose request failed so it can be retried
+func ReleaseIdempotencyKey(ctx context.Context, key, scope string) error {
+	_, err := config.DB.Exec(ctx, `DELETE FROM idempotency_keys WHERE idempotency_key = $1 AND scope = $2`, key, scope)
+	return err
+}
+
+// PurgeIdempotencyKeys removes keys older than ttl
+func PurgeIdempotencyKeys(ctx context.Context, ttl time.Duration) (int64, error) {
+	tag, err := config.DB.Exec(ctx, `DELETE FROM idempotency_keys WHERE created_at < NOW() - $1::INTERVAL`, ttl)
+	return tag.RowsAffected(), err
+}
@@ -0,0 +1,71 @@
+package db
+
+import (
+	"Backend/internal/dbtest"
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReserveIdempotencyKeyConcurrent(t *testing.T) {
+	dbtest.Require(t)
+
+	const requests = 8
+	var wg sync.WaitGroup
+	reservedCount := make(chan bool, requests)
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, reserved, err := ReserveIdempotencyKey(context.Background(), "key", "scope", "hash", time.Hour, time.Minute)
+			if err != nil {
+				t.Error(err)
+			}
+			reservedCount <- reserved
+		}()
+	}
+	wg.Wait()
+	close(reservedCount)
+
+	reserved := 0
+	for ok := range reservedCount {
+		if ok {
+			reserved++
+		}
+	}
+	if reserved != 1 {
+		t.Errorf("reserved %d times, want once", reserved)
+	}
+}
+
+func TestReserveIdempotencyKeyLease(t *testing.T) {
+	dbtest.Require(t)
+	ctx := context.Background()
+
+	if _, reserved, err := ReserveIdempotencyKey(ctx, "key", "scope", "hash", time.Hour, time.Hour); err != nil || !reserved {
+		t.Fatalf("first reservation: reserved = %v, err = %v", reserved, err)
+	}
+
+	// Still within the lease, the key is held by the running request
+	record, reserved, err := ReserveIdempotencyKey(ctx, "key", "scope", "hash", time.Hour, time.Hour)
+	if err != nil || reserved || record.StatusCode != nil {
+		t.Fatalf("within lease: reserved = %v, status = %v, err = %v; want held and unanswered", reserved, record.StatusCode, err)
+	}
+
+	// Once the lease has run out, an unanswered reservation is taken over
+	time.Sleep(10 * time.Millisecond)
+	if _, reserved, err := ReserveIdempotencyKey(ctx, "key", "scope", "hash", time.Hour, time.Millisecond); err != nil || !reserved {
+		t.Fatalf("after lease: reserved = %v, err = %v; want reserved", reserved, err)
+	}
+
+	// Answered keys are kept for the whole ttl, whatever the lease
+	if err := CompleteIdempotencyKey(ctx, "key", "scope", 201, "application/json", []byte(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	record, reserved, err = ReserveIdempotencyKey(ctx, "key", "scope", "hash", time.Hour, time.Millisecond)
+	if err != nil || reserved || record.StatusCode == nil || *record.StatusCode != 201 {
+		t.Fatalf("answered: reserved = %v, status = %v, err = %v; want the stored 201", reserved, record.StatusCode, err)
+	}
+}
@@ -2,10 +2,78 @@ package db
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"Backend/config"
 	"Backend/internal/schema"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrInterviewNotFound     = errors.New("interview not found")
+	ErrInterviewNotScheduled = errors.New("only scheduled interviews can be changed")
+	ErrRoundNotInJob         = errors.New("interview round does not belong to this job's plan")
+	ErrRoundAlreadyScheduled = errors.New("this round has already been scheduled for the application")
+	ErrRoundInUse            = errors.New("interview round has interviews and cannot be removed")
 )
 
+// interviewColumns lists the interview fields in the order scanInterview reads them
+const interviewColumns = `
+	i.id, i.application_id, i.scheduled_date, i.time_zone, i.interview_mode, i.status, i.interviewer_name,
+	i.interview_link, i.round_id, r.name, i.outcome, i.feedback, i.completed_at,
+	i.interviewer_id, i.duration_minutes, ij.job_title, i.ics_sequence, i.status_reason, i.status_changed_by, i.no_show_party,
+	i.meeting_provider, i.meeting_id,
+	ARRAY(SELECT p.employer_id FROM interview_interviewers p WHERE p.interview_id = i.id ORDER BY p.employer_id)`
+
+const interviewFrom = `
+	FROM interviews i
+	JOIN applications ia ON i.application_id = ia.id
+	JOIN job_listings ij ON ia.job_listing_id = ij.id
+	LEFT JOIN interview_plan_rounds r ON i.round_id = r.id`
+
+func scanInterview(row pgx.Row) (schema.Interview, error) {
+	var interview schema.Interview
+	err := row.Scan(&interview.ID, &interview.ApplicationID, &interview.ScheduledDate, &interview.TimeZone, &interview.InterviewMode,
+		&interview.Status, &interview.InterviewerName, &interview.InterviewLink, &interview.RoundID,
+		&interview.RoundName, &interview.Outcome, &interview.Feedback, &interview.CompletedAt,
+		&interview.InterviewerID, &interview.DurationMinutes, &interview.JobTitle, &interview.Sequence,
+		&interview.StatusReason, &interview.StatusChangedBy, &interview.NoShowParty,
+		&interview.MeetingProvider, &interview.MeetingID, &interview.InterviewerIDs)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return interview, ErrInterviewNotFound
+	}
+	return interview, err
+}
+
+func getInterview(ctx context.Context, q querier, interviewID int) (schema.Interview, error) {
+	return scanInterview(q.QueryRow(ctx, `SELECT `+interviewColumns+interviewFrom+` WHERE i.id = $1`, interviewID))
+}
+
+func queryInterviews(ctx context.Context, query string, args ...any) ([]schema.Interview, error) {
+	rows, err := config.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var interviews []schema.Interview
+	for rows.Next() {
+		interview, err := scanInterview(rows)
+		if err != nil {
+			return nil, err
+		}
+		interviews = append(interviews, interview)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return interviews, nil
+}
+
 func GenerateInterviewID(ctx context.Context) (int, error) {
 	var newID int
 	query := `SELECT generate_interview_id()` // Replace with actual stored procedure name
@@ -16,10 +84,117 @@ func GenerateInterviewID(ctx context.Context) (int, error) {
 	return newID, nil
 }
 
-func ScheduleInterview(ctx context.Context, interview schema.Interview) (schema.Interview, error) {
-	query := `INSERT INTO interviews (application_id, scheduled_date, interview_mode, interviewer_name, interview_link) VALUES ($1, $2, $3, $4, $5) RETURNING *`
-	var result schema.Interview
-	err := config.DB.QueryRow(ctx, query, interview.ApplicationID, interview.ScheduledDate, interview.InterviewMode, interview.InterviewerName, interview.InterviewLink).Scan(&result.ID, &result.ApplicationID, &result.ScheduledDate, &result.InterviewMode, &result.Status, &result.InterviewerName, &result.InterviewLink)
+// GetInterview returns a single interview with its round
+func GetInterview(ctx context.Context, interviewID int) (schema.Interview, error) {
+	return getInterview(ctx, config.DB, interviewID)
+}
+
+// ScheduleInterview creates an interview and records it in the application's
+// history. A round must belong to the plan of the application's job and can
+// only be scheduled once unless its earlier interview was cancelled. Unless
+// allowConflicts is set, an overlap with another interview of the candidate
+// or of a panel member fails with an *InterviewConflictError. announce, if
+// given, builds the notifications about the interview, which are stored in
+// the same transaction.
+func ScheduleInterview(ctx context.Context, interview schema.Interview, actor schema.Actor, allowConflicts bool,
+	announce func(schema.Interview) (schema.Outbox, error)) (schema.Interview, error) {
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		return schema.Interview{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := scheduleInterview(ctx, tx, interview, actor, allowConflicts)
+	if err != nil {
+		return schema.Interview{}, err
+	}
+	if err = announceChange(ctx, tx, announce, result); err != nil {
+		return schema.Interview{}, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return schema.Interview{}, err
+	}
+	return result, nil
+}
+
+// scheduleInterview creates an interview inside the caller's transaction.
+// Without a duration the round's duration is used, or one hour.
+func scheduleInterview(ctx context.Context, tx querier, interview schema.Interview, actor schema.Actor, allowConflicts bool) (schema.Interview, error) {
+	if _, err := lockApplication(ctx, tx, interview.ApplicationID); err != nil {
+		return schema.Interview{}, err
+	}
+
+	var roundDuration *int
+	if interview.RoundID != nil {
+		query := `
+			SELECT r.duration_minutes FROM interview_plan_rounds r
+			JOIN applications a ON a.job_listing_id = r.job_listing_id
+			WHERE r.id = $1 AND a.id = $2`
+		err := tx.QueryRow(ctx, query, *interview.RoundID, interview.ApplicationID).Scan(&roundDuration)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return schema.Interview{}, ErrRoundNotInJob
+		}
+		if err != nil {
+			return schema.Interview{}, err
+		}
+	}
+	if interview.DurationMinutes == 0 {
+		interview.DurationMinutes = 60
+		if roundDuration != nil {
+			interview.DurationMinutes = *roundDuration
+		}
+	}
+
+	var panel []int
+	interview.InterviewerID, panel = interviewPanel(interview.InterviewerID, interview.InterviewerIDs)
+	if !allowConflicts {
+		err := checkInterviewConflicts(ctx, tx, interview.ApplicationID, 0, panel, interview.ScheduledDate, interview.DurationMinutes)
+		if err != nil {
+			return schema.Interview{}, err
+		}
+	}
+
+	query := `
+		INSERT INTO interviews (application_id, scheduled_date, interview_mode, interviewer_name, interview_link, round_id, interviewer_id, duration_minutes, time_zone)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, COALESCE(NULLIF($9, ''), 'UTC'))
+		RETURNING id`
+	var interviewID int
+	err := tx.QueryRow(ctx, query, interview.ApplicationID, interview.ScheduledDate.UTC(), interview.InterviewMode, interview.InterviewerName,
+		interview.InterviewLink, interview.RoundID, interview.InterviewerID, interview.DurationMinutes, interview.TimeZone).Scan(&interviewID)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return schema.Interview{}, ErrRoundAlreadyScheduled
+	}
+	if err != nil {
+		return schema.Interview{}, err
+	}
+
+	if err = setInterviewPanel(ctx, tx, interviewID, panel); err != nil {
+		return schema.Interview{}, err
+	}
+	if err = scheduleInterviewReminders(ctx, tx, interviewID, interview.ScheduledDate); err != nil {
+		return schema.Interview{}, err
+	}
+
+	result, err := getInterview(ctx, tx, interviewID)
+	if err != nil {
+		return schema.Interview{}, err
+	}
+
+	from, to, err := syncInterviewStatus(ctx, tx, result.ApplicationID)
+	if err != nil {
+		return schema.Interview{}, err
+	}
+	err = recordApplicationEvent(ctx, tx, schema.ApplicationEvent{
+		ApplicationID: result.ApplicationID,
+		EventType:     schema.EventInterviewScheduled,
+		FromStatus:    from,
+		ToStatus:      to,
+		ActorType:     actor.Type,
+		ActorID:       actor.ID,
+		Reason:        result.RoundName,
+	})
 	if err != nil {
 		return schema.Interview{}, err
 	}
@@ -27,75 +202,293 @@ func ScheduleInterview(ctx context.Context, interview schema.Interview) (schema.
 }
 
 func GetSeekerInterviews(ctx context.Context, seekerID int) ([]schema.Interview, error) {
+	query := `SELECT ` + interviewColumns + interviewFrom + `
+		WHERE ia.job_seeker_id = $1
+		ORDER BY i.scheduled_date`
+	return queryInterviews(ctx, query, seekerID)
+}
+
+// GetEmployerInterviews returns the interviews of an employer's jobs and the
+// interviews the employer conducts for colleagues
+func GetEmployerInterviews(ctx context.Context, employerID int) ([]schema.Interview, error) {
+	query := `SELECT ` + interviewColumns + interviewFrom + `
+		WHERE ij.employer_id = $1
+		   OR EXISTS (SELECT 1 FROM interview_interviewers p WHERE p.interview_id = i.id AND p.employer_id = $1)
+		ORDER BY i.scheduled_date`
+	return queryInterviews(ctx, query, employerID)
+}
+
+// GetInterviews returns all interviews of an application, including completed
+// and cancelled ones, in the order of the job's interview plan
+func GetInterviews(ctx context.Context, applicationID int) ([]schema.Interview, error) {
+	query := `SELECT ` + interviewColumns + interviewFrom + `
+		WHERE i.application_id = $1
+		ORDER BY r.position NULLS LAST, i.scheduled_date`
+	return queryInterviews(ctx, query, applicationID)
+}
+
+// UpdateInterview changes the time, duration, mode, panel or link of a
+// scheduled interview. The panel and duration are kept when not given.
+// Overlaps are checked as in ScheduleInterview. The calendar sequence is
+// raised so invites already sent are replaced; announce, if given, builds the
+// updated invites, which are stored in the same transaction.
+func UpdateInterview(ctx context.Context, interview schema.Interview, allowConflicts bool,
+	announce func(schema.Interview) (schema.Outbox, error)) (schema.Interview, error) {
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		return schema.Interview{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	current, err := lockInterview(ctx, tx, interview.ID)
+	if err != nil {
+		return schema.Interview{}, err
+	}
+	result, err := updateInterview(ctx, tx, current, interview, allowConflicts)
+	if err != nil {
+		return schema.Interview{}, err
+	}
+	if err = announceChange(ctx, tx, announce, result); err != nil {
+		return schema.Interview{}, err
+	}
+	return result, tx.Commit(ctx)
+}
+
+// updateInterview applies an update to a locked interview inside the caller's transaction
+func updateInterview(ctx context.Context, tx querier, current, interview schema.Interview, allowConflicts bool) (schema.Interview, error) {
+	if current.Status != "Scheduled" {
+		return schema.Interview{}, ErrInterviewNotScheduled
+	}
+	if interview.DurationMinutes == 0 {
+		interview.DurationMinutes = current.DurationMinutes
+	}
+	lead, panel := current.InterviewerID, current.InterviewerIDs
+	if interview.InterviewerIDs != nil || interview.InterviewerID != nil {
+		lead, panel = interviewPanel(interview.InterviewerID, interview.InterviewerIDs)
+	}
+
+	if !allowConflicts {
+		err := checkInterviewConflicts(ctx, tx, current.ApplicationID, current.ID, panel, interview.ScheduledDate, interview.DurationMinutes)
+		if err != nil {
+			return schema.Interview{}, err
+		}
+	}
+
+	// A link typed in by the employer replaces a generated meeting
+	query := `UPDATE interviews SET scheduled_date=$1, interview_mode=$2, interviewer_name=$3, interview_link=$4, ics_sequence = ics_sequence + 1,
+		time_zone = COALESCE(NULLIF($6, ''), time_zone), duration_minutes = $7, interviewer_id = $8,
+		meeting_provider = CASE WHEN interview_link IS NOT DISTINCT FROM $4 THEN meeting_provider END,
+		meeting_id = CASE WHEN interview_link IS NOT DISTINCT FROM $4 THEN meeting_id END
+		WHERE id=$5`
+	_, err := tx.Exec(ctx, query, interview.ScheduledDate.UTC(), interview.InterviewMode, interview.InterviewerName, interview.InterviewLink,
+		current.ID, interview.TimeZone, interview.DurationMinutes, lead)
+	if err != nil {
+		return schema.Interview{}, err
+	}
+	if err = setInterviewPanel(ctx, tx, current.ID, panel); err != nil {
+		return schema.Interview{}, err
+	}
+	if err = scheduleInterviewReminders(ctx, tx, current.ID, interview.ScheduledDate); err != nil {
+		return schema.Interview{}, err
+	}
+	return getInterview(ctx, tx, current.ID)
+}
+
+// CompleteInterview marks a scheduled interview as held and records its
+// outcome in the application's history. announce, if given, builds the
+// notifications about it, which are stored in the same transaction.
+func CompleteInterview(ctx context.Context, interviewID int, outcome string, feedback *string, actor schema.Actor,
+	announce func(schema.Interview) (schema.Outbox, error)) (schema.Interview, error) {
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		return schema.Interview{}, err
+	}
+	defer tx.Rollback(ctx)
+
 	query := `
-		SELECT i.id, i.application_id, i.scheduled_date, i.interview_mode, i.status, 
-		       i.interviewer_name, i.interview_link 
-		FROM interviews i
-		JOIN applications a ON i.application_id = a.id
-		WHERE a.job_seeker_id = $1
-	`
-	rows, err := config.DB.Query(ctx, query, seekerID)
+		UPDATE interviews SET status = 'Completed', outcome = $2, feedback = $3, completed_at = NOW()
+		WHERE id = $1 AND status = 'Scheduled'`
+	tag, err := tx.Exec(ctx, query, interviewID, outcome, feedback)
 	if err != nil {
+		return schema.Interview{}, err
+	}
+
+	interview, err := getInterview(ctx, tx, interviewID)
+	if err != nil {
+		return schema.Interview{}, err
+	}
+	if tag.RowsAffected() == 0 {
+		return schema.Interview{}, ErrInterviewNotScheduled
+	}
+	if err = cancelInterviewReminders(ctx, tx, interviewID); err != nil {
+		return schema.Interview{}, err
+	}
+	if err = closeRescheduleRequests(ctx, tx, interviewID); err != nil {
+		return schema.Interview{}, err
+	}
+
+	reason := outcome
+	if interview.RoundName != nil {
+		reason = fmt.Sprintf("%s: %s", *interview.RoundName, outcome)
+	}
+	err = recordApplicationEvent(ctx, tx, schema.ApplicationEvent{
+		ApplicationID: interview.ApplicationID,
+		EventType:     schema.EventInterviewCompleted,
+		ActorType:     actor.Type,
+		ActorID:       actor.ID,
+		Reason:        &reason,
+	})
+	if err != nil {
+		return schema.Interview{}, err
+	}
+
+	if err = announceChange(ctx, tx, announce, interview); err != nil {
+		return schema.Interview{}, err
+	}
+
+	return interview, tx.Commit(ctx)
+}
+
+// cancelScheduledInterviews cancels the interviews of an application that
+// have not taken place and returns them. Completed interviews are kept as
+// history.
+func cancelScheduledInterviews(ctx context.Context, q querier, applicationID int) ([]schema.Interview, error) {
+	query := `
+		UPDATE interview_reminders SET cancelled_at = NOW()
+		WHERE sent_at IS NULL AND cancelled_at IS NULL
+		  AND interview_id IN (SELECT id FROM interviews WHERE application_id = $1 AND status = 'Scheduled')`
+	if _, err := q.Exec(ctx, query, applicationID); err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	var interviews []schema.Interview
+	query = `
+		UPDATE interview_reschedule_requests SET status = 'Closed', responded_at = NOW()
+		WHERE status = 'Pending'
+		  AND interview_id IN (SELECT id FROM interviews WHERE application_id = $1 AND status = 'Scheduled')`
+	if _, err := q.Exec(ctx, query, applicationID); err != nil {
+		return nil, err
+	}
+
+	rows, err := q.Query(ctx, `UPDATE interviews SET status = 'Cancelled', ics_sequence = ics_sequence + 1
+		WHERE application_id = $1 AND status = 'Scheduled' RETURNING id`, applicationID)
+	if err != nil {
+		return nil, err
+	}
+	var ids []int
 	for rows.Next() {
-		var interview schema.Interview
-		err := rows.Scan(&interview.ID, &interview.ApplicationID, &interview.ScheduledDate, &interview.InterviewMode, &interview.Status, &interview.InterviewerName, &interview.InterviewLink)
-		if err != nil {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
 			return nil, err
 		}
-		interviews = append(interviews, interview)
+		ids = append(ids, id)
 	}
-
-	if err = rows.Err(); err != nil {
+	rows.Close()
+	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 
-	return interviews, nil
+	cancelled := make([]schema.Interview, 0, len(ids))
+	for _, id := range ids {
+		interview, err := getInterview(ctx, q, id)
+		if err != nil {
+			return nil, err
+		}
+		cancelled = append(cancelled, interview)
+	}
+	return cancelled, nil
 }
 
-func GetInterviews(ctx context.Context, applicationID int) ([]schema.Interview, error) {
-	query := `SELECT * FROM interviews WHERE application_id=$1`
-	rows, err := config.DB.Query(ctx, query, applicationID)
+// GetInterviewPlan returns the ordered interview rounds of a job
+func GetInterviewPlan(ctx context.Context, jobListingID int) ([]schema.InterviewRound, error) {
+	return getInterviewPlan(ctx, config.DB, jobListingID)
+}
+
+func getInterviewPlan(ctx context.Context, q querier, jobListingID int) ([]schema.InterviewRound, error) {
+	query := `
+		SELECT id, job_listing_id, name, position, duration_minutes, description
+		FROM interview_plan_rounds
+		WHERE job_listing_id = $1
+		ORDER BY position, id`
+	rows, err := q.Query(ctx, query, jobListingID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var interviews []schema.Interview
+	rounds := []schema.InterviewRound{}
 	for rows.Next() {
-		var interview schema.Interview
-		err := rows.Scan(&interview.ID, &interview.ApplicationID, &interview.ScheduledDate, &interview.InterviewMode, &interview.Status, &interview.InterviewerName, &interview.InterviewLink)
+		var round schema.InterviewRound
+		err := rows.Scan(&round.ID, &round.JobListingID, &round.Name, &round.Position, &round.DurationMinutes, &round.Description)
 		if err != nil {
 			return nil, err
 		}
-		interviews = append(interviews, interview)
+		rounds = append(rounds, round)
 	}
+	return rounds, rows.Err()
+}
 
-	if err = rows.Err(); err != nil {
+// SetInterviewPlan replaces the interview plan of a job. Rounds passed with an
+// ID are updated, the others inserted, and rounds left out are removed unless
+// interviews were held or scheduled for them. Positions follow the slice order.
+func SetInterviewPlan(ctx context.Context, jobListingID int, rounds []schema.InterviewRound) ([]schema.InterviewRound, error) {
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
 		return nil, err
 	}
+	defer tx.Rollback(ctx)
 
-	return interviews, nil
-}
+	keep := make([]int, 0, len(rounds))
+	for i, round := range rounds {
+		if round.ID == 0 {
+			continue
+		}
+		query := `UPDATE interview_plan_rounds SET name = $1, position = $2, duration_minutes = $3, description = $4
+			WHERE id = $5 AND job_listing_id = $6`
+		tag, err := tx.Exec(ctx, query, round.Name, i+1, round.DurationMinutes, round.Description, round.ID, jobListingID)
+		if err != nil {
+			return nil, err
+		}
+		if tag.RowsAffected() == 0 {
+			return nil, ErrRoundNotInJob
+		}
+		keep = append(keep, round.ID)
+	}
+
+	var inUse bool
+	inUseQuery := `
+		SELECT EXISTS (
+			SELECT 1 FROM interviews i
+			JOIN interview_plan_rounds r ON i.round_id = r.id
+			WHERE r.job_listing_id = $1 AND NOT (r.id = ANY($2))
+		)`
+	if err := tx.QueryRow(ctx, inUseQuery, jobListingID, keep).Scan(&inUse); err != nil {
+		return nil, err
+	}
+	if inUse {
+		return nil, ErrRoundInUse
+	}
 
-func UpdateInterview(ctx context.Context, interview schema.Interview) (schema.Interview, error) {
-	query := `UPDATE interviews SET scheduled_date=$1, interview_mode=$2, interviewer_name=$3, interview_link=$4 WHERE id=$5 RETURNING *`
-	var result schema.Interview
-	err := config.DB.QueryRow(ctx, query, interview.ScheduledDate, interview.InterviewMode, interview.InterviewerName, interview.InterviewLink, interview.ID).Scan(&result.ID, &result.ApplicationID, &result.ScheduledDate, &result.InterviewMode, &result.Status, &result.InterviewerName, &result.InterviewLink)
+	_, err = tx.Exec(ctx, `DELETE FROM interview_plan_rounds WHERE job_listing_id = $1 AND NOT (id = ANY($2))`, jobListingID, keep)
 	if err != nil {
-		return schema.Interview{}, err
+		return nil, err
+	}
+
+	insertQuery := `INSERT INTO interview_plan_rounds (job_listing_id, name, position, duration_minutes, description) VALUES ($1, $2, $3, $4, $5)`
+	for i, round := range rounds {
+		if round.ID != 0 {
+			continue
+		}
+		if _, err := tx.Exec(ctx, insertQuery, jobListingID, round.Name, i+1, round.DurationMinutes, round.Description); err != nil {
+			return nil, err
+		}
 	}
-	return result, nil
-}
 
-func DeleteInterview(ctx context.Context, applicationID int) error {
-	query := `DELETE FROM interviews WHERE application_id = $1`
-	_, err := config.DB.Exec(ctx, query, applicationID)
-	return err
+	result, err := getInterviewPlan(ctx, tx, jobListingID)
+	if err != nil {
+		return nil, err
+	}
+	return result, tx.Commit(ctx)
 }
 
 // GetSeekerInterviewCount retrieves the count of interviews for a given seeker
@@ -0,0 +1,129 @@
+package db
+
+import (
+	"Backend/internal/schema"
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrInterviewConflict = errors.New("the candidate or an interviewer already has an interview at this time")
+
+// InterviewConflictError carries the interviews that overlap the time asked
+// for. It matches ErrInterviewConflict with errors.Is.
+type InterviewConflictError struct {
+	Conflicts []schema.InterviewConflict
+}
+
+func (e *InterviewConflictError) Error() string {
+	return ErrInterviewConflict.Error()
+}
+
+func (e *InterviewConflictError) Unwrap() error {
+	return ErrInterviewConflict
+}
+
+// interviewPanel returns the lead interviewer and the whole panel without
+// duplicates. The lead is the given lead when set, otherwise the first
+// panel member, and always sits on the panel.
+func interviewPanel(lead *int, members []int) (*int, []int) {
+	panel := make([]int, 0, len(members)+1)
+	seen := make(map[int]bool)
+	if lead != nil {
+		panel = append(panel, *lead)
+		seen[*lead] = true
+	}
+	for _, id := range members {
+		if !seen[id] {
+			seen[id] = true
+			panel = append(panel, id)
+		}
+	}
+	if lead == nil && len(panel) > 0 {
+		lead = &panel[0]
+	}
+	return lead, panel
+}
+
+// setInterviewPanel replaces the interviewers of an interview
+func setInterviewPanel(ctx context.Context, tx querier, interviewID int, panel []int) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM interview_interviewers WHERE interview_id = $1`, interviewID); err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO interview_interviewers (interview_id, employer_id)
+		SELECT $1, unnest($2::int[])`
+	_, err := tx.Exec(ctx, query, interviewID, panel)
+	return err
+}
+
+// checkInterviewConflicts looks for scheduled interviews of the candidate of
+// an application or of any panel member that overlap the given time,
+// ignoring the interview being changed. The interviewer and candidate rows
+// are locked first, in that order, so concurrent scheduling for the same
+// people waits for this transaction instead of missing its interview.
+func checkInterviewConflicts(ctx context.Context, tx querier, applicationID, excludeID int, panel []int, start time.Time, durationMinutes int) error {
+	var jobSeekerID, companyID int
+	query := `
+		SELECT a.job_seeker_id, e.companyid
+		FROM applications a
+		JOIN job_listings j ON a.job_listing_id = j.id
+		JOIN employers e ON j.employer_id = e.id
+		WHERE a.id = $1`
+	if err := tx.QueryRow(ctx, query, applicationID).Scan(&jobSeekerID, &companyID); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM employers WHERE id = ANY($1) ORDER BY id FOR UPDATE`, panel); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM job_seekers WHERE id = $1 FOR UPDATE`, jobSeekerID); err != nil {
+		return err
+	}
+
+	end := start.Add(time.Duration(durationMinutes) * time.Minute)
+	conflictQuery := `
+		SELECT i.id, i.application_id, i.scheduled_date, i.duration_minutes, e.companyid = $6,
+		       ia.job_seeker_id = $1,
+		       ARRAY(SELECT p.employer_id FROM interview_interviewers p
+		             WHERE p.interview_id = i.id AND p.employer_id = ANY($2) ORDER BY p.employer_id)
+		FROM interviews i
+		JOIN applications ia ON i.application_id = ia.id
+		JOIN job_listings j ON ia.job_listing_id = j.id
+		JOIN employers e ON j.employer_id = e.id
+		WHERE i.status = 'Scheduled' AND i.id <> $5
+		  AND i.scheduled_date < $4 AND i.scheduled_date + make_interval(mins => i.duration_minutes) > $3
+		  AND (ia.job_seeker_id = $1 OR EXISTS (
+			SELECT 1 FROM interview_interviewers p WHERE p.interview_id = i.id AND p.employer_id = ANY($2)))
+		ORDER BY i.scheduled_date`
+	rows, err := tx.Query(ctx, conflictQuery, jobSeekerID, panel, start, end, excludeID, companyID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var conflicts []schema.InterviewConflict
+	for rows.Next() {
+		var conflict schema.InterviewConflict
+		var interviewID, conflictApplicationID int
+		var sameCompany bool
+		err := rows.Scan(&interviewID, &conflictApplicationID, &conflict.ScheduledDate, &conflict.DurationMinutes,
+			&sameCompany, &conflict.Candidate, &conflict.InterviewerIDs)
+		if err != nil {
+			return err
+		}
+		if sameCompany {
+			conflict.InterviewID = &interviewID
+			conflict.ApplicationID = &conflictApplicationID
+		}
+		conflicts = append(conflicts, conflict)
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	if len(conflicts) > 0 {
+		return &InterviewConflictError{Conflicts: conflicts}
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package db
+
+import (
+	"Backend/internal/dbtest"
+	"Backend/internal/schema"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// conflictFixture is an interviewer with an hour-long interview from 10:00
+// tomorrow, and a job of the same company to schedule further interviews for
+type conflictFixture struct {
+	interviewerID int
+	jobID         int
+	jobSeekerID   int
+	booked        schema.Interview
+	ten           time.Time
+}
+
+func newConflictFixture(t *testing.T) conflictFixture {
+	t.Helper()
+	interviewerID := dbtest.Employer(t, dbtest.Company(t))
+	jobID := dbtest.Job(t, interviewerID)
+	ten := time.Now().UTC().Truncate(24 * time.Hour).Add(34 * time.Hour)
+
+	jobSeekerID := dbtest.JobSeeker(t)
+	booked := scheduleAt(t, dbtest.Application(t, jobSeekerID, jobID, "Applied"), interviewerID, ten, 60, false)
+	return conflictFixture{interviewerID: interviewerID, jobID: jobID, jobSeekerID: jobSeekerID, booked: booked, ten: ten}
+}
+
+func scheduleAt(t *testing.T, applicationID, interviewerID int, start time.Time, durationMinutes int, allowConflicts bool) schema.Interview {
+	t.Helper()
+	interview, err := scheduleWith(applicationID, interviewerID, start, durationMinutes, allowConflicts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return interview
+}
+
+func scheduleWith(applicationID, interviewerID int, start time.Time, durationMinutes int, allowConflicts bool) (schema.Interview, error) {
+	return ScheduleInterview(context.Background(), schema.Interview{
+		ApplicationID:   applicationID,
+		ScheduledDate:   start,
+		InterviewMode:   "Video",
+		InterviewerID:   &interviewerID,
+		DurationMinutes: durationMinutes,
+	}, schema.Actor{Type: "employer", ID: &interviewerID}, allowConflicts, nil)
+}
+
+func TestCheckInterviewConflictsInterviewer(t *testing.T) {
+	tests := []struct {
+		name     string
+		offset   time.Duration // From the start of the booked interview
+		duration int
+		conflict bool
+	}{
+		{"ends as the booked one starts", -30 * time.Minute, 30, false},
+		{"starts as the booked one ends", time.Hour, 45, false},
+		{"longer one runs into the booked one", -30 * time.Minute, 45, true},
+		{"shorter one inside the booked one", 15 * time.Minute, 15, true},
+		{"longer one around the booked one", -time.Hour, 180, true},
+		{"starts a minute before the booked one ends", 59 * time.Minute, 30, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dbtest.Require(t)
+			fixture := newConflictFixture(t)
+			applicationID := dbtest.Application(t, dbtest.JobSeeker(t), fixture.jobID, "Applied")
+			_, err := scheduleWith(applicationID, fixture.interviewerID, fixture.ten.Add(tt.offset), tt.duration, false)
+			if !tt.conflict {
+				if err != nil {
+					t.Fatalf("err = %v, want none", err)
+				}
+				return
+			}
+
+			var conflictErr *InterviewConflictError
+			if !errors.As(err, &conflictErr) || !errors.Is(err, ErrInterviewConflict) {
+				t.Fatalf("err = %v, want an *InterviewConflictError", err)
+			}
+			if len(conflictErr.Conflicts) != 1 {
+				t.Fatalf("got %d conflicts, want 1", len(conflictErr.Conflicts))
+			}
+			conflict := conflictErr.Conflicts[0]
+			if conflict.InterviewID == nil || *conflict.InterviewID != fixture.booked.ID {
+				t.Errorf("conflict interview = %v, want %d", conflict.InterviewID, fixture.booked.ID)
+			}
+			if conflict.Candidate || len(conflict.InterviewerIDs) != 1 || conflict.InterviewerIDs[0] != fixture.interviewerID {
+				t.Errorf("conflict = %+v, want the interviewer only", conflict)
+			}
+		})
+	}
+}
+
+func TestCheckInterviewConflictsBookedDuration(t *testing.T) {
+	dbtest.Require(t)
+	fixture := newConflictFixture(t)
+
+	// A 90 minute interview still runs at 11:00, while the 60 minute one
+	// booked by the fixture has ended
+	otherID := dbtest.Employer(t, dbtest.Company(t))
+	otherJobID := dbtest.Job(t, otherID)
+	scheduleAt(t, dbtest.Application(t, dbtest.JobSeeker(t), otherJobID, "Applied"), otherID, fixture.ten, 90, false)
+
+	eleven := fixture.ten.Add(time.Hour)
+	if _, err := scheduleWith(dbtest.Application(t, dbtest.JobSeeker(t), fixture.jobID, "Applied"), fixture.interviewerID, eleven, 30, false); err != nil {
+		t.Fatalf("60 minute interview: err = %v, want none", err)
+	}
+	_, err := scheduleWith(dbtest.Application(t, dbtest.JobSeeker(t), otherJobID, "Applied"), otherID, eleven, 30, false)
+	var conflictErr *InterviewConflictError
+	if !errors.As(err, &conflictErr) {
+		t.Fatalf("90 minute interview: err = %v, want an *InterviewConflictError", err)
+	}
+	if got := conflictErr.Conflicts[0].DurationMinutes; got != 90 {
+		t.Errorf("conflict duration = %d, want 90", got)
+	}
+}
+
+func TestCheckInterviewConflictsCandidate(t *testing.T) {
+	dbtest.Require(t)
+	fixture := newConflictFixture(t)
+
+	// The candidate applied to a job of another company, whose interviewer
+	// is free but must not see which interview is in the way
+	otherID := dbtest.Employer(t, dbtest.Company(t))
+	applicationID := dbtest.Application(t, fixture.jobSeekerID, dbtest.Job(t, otherID), "Applied")
+
+	_, err := scheduleWith(applicationID, otherID, fixture.ten.Add(30*time.Minute), 60, false)
+	var conflictErr *InterviewConflictError
+	if !errors.As(err, &conflictErr) {
+		t.Fatalf("err = %v, want an *InterviewConflictError", err)
+	}
+	conflict := conflictErr.Conflicts[0]
+	if !conflict.Candidate || len(conflict.InterviewerIDs) != 0 {
+		t.Errorf("conflict = %+v, want the candidate only", conflict)
+	}
+	if conflict.InterviewID != nil || conflict.ApplicationID != nil {
+		t.Errorf("conflict shows another company's interview %v of application %v", conflict.InterviewID, conflict.ApplicationID)
+	}
+	if !conflict.ScheduledDate.Equal(fixture.ten) {
+		t.Errorf("conflict time = %v, want %v", conflict.ScheduledDate, fixture.ten)
+	}
+}
+
+func TestCheckInterviewConflictsOverride(t *testing.T) {
+	dbtest.Require(t)
+	fixture := newConflictFixture(t)
+	actor := schema.Actor{Type: "employer", ID: &fixture.interviewerID}
+
+	// An interview moved within its own time does not conflict with itself
+	if _, err := RescheduleInterview(context.Background(), fixture.booked.ID, fixture.ten.Add(15*time.Minute), "", 0, actor, nil, false, nil); err != nil {
+		t.Fatalf("reschedule within its own time: %v", err)
+	}
+
+	applicationID := dbtest.Application(t, dbtest.JobSeeker(t), fixture.jobID, "Applied")
+	if _, err := scheduleWith(applicationID, fixture.interviewerID, fixture.ten, 60, false); !errors.Is(err, ErrInterviewConflict) {
+		t.Fatalf("err = %v, want ErrInterviewConflict", err)
+	}
+	overlapping := scheduleAt(t, applicationID, fixture.interviewerID, fixture.ten, 60, true)
+	if overlapping.Status != "Scheduled" {
+		t.Errorf("status = %q, want Scheduled", overlapping.Status)
+	}
+
+	// Moving either interview is still checked against the other
+	_, err := RescheduleInterview(context.Background(), overlapping.ID, fixture.ten.Add(30*time.Minute), "", 0, actor, nil, false, nil)
+	if !errors.Is(err, ErrInterviewConflict) {
+		t.Fatalf("reschedule: err = %v, want ErrInterviewConflict", err)
+	}
+	if _, err := RescheduleInterview(context.Background(), overlapping.ID, fixture.ten.Add(30*time.Minute), "", 0, actor, nil, true, nil); err != nil {
+		t.Fatalf("reschedule with override: %v", err)
+	}
+}
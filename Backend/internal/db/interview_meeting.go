@@ -0,0 +1,46 @@
+package db
+
+import (
+	"Backend/config"
+	"Backend/internal/schema"
+	"context"
+	"errors"
+)
+
+var ErrMeetingChanged = errors.New("the interview's meeting link was changed in the meantime")
+
+// SetInterviewMeeting stores a generated meeting link on an interview, or
+// clears the link when provider is nil. It only applies while the interview
+// still has the meeting expectedMeetingID, so a concurrent change is not
+// overwritten; ErrMeetingChanged is returned then. A new link raises the
+// calendar sequence, and announce, if given, builds the invites carrying it,
+// which are stored in the same transaction.
+func SetInterviewMeeting(ctx context.Context, interviewID int, expectedMeetingID, link, provider, meetingID *string,
+	announce func(schema.Interview) (schema.Outbox, error)) (schema.Interview, error) {
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		return schema.Interview{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE interviews SET interview_link = $3, meeting_provider = $4, meeting_id = $5,
+			ics_sequence = ics_sequence + CASE WHEN $3::text IS NULL THEN 0 ELSE 1 END
+		WHERE id = $1 AND meeting_id IS NOT DISTINCT FROM $2`
+	tag, err := tx.Exec(ctx, query, interviewID, expectedMeetingID, link, provider, meetingID)
+	if err != nil {
+		return schema.Interview{}, err
+	}
+	if tag.RowsAffected() == 0 {
+		return schema.Interview{}, ErrMeetingChanged
+	}
+
+	interview, err := getInterview(ctx, tx, interviewID)
+	if err != nil {
+		return schema.Interview{}, err
+	}
+	if err = announceChange(ctx, tx, announce, interview); err != nil {
+		return schema.Interview{}, err
+	}
+	return interview, tx.Commit(ctx)
+}
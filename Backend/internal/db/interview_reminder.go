@@ -0,0 +1,90 @@
+package db
+
+import (
+	"Backend/config"
+	"Backend/internal/schema"
+	"context"
+	"time"
+)
+
+// interviewReminderMinutes are the reminders sent before every interview:
+// a day before and an hour before
+var interviewReminderMinutes = []int{24 * 60, 60}
+
+// scheduleInterviewReminders replaces the pending reminders of an interview
+// with ones for its current time. Reminders whose time has already passed
+// are skipped, and reminders already sent are kept as they are.
+func scheduleInterviewReminders(ctx context.Context, tx querier, interviewID int, scheduledDate time.Time) error {
+	if err := cancelInterviewReminders(ctx, tx, interviewID); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO interview_reminders (interview_id, minutes_before, send_at)
+		SELECT $1, m, $2::timestamptz - make_interval(mins => m)
+		FROM unnest($3::int[]) AS m
+		WHERE $2::timestamptz - make_interval(mins => m) > NOW()`
+	_, err := tx.Exec(ctx, query, interviewID, scheduledDate, interviewReminderMinutes)
+	return err
+}
+
+// cancelInterviewReminders cancels the reminders of an interview that have not been sent
+func cancelInterviewReminders(ctx context.Context, tx querier, interviewID int) error {
+	query := `
+		UPDATE interview_reminders SET cancelled_at = NOW()
+		WHERE interview_id = $1 AND sent_at IS NULL AND cancelled_at IS NULL`
+	_, err := tx.Exec(ctx, query, interviewID)
+	return err
+}
+
+// ClaimInterviewReminders marks due reminders of scheduled interviews as sent
+// and returns them. announce builds the notifications of each reminder, which
+// are stored in the same transaction. Rows locked by another instance are
+// skipped, so each reminder is delivered once however many workers run.
+func ClaimInterviewReminders(ctx context.Context, limit int, announce func(schema.InterviewReminder) (schema.Outbox, error)) ([]schema.InterviewReminder, error) {
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		WITH due AS (
+			SELECT r.id FROM interview_reminders r
+			JOIN interviews i ON r.interview_id = i.id
+			WHERE r.sent_at IS NULL AND r.cancelled_at IS NULL AND r.send_at <= NOW()
+			  AND i.status = 'Scheduled'
+			ORDER BY r.send_at
+			LIMIT $1
+			FOR UPDATE OF r SKIP LOCKED
+		)
+		UPDATE interview_reminders r SET sent_at = NOW()
+		FROM due
+		WHERE r.id = due.id
+		RETURNING r.id, r.interview_id, r.minutes_before, r.send_at`
+
+	rows, err := tx.Query(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	var reminders []schema.InterviewReminder
+	for rows.Next() {
+		var reminder schema.InterviewReminder
+		if err := rows.Scan(&reminder.ID, &reminder.InterviewID, &reminder.MinutesBefore, &reminder.SendAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		reminders = append(reminders, reminder)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, reminder := range reminders {
+		if err := announceChange(ctx, tx, announce, reminder); err != nil {
+			return nil, err
+		}
+	}
+	return reminders, tx.Commit(ctx)
+}
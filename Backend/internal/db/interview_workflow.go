@@ -0,0 +1,400 @@
+package db
+
+import (
+	"Backend/config"
+	"Backend/internal/schema"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrInterviewNotStarted       = errors.New("a no-show can only be recorded once the interview has started")
+	ErrReschedulePending         = errors.New("a reschedule request for this interview is already pending")
+	ErrRescheduleRequestNotFound = errors.New("there is no pending reschedule request for this interview")
+)
+
+const rescheduleRequestColumns = `
+	id, interview_id, job_seeker_id, reason, proposed_times, status, responded_by, response, responded_at, created_at`
+
+func scanRescheduleRequest(row pgx.Row) (schema.RescheduleRequest, error) {
+	var request schema.RescheduleRequest
+	err := row.Scan(&request.ID, &request.InterviewID, &request.JobSeekerID, &request.Reason, &request.ProposedTimes,
+		&request.Status, &request.RespondedBy, &request.Response, &request.RespondedAt, &request.CreatedAt)
+	return request, err
+}
+
+// lockInterview locks the application of an interview and then the interview.
+// Taking the application first, as decisions do, keeps the two from deadlocking.
+func lockInterview(ctx context.Context, tx querier, interviewID int) (schema.Interview, error) {
+	var applicationID int
+	err := tx.QueryRow(ctx, `SELECT application_id FROM interviews WHERE id = $1`, interviewID).Scan(&applicationID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return schema.Interview{}, ErrInterviewNotFound
+	}
+	if err != nil {
+		return schema.Interview{}, err
+	}
+	if _, err := lockApplication(ctx, tx, applicationID); err != nil {
+		return schema.Interview{}, err
+	}
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM interviews WHERE id = $1 FOR UPDATE`, interviewID); err != nil {
+		return schema.Interview{}, err
+	}
+	return getInterview(ctx, tx, interviewID)
+}
+
+// lockApplication locks an application row and returns its status
+func lockApplication(ctx context.Context, tx querier, applicationID int) (string, error) {
+	var status string
+	err := tx.QueryRow(ctx, `SELECT application_status FROM applications WHERE id = $1 FOR UPDATE`, applicationID).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrApplicationNotFound
+	}
+	return status, err
+}
+
+// syncInterviewStatus keeps the application status in step with its
+// interviews: an Applied application moves to Interview Scheduled once an
+// interview is scheduled, and moves back when all its interviews were
+// cancelled. It returns the old and new status when it changed either.
+func syncInterviewStatus(ctx context.Context, tx querier, applicationID int) (from, to *string, err error) {
+	status, err := lockApplication(ctx, tx, applicationID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var scheduled, active bool
+	query := `
+		SELECT COALESCE(bool_or(status = 'Scheduled'), FALSE), COALESCE(bool_or(status <> 'Cancelled'), FALSE)
+		FROM interviews WHERE application_id = $1`
+	if err := tx.QueryRow(ctx, query, applicationID).Scan(&scheduled, &active); err != nil {
+		return nil, nil, err
+	}
+
+	next := status
+	switch {
+	case status == "Applied" && scheduled:
+		next = "Interview Scheduled"
+	case status == "Interview Scheduled" && !active:
+		next = "Applied"
+	}
+	if next == status {
+		return nil, nil, nil
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE applications SET application_status = $1 WHERE id = $2`, next, applicationID); err != nil {
+		return nil, nil, err
+	}
+	return &status, &next, nil
+}
+
+// closeRescheduleRequests closes the pending reschedule request of an
+// interview that will not be rescheduled any more
+func closeRescheduleRequests(ctx context.Context, tx querier, interviewID int) error {
+	_, err := tx.Exec(ctx, `UPDATE interview_reschedule_requests SET status = 'Closed', responded_at = NOW()
+		WHERE interview_id = $1 AND status = 'Pending'`, interviewID)
+	return err
+}
+
+// CancelInterview cancels a scheduled interview on behalf of either side and
+// records who cancelled it and why. Pending reminders and reschedule requests
+// are dropped, and the application goes back to Applied if no other
+// interview is left. announce, if given, builds the notifications about the
+// cancellation, which are stored in the same transaction.
+func CancelInterview(ctx context.Context, interviewID int, actor schema.Actor, reason string, announce func(schema.Interview) (schema.Outbox, error)) (schema.Interview, error) {
+	return endInterview(ctx, interviewID, "Cancelled", nil, actor, reason, schema.EventInterviewCancelled, announce)
+}
+
+// MarkNoShow records that the candidate or the interviewer did not attend a
+// scheduled interview that has started. announce is as in CancelInterview.
+func MarkNoShow(ctx context.Context, interviewID int, party string, actor schema.Actor, reason *string,
+	announce func(schema.Interview) (schema.Outbox, error)) (schema.Interview, error) {
+	statusReason := ""
+	if reason != nil {
+		statusReason = *reason
+	}
+	return endInterview(ctx, interviewID, "No Show", &party, actor, statusReason, schema.EventInterviewNoShow, announce)
+}
+
+// ReportNoShow records in the application's history that the candidate
+// reported the interviewer missing from a scheduled interview that has
+// started. The interview stays scheduled until the hiring team confirms it
+// with MarkNoShow or holds it after all. announce, if given, builds the
+// report to the hiring team, which is stored in the same transaction.
+func ReportNoShow(ctx context.Context, interviewID, jobSeekerID int, reason *string, announce func(schema.Interview) (schema.Outbox, error)) (schema.Interview, error) {
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		return schema.Interview{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	current, err := lockInterview(ctx, tx, interviewID)
+	if err != nil {
+		return schema.Interview{}, err
+	}
+	if current.Status != "Scheduled" {
+		return schema.Interview{}, ErrInterviewNotScheduled
+	}
+	if current.ScheduledDate.After(time.Now()) {
+		return schema.Interview{}, ErrInterviewNotStarted
+	}
+
+	err = recordApplicationEvent(ctx, tx, schema.ApplicationEvent{
+		ApplicationID: current.ApplicationID,
+		EventType:     schema.EventNoShowReported,
+		ActorType:     "job_seeker",
+		ActorID:       &jobSeekerID,
+		Reason:        reason,
+	})
+	if err != nil {
+		return schema.Interview{}, err
+	}
+	if err = announceChange(ctx, tx, announce, current); err != nil {
+		return schema.Interview{}, err
+	}
+	return current, tx.Commit(ctx)
+}
+
+func endInterview(ctx context.Context, interviewID int, status string, noShowParty *string, actor schema.Actor, reason, eventType string,
+	announce func(schema.Interview) (schema.Outbox, error)) (schema.Interview, error) {
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		return schema.Interview{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	current, err := lockInterview(ctx, tx, interviewID)
+	if err != nil {
+		return schema.Interview{}, err
+	}
+	if current.Status != "Scheduled" {
+		return schema.Interview{}, ErrInterviewNotScheduled
+	}
+	if noShowParty != nil && current.ScheduledDate.After(time.Now()) {
+		return schema.Interview{}, ErrInterviewNotStarted
+	}
+
+	query := `
+		UPDATE interviews SET status = $2, no_show_party = $3, status_reason = NULLIF($4, ''), status_changed_by = $5,
+			ics_sequence = ics_sequence + 1
+		WHERE id = $1`
+	if _, err := tx.Exec(ctx, query, interviewID, status, noShowParty, reason, actor.Type); err != nil {
+		return schema.Interview{}, err
+	}
+	if err = cancelInterviewReminders(ctx, tx, interviewID); err != nil {
+		return schema.Interview{}, err
+	}
+	if err = closeRescheduleRequests(ctx, tx, interviewID); err != nil {
+		return schema.Interview{}, err
+	}
+
+	from, to, err := syncInterviewStatus(ctx, tx, current.ApplicationID)
+	if err != nil {
+		return schema.Interview{}, err
+	}
+	event := schema.ApplicationEvent{
+		ApplicationID: current.ApplicationID,
+		EventType:     eventType,
+		FromStatus:    from,
+		ToStatus:      to,
+		ActorType:     actor.Type,
+		ActorID:       actor.ID,
+	}
+	if reason != "" {
+		event.Reason = &reason
+	}
+	if err = recordApplicationEvent(ctx, tx, event); err != nil {
+		return schema.Interview{}, err
+	}
+
+	result, err := getInterview(ctx, tx, interviewID)
+	if err != nil {
+		return schema.Interview{}, err
+	}
+	if err = announceChange(ctx, tx, announce, result); err != nil {
+		return schema.Interview{}, err
+	}
+	return result, tx.Commit(ctx)
+}
+
+// RescheduleInterview moves a scheduled interview to a new time, keeping its
+// panel and mode, and accepts the candidate's pending reschedule request if
+// there is one. Without a duration or time zone the current ones are kept.
+// Overlaps are checked as in ScheduleInterview. announce, if given, builds the
+// notifications about the new time, which are stored in the same transaction.
+func RescheduleInterview(ctx context.Context, interviewID int, scheduledDate time.Time, timeZone string, durationMinutes int,
+	actor schema.Actor, reason *string, allowConflicts bool, announce func(schema.Interview) (schema.Outbox, error)) (schema.Interview, error) {
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		return schema.Interview{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	current, err := lockInterview(ctx, tx, interviewID)
+	if err != nil {
+		return schema.Interview{}, err
+	}
+
+	moved := current
+	moved.ScheduledDate = scheduledDate
+	moved.TimeZone = timeZone
+	moved.DurationMinutes = durationMinutes
+	moved.InterviewerID, moved.InterviewerIDs = nil, nil
+	result, err := updateInterview(ctx, tx, current, moved, allowConflicts)
+	if err != nil {
+		return schema.Interview{}, err
+	}
+
+	query := `
+		UPDATE interview_reschedule_requests SET status = 'Accepted', responded_by = $2, response = $3, responded_at = NOW()
+		WHERE interview_id = $1 AND status = 'Pending'`
+	if _, err := tx.Exec(ctx, query, interviewID, actor.ID, reason); err != nil {
+		return schema.Interview{}, err
+	}
+
+	err = recordApplicationEvent(ctx, tx, schema.ApplicationEvent{
+		ApplicationID: current.ApplicationID,
+		EventType:     schema.EventInterviewRescheduled,
+		ActorType:     actor.Type,
+		ActorID:       actor.ID,
+		Reason:        reason,
+	})
+	if err != nil {
+		return schema.Interview{}, err
+	}
+	if err = announceChange(ctx, tx, announce, result); err != nil {
+		return schema.Interview{}, err
+	}
+	return result, tx.Commit(ctx)
+}
+
+// RequestReschedule lets the candidate ask the hiring team to move a
+// scheduled interview, optionally proposing times that would suit them.
+// Only one request per interview can be pending. announce, if given, builds
+// the notifications to the hiring team, which are stored in the same
+// transaction.
+func RequestReschedule(ctx context.Context, interviewID, jobSeekerID int, reason string, proposedTimes []time.Time,
+	announce func(schema.RescheduleRequest) (schema.Outbox, error)) (schema.RescheduleRequest, error) {
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		return schema.RescheduleRequest{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	current, err := lockInterview(ctx, tx, interviewID)
+	if err != nil {
+		return schema.RescheduleRequest{}, err
+	}
+	var ownerID int
+	if err := tx.QueryRow(ctx, `SELECT job_seeker_id FROM applications WHERE id = $1`, current.ApplicationID).Scan(&ownerID); err != nil {
+		return schema.RescheduleRequest{}, err
+	}
+	if ownerID != jobSeekerID {
+		return schema.RescheduleRequest{}, ErrNotApplicationOwner
+	}
+	if current.Status != "Scheduled" {
+		return schema.RescheduleRequest{}, ErrInterviewNotScheduled
+	}
+
+	if proposedTimes == nil {
+		proposedTimes = []time.Time{}
+	}
+	query := `
+		INSERT INTO interview_reschedule_requests (interview_id, job_seeker_id, reason, proposed_times)
+		VALUES ($1, $2, $3, $4)
+		RETURNING` + rescheduleRequestColumns
+	request, err := scanRescheduleRequest(tx.QueryRow(ctx, query, interviewID, jobSeekerID, reason, proposedTimes))
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return schema.RescheduleRequest{}, ErrReschedulePending
+	}
+	if err != nil {
+		return schema.RescheduleRequest{}, err
+	}
+
+	err = recordApplicationEvent(ctx, tx, schema.ApplicationEvent{
+		ApplicationID: current.ApplicationID,
+		EventType:     schema.EventRescheduleRequested,
+		ActorType:     "job_seeker",
+		ActorID:       &jobSeekerID,
+		Reason:        &reason,
+	})
+	if err != nil {
+		return schema.RescheduleRequest{}, err
+	}
+	if err = announceChange(ctx, tx, announce, request); err != nil {
+		return schema.RescheduleRequest{}, err
+	}
+	return request, tx.Commit(ctx)
+}
+
+// DeclineReschedule turns down the pending reschedule request of an
+// interview, which stays at its current time. announce is as in
+// RequestReschedule, for the candidate.
+func DeclineReschedule(ctx context.Context, interviewID int, actor schema.Actor, response *string,
+	announce func(schema.RescheduleRequest) (schema.Outbox, error)) (schema.RescheduleRequest, error) {
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		return schema.RescheduleRequest{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	current, err := lockInterview(ctx, tx, interviewID)
+	if err != nil {
+		return schema.RescheduleRequest{}, err
+	}
+
+	query := `
+		UPDATE interview_reschedule_requests SET status = 'Declined', responded_by = $2, response = $3, responded_at = NOW()
+		WHERE interview_id = $1 AND status = 'Pending'
+		RETURNING` + rescheduleRequestColumns
+	request, err := scanRescheduleRequest(tx.QueryRow(ctx, query, interviewID, actor.ID, response))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return schema.RescheduleRequest{}, ErrRescheduleRequestNotFound
+	}
+	if err != nil {
+		return schema.RescheduleRequest{}, err
+	}
+
+	err = recordApplicationEvent(ctx, tx, schema.ApplicationEvent{
+		ApplicationID: current.ApplicationID,
+		EventType:     schema.EventRescheduleDeclined,
+		ActorType:     actor.Type,
+		ActorID:       actor.ID,
+		Reason:        response,
+	})
+	if err != nil {
+		return schema.RescheduleRequest{}, err
+	}
+	if err = announceChange(ctx, tx, announce, request); err != nil {
+		return schema.RescheduleRequest{}, err
+	}
+	return request, tx.Commit(ctx)
+}
+
+// GetRescheduleRequests returns the reschedule requests of an interview, newest first
+func GetRescheduleRequests(ctx context.Context, interviewID int) ([]schema.RescheduleRequest, error) {
+	query := `SELECT` + rescheduleRequestColumns + `
+		FROM interview_reschedule_requests
+		WHERE interview_id = $1
+		ORDER BY created_at DESC, id DESC`
+	rows, err := config.DB.Query(ctx, query, interviewID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	requests := []schema.RescheduleRequest{}
+	for rows.Next() {
+		request, err := scanRescheduleRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, request)
+	}
+	return requests, rows.Err()
+}
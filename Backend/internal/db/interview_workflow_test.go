@@ -0,0 +1,60 @@
+package db
+
+import (
+	"Backend/config"
+	"Backend/internal/dbtest"
+	"Backend/internal/schema"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestReportNoShowKeepsInterviewScheduled(t *testing.T) {
+	dbtest.Require(t)
+	ctx := context.Background()
+	interviewerID := dbtest.Employer(t, dbtest.Company(t))
+	seekerID := dbtest.JobSeeker(t)
+	applicationID := dbtest.Application(t, seekerID, dbtest.Job(t, interviewerID), "Applied")
+
+	upcoming := scheduleAt(t, applicationID, interviewerID, time.Now().Add(time.Hour), 60, false)
+	if _, err := ReportNoShow(ctx, upcoming.ID, seekerID, nil, nil); !errors.Is(err, ErrInterviewNotStarted) {
+		t.Fatalf("upcoming interview: err = %v, want ErrInterviewNotStarted", err)
+	}
+
+	started := scheduleAt(t, applicationID, interviewerID, time.Now().Add(-10*time.Minute), 60, true)
+	reason := "Nobody joined the call"
+	if _, err := ReportNoShow(ctx, started.ID, seekerID, &reason, nil); err != nil {
+		t.Fatal(err)
+	}
+	interview, err := GetInterview(ctx, started.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if interview.Status != "Scheduled" || interview.NoShowParty != nil {
+		t.Errorf("interview = %s (no-show party %v), want it still Scheduled", interview.Status, interview.NoShowParty)
+	}
+
+	var reported int
+	err = config.DB.QueryRow(ctx, `SELECT COUNT(*) FROM application_history WHERE application_id = $1 AND event_type = $2`,
+		applicationID, schema.EventNoShowReported).Scan(&reported)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reported != 1 {
+		t.Errorf("reported events = %d, want 1", reported)
+	}
+
+	// The hiring team can still confirm it
+	actor := schema.Actor{Type: "employer", ID: &interviewerID}
+	confirmed, err := MarkNoShow(ctx, started.ID, "employer", actor, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if confirmed.Status != "No Show" {
+		t.Errorf("status = %q, want No Show", confirmed.Status)
+	}
+	if _, err := ReportNoShow(ctx, started.ID, seekerID, nil, nil); !errors.Is(err, ErrInterviewNotScheduled) {
+		t.Errorf("after no-show: err = %v, want ErrInterviewNotScheduled", err)
+	}
+}
@@ -96,6 +96,31 @@ func FetchJob(jobID int) (*schema.JobListing, error) {
 	return &job, nil
 }
 
+// GetJobRequirements retrieves the requirements of a job regardless of its status
+func GetJobRequirements(jobID int) ([]string, error) {
+	db := config.GetDB()
+	query := "SELECT name FROM requirement WHERE job_listing_id = $1 ORDER BY id"
+
+	rows, err := db.Query(context.Background(), query, jobID)
+	if err != nil {
+		log.Println("[ERROR] GetJobRequirements - Error fetching requirements:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var requirements []string
+	for rows.Next() {
+		var requirement string
+		if err := rows.Scan(&requirement); err != nil {
+			log.Println("[ERROR] GetJobRequirements - Error scanning requirement:", err)
+			return nil, err
+		}
+		requirements = append(requirements, requirement)
+	}
+
+	return requirements, rows.Err()
+}
+
 // FetchJobsByEmployer retrieves all jobs posted by a specific employer
 func FetchJobsByEmployer(employerID int) ([]schema.JobListing, error) {
 	db := config.GetDB()
@@ -223,20 +248,69 @@ func FilterJobs(location, jobType string, minSalary, maxSalary float64, skills [
 	return jobs, nil
 }
 
-// ApplyJob allows a job seeker to apply for a job using a stored procedure
-func ApplyJob(jobSeekerID, jobListingID int, coverLetter string) (int, error) {
+// ApplyJob allows a job seeker to apply for a job using a stored procedure.
+// announce, if given, builds the notifications about the new application,
+// which are stored in the same transaction.
+func ApplyJob(jobSeekerID, jobListingID int, coverLetter string, resumeID *int, announce func(applicationID int) (schema.Outbox, error)) (int, error) {
 	db := config.GetDB()
 
+	tx, err := db.Begin(context.Background())
+	if err != nil {
+		log.Println("[ERROR] Failed to start transaction:", err)
+		return 0, err
+	}
+	defer tx.Rollback(context.Background())
+
 	var applicationID int
-	err := db.QueryRow(context.Background(), "SELECT apply_for_job($1, $2, $3)",
+	err = tx.QueryRow(context.Background(), "SELECT apply_for_job($1, $2, $3)",
 		jobSeekerID, jobListingID, coverLetter,
 	).Scan(&applicationID)
 
+	if isDuplicateApplication(err) {
+		log.Printf("[WARN] Duplicate application: JobSeekerID=%d, JobID=%d\n", jobSeekerID, jobListingID)
+		return 0, ErrAlreadyApplied
+	}
 	if err != nil {
 		log.Println("[ERROR] Failed to apply for job:", err)
 		return 0, err
 	}
 
+	if _, err = attachResume(context.Background(), tx, applicationID, jobSeekerID, resumeID); err != nil {
+		log.Println("[ERROR] Failed to attach resume:", err)
+		return 0, err
+	}
+
+	//  Record the submission as the first event of the application's history
+	status := "Applied"
+	err = recordApplicationEvent(context.Background(), tx, schema.ApplicationEvent{
+		ApplicationID: applicationID,
+		EventType:     schema.EventApplied,
+		ToStatus:      &status,
+		ActorType:     "job_seeker",
+		ActorID:       &jobSeekerID,
+	})
+	if err != nil {
+		log.Println("[ERROR] Failed to record application history:", err)
+		return 0, err
+	}
+
+	if announce != nil {
+		outbox, err := announce(applicationID)
+		if err != nil {
+			log.Println("[ERROR] Failed to prepare notifications:", err)
+			return 0, err
+		}
+		if err = storeOutbox(context.Background(), tx, outbox); err != nil {
+			log.Println("[ERROR] Failed to store notifications:", err)
+			return 0, err
+		}
+	}
+
+	if err = tx.Commit(context.Background()); err != nil {
+		log.Println("[ERROR] Failed to commit application:", err)
+		return 0, err
+	}
+
 	log.Printf("[SUCCESS] Job Application Submitted: JobSeekerID=%d, JobID=%d\n", jobSeekerID, jobListingID)
 	return applicationID, nil
 }
@@ -0,0 +1,271 @@
+package db
+
+import (
+	"Backend/config"
+	"Backend/internal/schema"
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrThreadNotFound     = errors.New("message thread not found")
+	ErrAttachmentNotFound = errors.New("attachment not found")
+)
+
+// GetApplicationParticipants returns the applicant and the employer who
+// posted the job of an application
+func GetApplicationParticipants(ctx context.Context, applicationID int) (schema.ApplicationParticipants, error) {
+	query := `
+		SELECT a.id, a.job_seeker_id, j.employer_id
+		FROM applications a
+		JOIN job_listings j ON a.job_listing_id = j.id
+		WHERE a.id = $1`
+
+	var participants schema.ApplicationParticipants
+	err := config.DB.QueryRow(ctx, query, applicationID).Scan(&participants.ApplicationID, &participants.JobSeekerID, &participants.EmployerID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return participants, ErrApplicationNotFound
+	}
+	return participants, err
+}
+
+// CreateThread starts a conversation on an application with its first
+// message. announce, if given, builds the notifications about the message,
+// which are stored in the same transaction.
+func CreateThread(ctx context.Context, thread schema.MessageThread, message schema.Message,
+	announce func(schema.Message) (schema.Outbox, error)) (schema.MessageThread, schema.Message, error) {
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		return thread, message, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO message_threads (application_id, subject, created_by_type, created_by_id)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at`
+	err = tx.QueryRow(ctx, query, thread.ApplicationID, thread.Subject, thread.CreatedByType, thread.CreatedByID).Scan(&thread.ID, &thread.CreatedAt)
+	if err != nil {
+		return thread, message, err
+	}
+
+	message.ThreadID = thread.ID
+	if message, err = createMessage(ctx, tx, message); err != nil {
+		return thread, message, err
+	}
+	thread.LastMessageAt = &message.CreatedAt
+	if err = announceChange(ctx, tx, announce, message); err != nil {
+		return thread, message, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return thread, message, err
+	}
+	return thread, message, nil
+}
+
+// AddMessage adds a message with its attachments to an existing thread.
+// announce is as in CreateThread.
+func AddMessage(ctx context.Context, message schema.Message, announce func(schema.Message) (schema.Outbox, error)) (schema.Message, error) {
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		return message, err
+	}
+	defer tx.Rollback(ctx)
+
+	if message, err = createMessage(ctx, tx, message); err != nil {
+		return message, err
+	}
+	if err = announceChange(ctx, tx, announce, message); err != nil {
+		return message, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return message, err
+	}
+	return message, nil
+}
+
+func createMessage(ctx context.Context, q querier, message schema.Message) (schema.Message, error) {
+	query := `
+		INSERT INTO messages (thread_id, sender_type, sender_id, body)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at`
+	err := q.QueryRow(ctx, query, message.ThreadID, message.SenderType, message.SenderID, message.Body).Scan(&message.ID, &message.CreatedAt)
+	if err != nil {
+		return message, err
+	}
+
+	for i := range message.Attachments {
+		attachment := &message.Attachments[i]
+		attachment.MessageID = message.ID
+		err = q.QueryRow(ctx, `
+			INSERT INTO message_attachments (message_id, file_name, file_path, mime_type, size_bytes)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id`,
+			attachment.MessageID, attachment.FileName, attachment.FilePath, attachment.MimeType, attachment.SizeBytes,
+		).Scan(&attachment.ID)
+		if err != nil {
+			return message, err
+		}
+	}
+	if message.Attachments == nil {
+		message.Attachments = []schema.MessageAttachment{}
+	}
+	return message, nil
+}
+
+// GetThread returns a single thread
+func GetThread(ctx context.Context, threadID int) (schema.MessageThread, error) {
+	query := `
+		SELECT id, application_id, subject, created_by_type, created_by_id, created_at
+		FROM message_threads WHERE id = $1`
+
+	var thread schema.MessageThread
+	err := config.DB.QueryRow(ctx, query, threadID).Scan(&thread.ID, &thread.ApplicationID, &thread.Subject,
+		&thread.CreatedByType, &thread.CreatedByID, &thread.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return thread, ErrThreadNotFound
+	}
+	return thread, err
+}
+
+// GetThreads returns the threads of an application, most recently active
+// first, with the number of messages the viewer has not read yet
+func GetThreads(ctx context.Context, applicationID int, viewerType string) ([]schema.MessageThread, error) {
+	query := `
+		SELECT t.id, t.application_id, t.subject, t.created_by_type, t.created_by_id, t.created_at,
+		       MAX(m.created_at),
+		       COUNT(m.id) FILTER (WHERE m.sender_type <> $2 AND m.read_at IS NULL)
+		FROM message_threads t
+		LEFT JOIN messages m ON m.thread_id = t.id
+		WHERE t.application_id = $1
+		GROUP BY t.id
+		ORDER BY MAX(m.created_at) DESC NULLS LAST, t.id DESC`
+
+	rows, err := config.DB.Query(ctx, query, applicationID, viewerType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	threads := []schema.MessageThread{}
+	for rows.Next() {
+		var thread schema.MessageThread
+		err := rows.Scan(&thread.ID, &thread.ApplicationID, &thread.Subject, &thread.CreatedByType,
+			&thread.CreatedByID, &thread.CreatedAt, &thread.LastMessageAt, &thread.UnreadCount)
+		if err != nil {
+			return nil, err
+		}
+		threads = append(threads, thread)
+	}
+	return threads, rows.Err()
+}
+
+// GetMessages returns the messages of a thread, oldest first, with attachments
+func GetMessages(ctx context.Context, threadID int) ([]schema.Message, error) {
+	query := `
+		SELECT id, thread_id, sender_type, sender_id, body, created_at, read_at
+		FROM messages WHERE thread_id = $1
+		ORDER BY created_at, id`
+
+	rows, err := config.DB.Query(ctx, query, threadID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := []schema.Message{}
+	index := make(map[int]int)
+	for rows.Next() {
+		var message schema.Message
+		err := rows.Scan(&message.ID, &message.ThreadID, &message.SenderType, &message.SenderID,
+			&message.Body, &message.CreatedAt, &message.ReadAt)
+		if err != nil {
+			return nil, err
+		}
+		message.Attachments = []schema.MessageAttachment{}
+		index[message.ID] = len(messages)
+		messages = append(messages, message)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	attachmentQuery := `
+		SELECT a.id, a.message_id, a.file_name, a.file_path, a.mime_type, a.size_bytes
+		FROM message_attachments a
+		JOIN messages m ON a.message_id = m.id
+		WHERE m.thread_id = $1
+		ORDER BY a.id`
+	attachmentRows, err := config.DB.Query(ctx, attachmentQuery, threadID)
+	if err != nil {
+		return nil, err
+	}
+	defer attachmentRows.Close()
+
+	for attachmentRows.Next() {
+		var attachment schema.MessageAttachment
+		err := attachmentRows.Scan(&attachment.ID, &attachment.MessageID, &attachment.FileName,
+			&attachment.FilePath, &attachment.MimeType, &attachment.SizeBytes)
+		if err != nil {
+			return nil, err
+		}
+		message := &messages[index[attachment.MessageID]]
+		message.Attachments = append(message.Attachments, attachment)
+	}
+	return messages, attachmentRows.Err()
+}
+
+// MarkThreadRead sets the read receipt on every message in a thread that was
+// sent to the reader and returns how many messages were marked
+func MarkThreadRead(ctx context.Context, threadID int, readerType string) (int64, error) {
+	query := `UPDATE messages SET read_at = CURRENT_TIMESTAMP WHERE thread_id = $1 AND sender_type <> $2 AND read_at IS NULL`
+	tag, err := config.DB.Exec(ctx, query, threadID, readerType)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// GetUnreadMessageCount returns how many messages across all of a user's
+// application threads they have not read yet
+func GetUnreadMessageCount(ctx context.Context, userType string, userID int) (int, error) {
+	participantColumn := "a.job_seeker_id"
+	if userType == "employer" {
+		participantColumn = "j.employer_id"
+	}
+
+	query := `
+		SELECT COUNT(*)
+		FROM messages m
+		JOIN message_threads t ON m.thread_id = t.id
+		JOIN applications a ON t.application_id = a.id
+		JOIN job_listings j ON a.job_listing_id = j.id
+		WHERE ` + participantColumn + ` = $1 AND m.sender_type <> $2 AND m.read_at IS NULL`
+
+	var count int
+	err := config.DB.QueryRow(ctx, query, userID, userType).Scan(&count)
+	return count, err
+}
+
+// GetAttachment returns an attachment with the application its thread belongs to
+func GetAttachment(ctx context.Context, attachmentID int) (schema.MessageAttachment, int, error) {
+	query := `
+		SELECT a.id, a.message_id, a.file_name, a.file_path, a.mime_type, a.size_bytes, t.application_id
+		FROM message_attachments a
+		JOIN messages m ON a.message_id = m.id
+		JOIN message_threads t ON m.thread_id = t.id
+		WHERE a.id = $1`
+
+	var attachment schema.MessageAttachment
+	var applicationID int
+	err := config.DB.QueryRow(ctx, query, attachmentID).Scan(&attachment.ID, &attachment.MessageID, &attachment.FileName,
+		&attachment.FilePath, &attachment.MimeType, &attachment.SizeBytes, &applicationID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return attachment, 0, ErrAttachmentNotFound
+	}
+	return attachment, applicationID, err
+}
@@ -0,0 +1,203 @@
+package db
+
+import (
+	"Backend/config"
+	"Backend/internal/schema"
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrTemplateNotFound  = errors.New("message template not found")
+	ErrTemplateNameTaken = errors.New("a template with this name already exists")
+)
+
+const templateColumns = `id, company_id, name, body, created_by, created_at, updated_at`
+
+func scanTemplate(row pgx.Row) (schema.MessageTemplate, error) {
+	var template schema.MessageTemplate
+	err := row.Scan(&template.ID, &template.CompanyID, &template.Name, &template.Body,
+		&template.CreatedBy, &template.CreatedAt, &template.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return template, ErrTemplateNotFound
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return template, ErrTemplateNameTaken
+	}
+	return template, err
+}
+
+// CreateMessageTemplate stores a template for the employer's company
+func CreateMessageTemplate(ctx context.Context, employerID int, name, body string) (schema.MessageTemplate, error) {
+	query := `
+		INSERT INTO message_templates (company_id, name, body, created_by)
+		SELECT companyid, $2, $3, id FROM employers WHERE id = $1
+		RETURNING ` + templateColumns
+	return scanTemplate(config.DB.QueryRow(ctx, query, employerID, name, body))
+}
+
+// GetMessageTemplates returns the templates of the employer's company by name
+func GetMessageTemplates(ctx context.Context, employerID int) ([]schema.MessageTemplate, error) {
+	query := `
+		SELECT ` + templateColumns + `
+		FROM message_templates
+		WHERE company_id = (SELECT companyid FROM employers WHERE id = $1)
+		ORDER BY name`
+
+	rows, err := config.DB.Query(ctx, query, employerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	templates := []schema.MessageTemplate{}
+	for rows.Next() {
+		template, err := scanTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, template)
+	}
+	return templates, rows.Err()
+}
+
+// GetMessageTemplate returns a template if it belongs to the employer's company
+func GetMessageTemplate(ctx context.Context, templateID, employerID int) (schema.MessageTemplate, error) {
+	query := `
+		SELECT ` + templateColumns + `
+		FROM message_templates
+		WHERE id = $1 AND company_id = (SELECT companyid FROM employers WHERE id = $2)`
+	return scanTemplate(config.DB.QueryRow(ctx, query, templateID, employerID))
+}
+
+// UpdateMessageTemplate renames or rewrites a template of the employer's company
+func UpdateMessageTemplate(ctx context.Context, templateID, employerID int, name, body string) (schema.MessageTemplate, error) {
+	query := `
+		UPDATE message_templates SET name = $3, body = $4, updated_at = NOW()
+		WHERE id = $1 AND company_id = (SELECT companyid FROM employers WHERE id = $2)
+		RETURNING ` + templateColumns
+	return scanTemplate(config.DB.QueryRow(ctx, query, templateID, employerID, name, body))
+}
+
+// DeleteMessageTemplate removes a template of the employer's company
+func DeleteMessageTemplate(ctx context.Context, templateID, employerID int) error {
+	query := `
+		DELETE FROM message_templates
+		WHERE id = $1 AND company_id = (SELECT companyid FROM employers WHERE id = $2)`
+	tag, err := config.DB.Exec(ctx, query, templateID, employerID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrTemplateNotFound
+	}
+	return nil
+}
+
+// scheduleNotifications stores notifications to be delivered at their SendAt
+// time inside the caller's transaction
+func scheduleNotifications(ctx context.Context, q querier, notifications []schema.ScheduledNotification) error {
+	query := `
+		INSERT INTO scheduled_notifications (application_id, user_id, user_type, message, send_at)
+		VALUES ($1, $2, $3, $4, $5)`
+	for _, notification := range notifications {
+		_, err := q.Exec(ctx, query, notification.ApplicationID, notification.UserID,
+			notification.UserType, notification.Message, notification.SendAt)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cancelScheduledNotifications drops the unsent notifications of an application
+func cancelScheduledNotifications(ctx context.Context, q querier, applicationID int) error {
+	query := `
+		UPDATE scheduled_notifications SET cancelled_at = NOW()
+		WHERE application_id = $1 AND sent_at IS NULL AND cancelled_at IS NULL`
+	_, err := q.Exec(ctx, query, applicationID)
+	return err
+}
+
+// ClaimDueNotifications marks up to limit due notifications as sent and
+// returns them. announce builds what each one delivers, which is stored in
+// the same transaction, so a notification is marked sent exactly when its
+// delivery is queued. Rows locked by another worker are skipped.
+func ClaimDueNotifications(ctx context.Context, limit int, announce func(schema.ScheduledNotification) (schema.Outbox, error)) ([]schema.ScheduledNotification, error) {
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		WITH due AS (
+			SELECT id FROM scheduled_notifications
+			WHERE sent_at IS NULL AND cancelled_at IS NULL AND send_at <= NOW()
+			ORDER BY send_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE scheduled_notifications s SET sent_at = NOW()
+		FROM due
+		WHERE s.id = due.id
+		RETURNING s.id, s.application_id, s.user_id, s.user_type, s.message, s.send_at`
+
+	rows, err := tx.Query(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	var notifications []schema.ScheduledNotification
+	for rows.Next() {
+		var notification schema.ScheduledNotification
+		err := rows.Scan(&notification.ID, &notification.ApplicationID, &notification.UserID,
+			&notification.UserType, &notification.Message, &notification.SendAt)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		notifications = append(notifications, notification)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, notification := range notifications {
+		if err := announceChange(ctx, tx, announce, notification); err != nil {
+			return nil, err
+		}
+	}
+	return notifications, tx.Commit(ctx)
+}
+
+// GetRejectionReasonCounts counts a job's rejected applications per reason
+// code. Rejections without a code are counted under "unspecified".
+func GetRejectionReasonCounts(ctx context.Context, jobListingID int) ([]schema.RejectionReasonCount, error) {
+	query := `
+		SELECT COALESCE(rejection_reason_code, 'unspecified'), COUNT(*)
+		FROM applications
+		WHERE job_listing_id = $1 AND application_status = 'Rejected'
+		GROUP BY 1
+		ORDER BY 2 DESC, 1`
+
+	rows, err := config.DB.Query(ctx, query, jobListingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := []schema.RejectionReasonCount{}
+	for rows.Next() {
+		var count schema.RejectionReasonCount
+		if err := rows.Scan(&count.ReasonCode, &count.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, count)
+	}
+	return counts, rows.Err()
+}
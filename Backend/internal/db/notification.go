@@ -26,13 +26,14 @@ func StoreNotification(ctx context.Context, notification schema.Notification) er
 	return err
 }
 
-func GetNotifications(ctx context.Context, seekerID int) ([]schema.Notification, error) {
+// GetNotifications returns the notifications of a job seeker or employer, newest first
+func GetNotifications(ctx context.Context, userType string, userID int) ([]schema.Notification, error) {
 	var notifications []schema.Notification
 	query := `SELECT id, user_id, user_type, message, is_read, created_at 
-			  FROM notifications WHERE user_type = 'job_seeker' AND user_id = $1 
+			  FROM notifications WHERE user_type = $1 AND user_id = $2 
 			  ORDER BY created_at DESC`
 
-	rows, err := config.DB.Query(ctx, query, seekerID)
+	rows, err := config.DB.Query(ctx, query, userType, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -50,8 +51,9 @@ func GetNotifications(ctx context.Context, seekerID int) ([]schema.Notification,
 	return notifications, nil
 }
 
-func UpdateNotificationStatus(ctx context.Context, seekerID int) error {
-	query := `UPDATE notifications SET is_read = true WHERE user_id = $1 AND user_type = 'job_seeker' AND is_read = false`
-	_, err := config.DB.Exec(ctx, query, seekerID)
+// UpdateNotificationStatus marks all unread notifications of a user as read
+func UpdateNotificationStatus(ctx context.Context, userType string, userID int) error {
+	query := `UPDATE notifications SET is_read = true WHERE user_id = $1 AND user_type = $2 AND is_read = false`
+	_, err := config.DB.Exec(ctx, query, userID, userType)
 	return err
 }
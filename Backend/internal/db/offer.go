@@ -0,0 +1,419 @@
+package db
+
+import (
+	"Backend/config"
+	"Backend/internal/schema"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrOfferNotFound     = errors.New("offer not found")
+	ErrOfferNotAllowed   = errors.New("offers can only be made on accepted applications")
+	ErrOfferExists       = errors.New("application already has an open offer")
+	ErrOfferStatus       = errors.New("offer cannot be changed in its current status")
+	ErrOfferExpired      = errors.New("offer has expired")
+	ErrOfferExpiryPassed = errors.New("offer expiry must be in the future")
+)
+
+const offerColumns = `
+	o.id, o.application_id, o.employer_id, o.salary, o.currency, o.start_date, o.expires_at,
+	o.notes, o.letter_file_name, o.letter_path, o.status, o.decline_reason,
+	o.created_at, o.sent_at, o.responded_at, a.job_listing_id, j.job_title, a.job_seeker_id`
+
+const offerFrom = `
+	FROM offers o
+	JOIN applications a ON o.application_id = a.id
+	JOIN job_listings j ON a.job_listing_id = j.id`
+
+func scanOffer(row pgx.Row) (schema.Offer, error) {
+	var offer schema.Offer
+	err := row.Scan(&offer.ID, &offer.ApplicationID, &offer.EmployerID, &offer.Salary, &offer.Currency,
+		&offer.StartDate, &offer.ExpiresAt, &offer.Notes, &offer.LetterFileName, &offer.LetterPath,
+		&offer.Status, &offer.DeclineReason, &offer.CreatedAt, &offer.SentAt, &offer.RespondedAt,
+		&offer.JobListingID, &offer.JobTitle, &offer.JobSeekerID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return offer, ErrOfferNotFound
+	}
+	return offer, err
+}
+
+func getOffer(ctx context.Context, q querier, offerID int) (schema.Offer, error) {
+	return scanOffer(q.QueryRow(ctx, `SELECT `+offerColumns+offerFrom+` WHERE o.id = $1`, offerID))
+}
+
+func queryOffers(ctx context.Context, q querier, query string, args ...any) ([]schema.Offer, error) {
+	rows, err := q.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	offers := []schema.Offer{}
+	for rows.Next() {
+		offer, err := scanOffer(rows)
+		if err != nil {
+			return nil, err
+		}
+		offers = append(offers, offer)
+	}
+	return offers, rows.Err()
+}
+
+// GetOffer returns an offer with its application and job
+func GetOffer(ctx context.Context, offerID int) (schema.Offer, error) {
+	return getOffer(ctx, config.DB, offerID)
+}
+
+// GetApplicationOffers returns every offer made on an application, newest first
+func GetApplicationOffers(ctx context.Context, applicationID int) ([]schema.Offer, error) {
+	return queryOffers(ctx, config.DB, `SELECT `+offerColumns+offerFrom+`
+		WHERE o.application_id = $1
+		ORDER BY o.created_at DESC`, applicationID)
+}
+
+// GetSeekerOffers returns the offers sent to a job seeker. Drafts are not
+// visible to the candidate.
+func GetSeekerOffers(ctx context.Context, jobSeekerID int) ([]schema.Offer, error) {
+	return queryOffers(ctx, config.DB, `SELECT `+offerColumns+offerFrom+`
+		WHERE a.job_seeker_id = $1 AND o.status <> 'Draft'
+		ORDER BY o.sent_at DESC`, jobSeekerID)
+}
+
+// CreateOffer stores a draft offer on an accepted application and sends it
+// straight away when send is true. announce, if given, builds the
+// notifications about the new offer, which are stored in the same transaction.
+func CreateOffer(ctx context.Context, offer schema.Offer, send bool, announce func(schema.Offer) (schema.Outbox, error)) (schema.Offer, error) {
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		return offer, err
+	}
+	defer tx.Rollback(ctx)
+
+	var status string
+	err = tx.QueryRow(ctx, `SELECT application_status FROM applications WHERE id = $1 FOR SHARE`, offer.ApplicationID).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return offer, ErrApplicationNotFound
+	}
+	if err != nil {
+		return offer, err
+	}
+	if status != "Accepted" {
+		return offer, ErrOfferNotAllowed
+	}
+
+	query := `
+		INSERT INTO offers (application_id, employer_id, salary, currency, start_date, expires_at, notes, letter_file_name, letter_path)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id`
+	var offerID int
+	err = tx.QueryRow(ctx, query, offer.ApplicationID, offer.EmployerID, offer.Salary, offer.Currency, offer.StartDate,
+		offer.ExpiresAt, offer.Notes, offer.LetterFileName, offer.LetterPath).Scan(&offerID)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return offer, ErrOfferExists
+	}
+	if err != nil {
+		return offer, err
+	}
+
+	if send {
+		if err = sendOffer(ctx, tx, offerID, offer.EmployerID); err != nil {
+			return offer, err
+		}
+	}
+
+	created, err := getOffer(ctx, tx, offerID)
+	if err != nil {
+		return offer, err
+	}
+	if err = announceChange(ctx, tx, announce, created); err != nil {
+		return offer, err
+	}
+	return created, tx.Commit(ctx)
+}
+
+// SendOffer sends a draft offer to the candidate. announce builds the
+// notifications about it, which are stored in the same transaction.
+func SendOffer(ctx context.Context, offerID, employerID int, announce func(schema.Offer) (schema.Outbox, error)) (schema.Offer, error) {
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		return schema.Offer{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err = sendOffer(ctx, tx, offerID, &employerID); err != nil {
+		return schema.Offer{}, err
+	}
+	offer, err := getOffer(ctx, tx, offerID)
+	if err != nil {
+		return offer, err
+	}
+	if err = announceChange(ctx, tx, announce, offer); err != nil {
+		return offer, err
+	}
+	return offer, tx.Commit(ctx)
+}
+
+func sendOffer(ctx context.Context, tx querier, offerID int, employerID *int) error {
+	var status string
+	var expiresAt time.Time
+	var applicationID int
+	err := tx.QueryRow(ctx, `SELECT status, expires_at, application_id FROM offers WHERE id = $1 FOR UPDATE`, offerID).
+		Scan(&status, &expiresAt, &applicationID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrOfferNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if status != schema.OfferDraft {
+		return ErrOfferStatus
+	}
+	if !expiresAt.After(time.Now()) {
+		return ErrOfferExpiryPassed
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE offers SET status = 'Sent', sent_at = NOW() WHERE id = $1`, offerID)
+	if err != nil {
+		return err
+	}
+
+	return recordApplicationEvent(ctx, tx, schema.ApplicationEvent{
+		ApplicationID: applicationID,
+		EventType:     schema.EventOfferSent,
+		ActorType:     "employer",
+		ActorID:       employerID,
+	})
+}
+
+// WithdrawOffer takes back a draft or sent offer that has not been answered.
+// announce, if given, builds the notifications about it, which are stored in
+// the same transaction.
+func WithdrawOffer(ctx context.Context, offerID int, announce func(schema.Offer) (schema.Outbox, error)) (schema.Offer, error) {
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		return schema.Offer{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE offers SET status = 'Withdrawn', responded_at = NOW()
+		WHERE id = $1 AND status IN ('Draft', 'Sent')`, offerID)
+	if err != nil {
+		return schema.Offer{}, err
+	}
+
+	offer, err := getOffer(ctx, tx, offerID)
+	if err != nil {
+		return offer, err
+	}
+	if tag.RowsAffected() == 0 {
+		return offer, ErrOfferStatus
+	}
+	if err = announceChange(ctx, tx, announce, offer); err != nil {
+		return offer, err
+	}
+	return offer, tx.Commit(ctx)
+}
+
+// RespondToOffer records the candidate's answer to a sent offer. An offer
+// answered after its expiry is marked expired and ErrOfferExpired returned.
+// filled reports whether accepting it filled the job's last opening.
+// announce, if given, builds the notifications about the answer, which are
+// stored in the same transaction.
+func RespondToOffer(ctx context.Context, offerID, jobSeekerID int, accept bool, reason *string,
+	announce func(offer schema.Offer, filled bool) (schema.Outbox, error)) (offer schema.Offer, filled bool, err error) {
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		return offer, false, err
+	}
+	defer tx.Rollback(ctx)
+
+	offer, err = getOffer(ctx, tx, offerID)
+	if err != nil {
+		return offer, false, err
+	}
+	if offer.JobSeekerID != jobSeekerID {
+		return offer, false, ErrNotApplicationOwner
+	}
+
+	var status string
+	var expiresAt time.Time
+	err = tx.QueryRow(ctx, `SELECT status, expires_at FROM offers WHERE id = $1 FOR UPDATE`, offerID).Scan(&status, &expiresAt)
+	if err != nil {
+		return offer, false, err
+	}
+	if status != schema.OfferSent {
+		return offer, false, ErrOfferStatus
+	}
+	if !expiresAt.After(time.Now()) {
+		if _, err = tx.Exec(ctx, `UPDATE offers SET status = 'Expired' WHERE id = $1`, offerID); err != nil {
+			return offer, false, err
+		}
+		if err = tx.Commit(ctx); err != nil {
+			return offer, false, err
+		}
+		return offer, false, ErrOfferExpired
+	}
+
+	newStatus, eventType := schema.OfferDeclined, schema.EventOfferDeclined
+	if accept {
+		newStatus, eventType = schema.OfferAccepted, schema.EventOfferAccepted
+		reason = nil
+	}
+	_, err = tx.Exec(ctx, `UPDATE offers SET status = $1, decline_reason = $2, responded_at = NOW() WHERE id = $3`,
+		newStatus, reason, offerID)
+	if err != nil {
+		return offer, false, err
+	}
+
+	err = recordApplicationEvent(ctx, tx, schema.ApplicationEvent{
+		ApplicationID: offer.ApplicationID,
+		EventType:     eventType,
+		ActorType:     "job_seeker",
+		ActorID:       &jobSeekerID,
+		Reason:        reason,
+	})
+	if err != nil {
+		return offer, false, err
+	}
+
+	if accept {
+		if filled, err = fillJobIfComplete(ctx, tx, offer.JobListingID); err != nil {
+			return offer, false, err
+		}
+	}
+
+	if offer, err = getOffer(ctx, tx, offerID); err != nil {
+		return offer, false, err
+	}
+	if announce != nil {
+		outbox, err := announce(offer, filled)
+		if err != nil {
+			return offer, false, err
+		}
+		if err = storeOutbox(ctx, tx, outbox); err != nil {
+			return offer, false, err
+		}
+	}
+	return offer, filled, tx.Commit(ctx)
+}
+
+// fillJobIfComplete marks a job Filled once accepted offers cover all its
+// openings, if the job is set up to do so
+func fillJobIfComplete(ctx context.Context, tx querier, jobListingID int) (bool, error) {
+	settings, err := getOfferSettings(ctx, tx, jobListingID)
+	if err != nil || !settings.FillOnAccepted {
+		return false, err
+	}
+
+	// Lock the job so concurrent acceptances see each other's offers
+	var jobStatus string
+	err = tx.QueryRow(ctx, `SELECT status FROM job_listings WHERE id = $1 FOR UPDATE`, jobListingID).Scan(&jobStatus)
+	if err != nil {
+		return false, err
+	}
+
+	var accepted int
+	query := `
+		SELECT COUNT(*)
+		FROM offers o
+		JOIN applications a ON o.application_id = a.id
+		WHERE a.job_listing_id = $1 AND o.status = 'Accepted'`
+	if err = tx.QueryRow(ctx, query, jobListingID).Scan(&accepted); err != nil {
+		return false, err
+	}
+	if accepted < settings.Openings || jobStatus == "Filled" {
+		return false, nil
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE job_listings SET status = 'Filled' WHERE id = $1`, jobListingID)
+	return err == nil, err
+}
+
+// GetOfferSettings returns a job's openings and fill setting. Jobs without
+// stored settings have one opening and are never filled automatically.
+func GetOfferSettings(ctx context.Context, jobListingID int) (schema.OfferSettings, error) {
+	return getOfferSettings(ctx, config.DB, jobListingID)
+}
+
+func getOfferSettings(ctx context.Context, q querier, jobListingID int) (schema.OfferSettings, error) {
+	settings := schema.OfferSettings{JobListingID: jobListingID, Openings: 1}
+	query := `SELECT openings, fill_on_accepted FROM job_listing_settings WHERE job_listing_id = $1`
+	err := q.QueryRow(ctx, query, jobListingID).Scan(&settings.Openings, &settings.FillOnAccepted)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return settings, nil
+	}
+	return settings, err
+}
+
+// SetOfferSettings stores a job's openings and fill setting
+func SetOfferSettings(ctx context.Context, settings schema.OfferSettings) error {
+	query := `
+		INSERT INTO job_listing_settings (job_listing_id, openings, fill_on_accepted)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (job_listing_id)
+		DO UPDATE SET openings = EXCLUDED.openings, fill_on_accepted = EXCLUDED.fill_on_accepted`
+	_, err := config.DB.Exec(ctx, query, settings.JobListingID, settings.Openings, settings.FillOnAccepted)
+	return err
+}
+
+// ExpireOffers marks sent offers past their expiry as expired and returns
+// them. announce builds the notifications about each, which are stored in the
+// same transaction.
+func ExpireOffers(ctx context.Context, announce func(schema.Offer) (schema.Outbox, error)) ([]schema.Offer, error) {
+	return claimOffers(ctx, announce, `
+		WITH expired AS (
+			UPDATE offers SET status = 'Expired'
+			WHERE status = 'Sent' AND expires_at <= NOW()
+			RETURNING *
+		)
+		SELECT `+offerColumns+`
+		FROM expired o
+		JOIN applications a ON o.application_id = a.id
+		JOIN job_listings j ON a.job_listing_id = j.id`)
+}
+
+// ClaimOfferReminders returns the sent offers expiring within the window
+// that have not been reminded yet, and marks them as reminded so each offer
+// is reminded once even with several workers running. announce builds the
+// reminders, which are stored in the same transaction.
+func ClaimOfferReminders(ctx context.Context, window time.Duration, announce func(schema.Offer) (schema.Outbox, error)) ([]schema.Offer, error) {
+	return claimOffers(ctx, announce, `
+		WITH claimed AS (
+			UPDATE offers SET reminder_sent_at = NOW()
+			WHERE status = 'Sent' AND reminder_sent_at IS NULL
+			  AND expires_at > NOW() AND expires_at <= NOW() + $1::INTERVAL
+			RETURNING *
+		)
+		SELECT `+offerColumns+`
+		FROM claimed o
+		JOIN applications a ON o.application_id = a.id
+		JOIN job_listings j ON a.job_listing_id = j.id`, window)
+}
+
+// claimOffers runs an update that returns offers and announces each of them
+// in the same transaction
+func claimOffers(ctx context.Context, announce func(schema.Offer) (schema.Outbox, error), query string, args ...any) ([]schema.Offer, error) {
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	offers, err := queryOffers(ctx, tx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	for _, offer := range offers {
+		if err := announceChange(ctx, tx, announce, offer); err != nil {
+			return nil, err
+		}
+	}
+	return offers, tx.Commit(ctx)
+}
@@ -0,0 +1,176 @@
+package db
+
+import (
+	"Backend/config"
+	"Backend/internal/schema"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrOutboxMessageNotFound = errors.New("outbox message not found")
+	ErrOutboxMessageSent     = errors.New("outbox message has already been sent")
+	ErrOutboxMessageQueued   = errors.New("outbox message is being delivered or waiting for its next attempt")
+)
+
+const outboxColumns = `
+	id, channel, event, user_id, user_type, payload, status, attempts,
+	next_attempt_at, last_error, created_at, sent_at`
+
+func scanOutboxMessage(row pgx.Row) (schema.OutboxMessage, error) {
+	var message schema.OutboxMessage
+	err := row.Scan(&message.ID, &message.Channel, &message.Event, &message.UserID, &message.UserType,
+		&message.Payload, &message.Status, &message.Attempts, &message.NextAttemptAt,
+		&message.LastError, &message.CreatedAt, &message.SentAt)
+	return message, err
+}
+
+func queryOutboxMessages(ctx context.Context, query string, args ...any) ([]schema.OutboxMessage, error) {
+	rows, err := config.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := []schema.OutboxMessage{}
+	for rows.Next() {
+		message, err := scanOutboxMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+	return messages, rows.Err()
+}
+
+// announceChange stores what announce builds for a change inside the
+// change's transaction, so the notifications only exist if the change is
+// committed and a failure to store them rolls the change back. A nil
+// announce stores nothing.
+func announceChange[T any](ctx context.Context, q querier, announce func(T) (schema.Outbox, error), change T) error {
+	if announce == nil {
+		return nil
+	}
+	outbox, err := announce(change)
+	if err != nil {
+		return err
+	}
+	return storeOutbox(ctx, q, outbox)
+}
+
+// storeOutbox stores notifications, outbox messages and scheduled
+// notifications inside the caller's transaction
+func storeOutbox(ctx context.Context, q querier, outbox schema.Outbox) error {
+	query := `
+		INSERT INTO notifications (id, user_id, user_type, message, is_read)
+		VALUES (generate_notification_id(), $1, $2, $3, $4)`
+	for _, notification := range outbox.Notifications {
+		_, err := q.Exec(ctx, query, notification.UserID, notification.UserType, notification.Message, notification.IsRead)
+		if err != nil {
+			return err
+		}
+	}
+
+	query = `
+		INSERT INTO outbox_messages (channel, event, user_id, user_type, payload)
+		VALUES ($1, $2, $3, $4, $5)`
+	for _, message := range outbox.Messages {
+		_, err := q.Exec(ctx, query, message.Channel, message.Event, message.UserID, message.UserType, message.Payload)
+		if err != nil {
+			return err
+		}
+	}
+	return scheduleNotifications(ctx, q, outbox.Scheduled)
+}
+
+// ClaimOutboxMessages returns up to limit pending messages that are due and
+// counts the attempt. Their next attempt is pushed back by lease, so a
+// message whose delivery never reports back is picked up again. Rows locked
+// by another worker are skipped.
+func ClaimOutboxMessages(ctx context.Context, limit int, lease time.Duration) ([]schema.OutboxMessage, error) {
+	query := `
+		WITH due AS (
+			SELECT id FROM outbox_messages
+			WHERE status = 'Pending' AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE outbox_messages o
+		SET attempts = o.attempts + 1, next_attempt_at = NOW() + $2::INTERVAL
+		FROM due
+		WHERE o.id = due.id
+		RETURNING o.id, o.channel, o.event, o.user_id, o.user_type, o.payload, o.status, o.attempts,
+		          o.next_attempt_at, o.last_error, o.created_at, o.sent_at`
+	return queryOutboxMessages(ctx, query, limit, lease)
+}
+
+// MarkOutboxMessageSent records a successful delivery
+func MarkOutboxMessageSent(ctx context.Context, id int) error {
+	query := `
+		UPDATE outbox_messages SET status = 'Sent', sent_at = NOW(), last_error = NULL
+		WHERE id = $1`
+	_, err := config.DB.Exec(ctx, query, id)
+	return err
+}
+
+// FailOutboxMessage records a failed delivery. The message is tried again at
+// retryAt, or moved to the dead letters when retryAt is nil.
+func FailOutboxMessage(ctx context.Context, id int, lastError string, retryAt *time.Time) error {
+	query := `
+		UPDATE outbox_messages
+		SET last_error = $2,
+		    status = CASE WHEN $3::timestamptz IS NULL THEN 'Dead' ELSE 'Pending' END,
+		    next_attempt_at = COALESCE($3, next_attempt_at)
+		WHERE id = $1`
+	_, err := config.DB.Exec(ctx, query, id, lastError, retryAt)
+	return err
+}
+
+// GetOutboxMessages returns the newest outbox messages, optionally of one status
+func GetOutboxMessages(ctx context.Context, status string, limit int) ([]schema.OutboxMessage, error) {
+	query := `
+		SELECT` + outboxColumns + `
+		FROM outbox_messages
+		WHERE $1 = '' OR status = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2`
+	return queryOutboxMessages(ctx, query, status, limit)
+}
+
+// RetryOutboxMessage puts a dead message, or a pending one whose lease has
+// run out, back in the queue to be delivered right away, with a fresh set of
+// attempts. A pending message that a worker has claimed, or that is waiting
+// for its next attempt, is left alone so it is not sent twice;
+// ErrOutboxMessageQueued is returned then.
+func RetryOutboxMessage(ctx context.Context, id int) (schema.OutboxMessage, error) {
+	query := `
+		UPDATE outbox_messages
+		SET status = 'Pending', attempts = 0, next_attempt_at = NOW()
+		WHERE id = $1 AND (status = 'Dead' OR (status = 'Pending' AND next_attempt_at <= NOW()))
+		RETURNING` + outboxColumns
+	message, err := scanOutboxMessage(config.DB.QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		var status string
+		err := config.DB.QueryRow(ctx, `SELECT status FROM outbox_messages WHERE id = $1`, id).Scan(&status)
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return schema.OutboxMessage{}, ErrOutboxMessageNotFound
+		case err != nil:
+			return schema.OutboxMessage{}, err
+		case status == schema.OutboxSent:
+			return schema.OutboxMessage{}, ErrOutboxMessageSent
+		}
+		return schema.OutboxMessage{}, ErrOutboxMessageQueued
+	}
+	return message, err
+}
+
+// PurgeSentOutboxMessages removes messages delivered more than ttl ago
+func PurgeSentOutboxMessages(ctx context.Context, ttl time.Duration) (int64, error) {
+	tag, err := config.DB.Exec(ctx, `DELETE FROM outbox_messages WHERE status = 'Sent' AND sent_at < NOW() - $1::INTERVAL`, ttl)
+	return tag.RowsAffected(), err
+}
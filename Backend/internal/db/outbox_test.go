@@ -0,0 +1,68 @@
+package db
+
+import (
+	"Backend/config"
+	"Backend/internal/dbtest"
+	"Backend/internal/schema"
+	"context"
+	"errors"
+	"testing"
+)
+
+// addOutboxMessage stores a message that has been attempted and left in status
+func addOutboxMessage(t *testing.T, status string, attempts int) int {
+	t.Helper()
+	query := `
+		INSERT INTO outbox_messages (channel, event, user_id, user_type, payload, status, attempts, last_error)
+		VALUES ('email', 'interview_scheduled', 1, 'job_seeker', '{"message": "hi"}', $1, $2, 'mail server down')
+		RETURNING id`
+	var id int
+	if err := config.DB.QueryRow(context.Background(), query, status, attempts).Scan(&id); err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func TestRetryOutboxMessage(t *testing.T) {
+	dbtest.Require(t)
+	ctx := context.Background()
+
+	dead := addOutboxMessage(t, schema.OutboxDead, 10)
+	message, err := RetryOutboxMessage(ctx, dead)
+	if err != nil {
+		t.Fatalf("retry dead message: %v", err)
+	}
+	if message.Status != schema.OutboxPending || message.Attempts != 0 {
+		t.Errorf("retried message is %s after %d attempts, want Pending after 0", message.Status, message.Attempts)
+	}
+
+	sent := addOutboxMessage(t, schema.OutboxSent, 1)
+	if _, err := RetryOutboxMessage(ctx, sent); !errors.Is(err, ErrOutboxMessageSent) {
+		t.Errorf("retry sent message: err = %v, want ErrOutboxMessageSent", err)
+	}
+
+	// A claimed message is leased to a worker until its next attempt
+	leased := addOutboxMessage(t, schema.OutboxPending, 1)
+	if _, err := config.DB.Exec(ctx, `UPDATE outbox_messages SET next_attempt_at = NOW() + INTERVAL '5 minutes' WHERE id = $1`, leased); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RetryOutboxMessage(ctx, leased); !errors.Is(err, ErrOutboxMessageQueued) {
+		t.Errorf("retry leased message: err = %v, want ErrOutboxMessageQueued", err)
+	}
+
+	expired := addOutboxMessage(t, schema.OutboxPending, 3)
+	if _, err := config.DB.Exec(ctx, `UPDATE outbox_messages SET next_attempt_at = NOW() - INTERVAL '1 minute' WHERE id = $1`, expired); err != nil {
+		t.Fatal(err)
+	}
+	message, err = RetryOutboxMessage(ctx, expired)
+	if err != nil {
+		t.Fatalf("retry message with an expired lease: %v", err)
+	}
+	if message.Attempts != 0 {
+		t.Errorf("retried message has %d attempts, want 0", message.Attempts)
+	}
+
+	if _, err := RetryOutboxMessage(ctx, sent+1000); !errors.Is(err, ErrOutboxMessageNotFound) {
+		t.Errorf("retry missing message: err = %v, want ErrOutboxMessageNotFound", err)
+	}
+}
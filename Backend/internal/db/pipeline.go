@@ -0,0 +1,308 @@
+package db
+
+import (
+	"Backend/config"
+	"Backend/internal/schema"
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrNoPipeline       = errors.New("no pipeline stages configured for this job")
+	ErrStageNotInJob    = errors.New("stage does not belong to this job's pipeline")
+	ErrAlreadyInStage   = errors.New("application is already in this stage")
+	ErrApplicationFinal = errors.New("application has already been decided")
+	ErrStageInUse       = errors.New("stage still has applications and cannot be removed")
+)
+
+// GetPipelineStages returns the effective pipeline for a job: the job's own
+// stages when it has any, otherwise the stages of the employer's company.
+func GetPipelineStages(ctx context.Context, jobListingID int) ([]schema.PipelineStage, error) {
+	return getPipelineStages(ctx, config.DB, jobListingID)
+}
+
+func getPipelineStages(ctx context.Context, q querier, jobListingID int) ([]schema.PipelineStage, error) {
+	jobQuery := `
+		SELECT id, company_id, job_listing_id, stage_name, position, mapped_status
+		FROM pipeline_stages
+		WHERE job_listing_id = $1
+		ORDER BY position, id`
+	stages, err := scanPipelineStages(ctx, q, jobQuery, jobListingID)
+	if err != nil || len(stages) > 0 {
+		return stages, err
+	}
+
+	companyQuery := `
+		SELECT s.id, s.company_id, s.job_listing_id, s.stage_name, s.position, s.mapped_status
+		FROM pipeline_stages s
+		JOIN employers e ON e.companyid = s.company_id
+		JOIN job_listings j ON j.employer_id = e.id
+		WHERE j.id = $1
+		ORDER BY s.position, s.id`
+	return scanPipelineStages(ctx, q, companyQuery, jobListingID)
+}
+
+// GetCompanyPipelineStages returns the default stages defined for a company
+func GetCompanyPipelineStages(ctx context.Context, companyID int) ([]schema.PipelineStage, error) {
+	query := `
+		SELECT id, company_id, job_listing_id, stage_name, position, mapped_status
+		FROM pipeline_stages
+		WHERE company_id = $1
+		ORDER BY position, id`
+	return scanPipelineStages(ctx, config.DB, query, companyID)
+}
+
+func scanPipelineStages(ctx context.Context, q querier, query string, args ...any) ([]schema.PipelineStage, error) {
+	rows, err := q.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stages []schema.PipelineStage
+	for rows.Next() {
+		var stage schema.PipelineStage
+		if err := rows.Scan(&stage.ID, &stage.CompanyID, &stage.JobListingID, &stage.StageName, &stage.Position, &stage.MappedStatus); err != nil {
+			return nil, err
+		}
+		stages = append(stages, stage)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return stages, nil
+}
+
+// SetCompanyPipelineStages replaces the ordered stages of a company's default pipeline
+func SetCompanyPipelineStages(ctx context.Context, companyID int, stages []schema.PipelineStage) ([]schema.PipelineStage, error) {
+	return setPipelineStages(ctx, "company_id", companyID, stages)
+}
+
+// SetJobPipelineStages replaces the ordered stages of a job-specific pipeline
+func SetJobPipelineStages(ctx context.Context, jobListingID int, stages []schema.PipelineStage) ([]schema.PipelineStage, error) {
+	return setPipelineStages(ctx, "job_listing_id", jobListingID, stages)
+}
+
+// setPipelineStages updates stages that are passed with an ID, inserts the
+// others and removes stages that were left out. Stages that still hold
+// applications cannot be removed. Positions follow the order of the slice.
+func setPipelineStages(ctx context.Context, ownerColumn string, ownerID int, stages []schema.PipelineStage) ([]schema.PipelineStage, error) {
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	keep := make([]int, 0, len(stages))
+	for i, stage := range stages {
+		if stage.ID == 0 {
+			continue
+		}
+		query := fmt.Sprintf(`UPDATE pipeline_stages SET stage_name = $1, position = $2, mapped_status = $3
+			WHERE id = $4 AND %s = $5`, ownerColumn)
+		tag, err := tx.Exec(ctx, query, stage.StageName, i+1, stage.MappedStatus, stage.ID, ownerID)
+		if err != nil {
+			return nil, err
+		}
+		if tag.RowsAffected() == 0 {
+			return nil, fmt.Errorf("stage %d does not belong to this pipeline", stage.ID)
+		}
+		keep = append(keep, stage.ID)
+	}
+
+	var inUse bool
+	inUseQuery := fmt.Sprintf(`
+		SELECT EXISTS (
+			SELECT 1 FROM applications a
+			JOIN pipeline_stages s ON a.stage_id = s.id
+			WHERE s.%s = $1 AND NOT (s.id = ANY($2))
+		)`, ownerColumn)
+	if err := tx.QueryRow(ctx, inUseQuery, ownerID, keep).Scan(&inUse); err != nil {
+		return nil, err
+	}
+	if inUse {
+		return nil, ErrStageInUse
+	}
+
+	deleteQuery := fmt.Sprintf(`DELETE FROM pipeline_stages WHERE %s = $1 AND NOT (id = ANY($2))`, ownerColumn)
+	if _, err := tx.Exec(ctx, deleteQuery, ownerID, keep); err != nil {
+		return nil, err
+	}
+
+	insertQuery := fmt.Sprintf(`INSERT INTO pipeline_stages (%s, stage_name, position, mapped_status) VALUES ($1, $2, $3, $4)`, ownerColumn)
+	for i, stage := range stages {
+		if stage.ID != 0 {
+			continue
+		}
+		if _, err := tx.Exec(ctx, insertQuery, ownerID, stage.StageName, i+1, stage.MappedStatus); err != nil {
+			return nil, err
+		}
+	}
+
+	selectQuery := fmt.Sprintf(`
+		SELECT id, company_id, job_listing_id, stage_name, position, mapped_status
+		FROM pipeline_stages
+		WHERE %s = $1
+		ORDER BY position, id`, ownerColumn)
+	result, err := scanPipelineStages(ctx, tx, selectQuery, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, tx.Commit(ctx)
+}
+
+// MoveApplicationStage moves an application to another stage of its job's
+// pipeline and updates the seeker-facing status to the stage's mapped status.
+// announce, if given, builds the notifications about the move, which are
+// stored in the same transaction.
+func MoveApplicationStage(ctx context.Context, applicationID, stageID int, actor schema.Actor, reason *string,
+	announce func(schema.Application) (schema.Outbox, error)) (schema.Application, error) {
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		return schema.Application{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	updated, err := moveApplicationStage(ctx, tx, applicationID, stageID, actor, reason)
+	if err != nil {
+		return schema.Application{}, err
+	}
+	if err = announceChange(ctx, tx, announce, updated); err != nil {
+		return schema.Application{}, err
+	}
+
+	return updated, tx.Commit(ctx)
+}
+
+// moveApplicationStage performs a stage move inside the caller's transaction
+func moveApplicationStage(ctx context.Context, tx querier, applicationID, stageID int, actor schema.Actor, reason *string) (schema.Application, error) {
+	var app schema.Application
+	lockQuery := `SELECT id, job_seeker_id, job_listing_id, application_status, applied_date, cover_letter, stage_id
+		FROM applications WHERE id = $1 FOR UPDATE`
+	err := tx.QueryRow(ctx, lockQuery, applicationID).Scan(
+		&app.ID, &app.JobSeekerID, &app.JobListingID, &app.ApplicationStatus,
+		&app.AppliedDate, &app.CoverLetter, &app.StageID,
+	)
+	if err != nil {
+		return schema.Application{}, err
+	}
+
+	if app.ApplicationStatus == "Rejected" || app.ApplicationStatus == "Accepted" {
+		return schema.Application{}, ErrApplicationFinal
+	}
+	if app.ApplicationStatus == "Withdrawn" {
+		return schema.Application{}, ErrApplicationWithdrawn
+	}
+	if app.StageID != nil && *app.StageID == stageID {
+		return schema.Application{}, ErrAlreadyInStage
+	}
+
+	stages, err := getPipelineStages(ctx, tx, app.JobListingID)
+	if err != nil {
+		return schema.Application{}, err
+	}
+	if len(stages) == 0 {
+		return schema.Application{}, ErrNoPipeline
+	}
+
+	var target *schema.PipelineStage
+	for i := range stages {
+		if stages[i].ID == stageID {
+			target = &stages[i]
+			break
+		}
+	}
+	if target == nil {
+		return schema.Application{}, ErrStageNotInJob
+	}
+
+	updateQuery := `UPDATE applications SET stage_id = $1, application_status = $2 WHERE id = $3
+		RETURNING id, job_seeker_id, job_listing_id, application_status, applied_date, cover_letter, stage_id`
+	var updated schema.Application
+	err = tx.QueryRow(ctx, updateQuery, target.ID, target.MappedStatus, applicationID).Scan(
+		&updated.ID, &updated.JobSeekerID, &updated.JobListingID, &updated.ApplicationStatus,
+		&updated.AppliedDate, &updated.CoverLetter, &updated.StageID,
+	)
+	if err != nil {
+		return schema.Application{}, err
+	}
+
+	err = recordApplicationEvent(ctx, tx, schema.ApplicationEvent{
+		ApplicationID: applicationID,
+		EventType:     schema.EventStageChanged,
+		FromStatus:    &app.ApplicationStatus,
+		ToStatus:      &updated.ApplicationStatus,
+		StageID:       &target.ID,
+		StageName:     &target.StageName,
+		ActorType:     actor.Type,
+		ActorID:       actor.ID,
+		Reason:        reason,
+	})
+	if err != nil {
+		return schema.Application{}, err
+	}
+
+	if err = revealIdentityIfDue(ctx, tx, updated, actor); err != nil {
+		return schema.Application{}, err
+	}
+
+	return updated, nil
+}
+
+// GetStageCounts returns how many applications of a job sit in each stage of
+// its pipeline, followed by the applications that have no stage yet.
+func GetStageCounts(ctx context.Context, jobListingID int) ([]schema.StageCount, error) {
+	stages, err := GetPipelineStages(ctx, jobListingID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT stage_id, COUNT(*)
+		FROM applications
+		WHERE job_listing_id = $1
+		GROUP BY stage_id`
+	rows, err := config.DB.Query(ctx, query, jobListingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	countByStage := make(map[int]int)
+	unstaged := 0
+	for rows.Next() {
+		var stageID *int
+		var count int
+		if err := rows.Scan(&stageID, &count); err != nil {
+			return nil, err
+		}
+		if stageID == nil {
+			unstaged = count
+			continue
+		}
+		countByStage[*stageID] = count
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	counts := make([]schema.StageCount, 0, len(stages)+1)
+	for _, stage := range stages {
+		id := stage.ID
+		counts = append(counts, schema.StageCount{
+			StageID:      &id,
+			StageName:    stage.StageName,
+			MappedStatus: stage.MappedStatus,
+			Count:        countByStage[stage.ID],
+		})
+	}
+	counts = append(counts, schema.StageCount{
+		StageName: "Unassigned",
+		Count:     unstaged,
+	})
+
+	return counts, nil
+}
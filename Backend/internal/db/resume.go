@@ -0,0 +1,195 @@
+package db
+
+import (
+	"Backend/config"
+	"Backend/internal/schema"
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrResumeNotFound = errors.New("resume not found")
+	ErrResumeNotOwned = errors.New("resume does not belong to this job seeker")
+)
+
+const resumeColumns = `id, job_seeker_id, version, file_name, file_path, mime_type, size_bytes, is_default, uploaded_at`
+
+func scanResume(row pgx.Row) (schema.Resume, error) {
+	var resume schema.Resume
+	err := row.Scan(&resume.ID, &resume.JobSeekerID, &resume.Version, &resume.FileName, &resume.FilePath,
+		&resume.MimeType, &resume.SizeBytes, &resume.IsDefault, &resume.UploadedAt)
+	return resume, err
+}
+
+// CreateResume stores a new resume version for a job seeker. The first
+// resume, or one uploaded with makeDefault, becomes the default.
+func CreateResume(ctx context.Context, resume schema.Resume, makeDefault bool) (schema.Resume, error) {
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		return schema.Resume{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the seeker so concurrent uploads get distinct version numbers
+	_, err = tx.Exec(ctx, `SELECT 1 FROM job_seekers WHERE id = $1 FOR UPDATE`, resume.JobSeekerID)
+	if err != nil {
+		return schema.Resume{}, err
+	}
+
+	var hasDefault bool
+	err = tx.QueryRow(ctx, `
+		SELECT COALESCE(MAX(version), 0) + 1, COALESCE(BOOL_OR(is_default), FALSE)
+		FROM resumes WHERE job_seeker_id = $1`, resume.JobSeekerID).Scan(&resume.Version, &hasDefault)
+	if err != nil {
+		return schema.Resume{}, err
+	}
+
+	query := `
+		INSERT INTO resumes (job_seeker_id, version, file_name, file_path, mime_type, size_bytes)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + resumeColumns
+	resume, err = scanResume(tx.QueryRow(ctx, query, resume.JobSeekerID, resume.Version, resume.FileName,
+		resume.FilePath, resume.MimeType, resume.SizeBytes))
+	if err != nil {
+		return schema.Resume{}, err
+	}
+
+	if makeDefault || !hasDefault {
+		if err = setDefaultResume(ctx, tx, resume.JobSeekerID, resume.ID); err != nil {
+			return schema.Resume{}, err
+		}
+		resume.IsDefault = true
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return schema.Resume{}, err
+	}
+	return resume, nil
+}
+
+// GetResumes returns all resume versions of a job seeker, newest first
+func GetResumes(ctx context.Context, jobSeekerID int) ([]schema.Resume, error) {
+	rows, err := config.DB.Query(ctx, `SELECT `+resumeColumns+` FROM resumes WHERE job_seeker_id = $1 ORDER BY version DESC`, jobSeekerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	resumes := []schema.Resume{}
+	for rows.Next() {
+		resume, err := scanResume(rows)
+		if err != nil {
+			return nil, err
+		}
+		resumes = append(resumes, resume)
+	}
+	return resumes, rows.Err()
+}
+
+// GetResume returns a single resume version
+func GetResume(ctx context.Context, resumeID int) (schema.Resume, error) {
+	resume, err := scanResume(config.DB.QueryRow(ctx, `SELECT `+resumeColumns+` FROM resumes WHERE id = $1`, resumeID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return schema.Resume{}, ErrResumeNotFound
+	}
+	return resume, err
+}
+
+// SetDefaultResume marks one of a job seeker's resumes as the default
+func SetDefaultResume(ctx context.Context, jobSeekerID, resumeID int) error {
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err = setDefaultResume(ctx, tx, jobSeekerID, resumeID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// setDefaultResume moves the default flag to the given resume and points the
+// seeker's profile resume at its file
+func setDefaultResume(ctx context.Context, q querier, jobSeekerID, resumeID int) error {
+	if err := checkResumeOwner(ctx, q, jobSeekerID, resumeID); err != nil {
+		return err
+	}
+
+	_, err := q.Exec(ctx, `UPDATE resumes SET is_default = FALSE WHERE job_seeker_id = $1 AND is_default`, jobSeekerID)
+	if err != nil {
+		return err
+	}
+	_, err = q.Exec(ctx, `UPDATE resumes SET is_default = TRUE WHERE id = $1`, resumeID)
+	if err != nil {
+		return err
+	}
+	_, err = q.Exec(ctx, `UPDATE job_seekers SET resume = (SELECT file_path FROM resumes WHERE id = $1) WHERE id = $2`, resumeID, jobSeekerID)
+	return err
+}
+
+func checkResumeOwner(ctx context.Context, q querier, jobSeekerID, resumeID int) error {
+	var ownerID int
+	err := q.QueryRow(ctx, `SELECT job_seeker_id FROM resumes WHERE id = $1`, resumeID).Scan(&ownerID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrResumeNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if ownerID != jobSeekerID {
+		return ErrResumeNotOwned
+	}
+	return nil
+}
+
+// attachResume links a resume to a new application. Without an explicit
+// choice the seeker's default resume (if any) is attached.
+func attachResume(ctx context.Context, q querier, applicationID, jobSeekerID int, resumeID *int) (*int, error) {
+	if resumeID == nil {
+		var defaultID int
+		err := q.QueryRow(ctx, `SELECT id FROM resumes WHERE job_seeker_id = $1 AND is_default`, jobSeekerID).Scan(&defaultID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		resumeID = &defaultID
+	} else if err := checkResumeOwner(ctx, q, jobSeekerID, *resumeID); err != nil {
+		return nil, err
+	}
+
+	_, err := q.Exec(ctx, `UPDATE applications SET resume_id = $1 WHERE id = $2`, *resumeID, applicationID)
+	if err != nil {
+		return nil, err
+	}
+	return resumeID, nil
+}
+
+// EmployerCanAccessResume reports whether a resume was attached to an
+// application to the employer's company. Applications made without a resume
+// choice expose the seeker's current default resume. On blind-reviewed jobs
+// the resume stays hidden, like the rest of the candidate's identity, until
+// the application is revealed.
+func EmployerCanAccessResume(ctx context.Context, employerID, resumeID int) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM resumes r
+			JOIN applications a ON a.job_seeker_id = r.job_seeker_id
+			JOIN job_listings j ON a.job_listing_id = j.id
+			JOIN employers owner ON j.employer_id = owner.id
+			JOIN employers e ON e.companyid = owner.companyid
+			LEFT JOIN job_listing_settings s ON s.job_listing_id = j.id
+			WHERE r.id = $1 AND e.id = $2
+			  AND (a.resume_id = r.id OR (a.resume_id IS NULL AND r.is_default))
+			  AND (NOT COALESCE(s.blind_review, FALSE)
+			       OR EXISTS (SELECT 1 FROM application_identity_reveals ir WHERE ir.application_id = a.id))
+		)`
+	var allowed bool
+	err := config.DB.QueryRow(ctx, query, resumeID, employerID).Scan(&allowed)
+	return allowed, err
+}
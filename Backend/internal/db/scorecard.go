@@ -0,0 +1,376 @@
+package db
+
+import (
+	"Backend/config"
+	"Backend/internal/schema"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrCompetencyNotInJob = errors.New("competency does not belong to this job's scorecard")
+	ErrCompetencyInUse    = errors.New("competency has been rated and cannot be removed or rescaled")
+	ErrNotOnPanel         = errors.New("only interviewers on the panel can give feedback on this interview")
+	ErrFeedbackNotOpen    = errors.New("feedback can only be given on interviews that have taken place")
+	ErrFeedbackSubmitted  = errors.New("feedback for this interview has already been submitted")
+	ErrInvalidRatings     = errors.New("every competency of the scorecard must be rated once, within its scale")
+)
+
+// GetScorecard returns the competencies of a job's scorecard in order
+func GetScorecard(ctx context.Context, jobListingID int) ([]schema.ScorecardCompetency, error) {
+	return getScorecard(ctx, config.DB, jobListingID)
+}
+
+func getScorecard(ctx context.Context, q querier, jobListingID int) ([]schema.ScorecardCompetency, error) {
+	query := `
+		SELECT id, job_listing_id, name, description, position, scale_max
+		FROM scorecard_competencies
+		WHERE job_listing_id = $1
+		ORDER BY position, id`
+	rows, err := q.Query(ctx, query, jobListingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	competencies := []schema.ScorecardCompetency{}
+	for rows.Next() {
+		var competency schema.ScorecardCompetency
+		err := rows.Scan(&competency.ID, &competency.JobListingID, &competency.Name, &competency.Description,
+			&competency.Position, &competency.ScaleMax)
+		if err != nil {
+			return nil, err
+		}
+		competencies = append(competencies, competency)
+	}
+	return competencies, rows.Err()
+}
+
+// SetScorecard replaces the scorecard of a job. Competencies passed with an
+// ID are updated, the others inserted, and competencies left out are removed.
+// Competencies that were already rated keep their scale and cannot be removed.
+func SetScorecard(ctx context.Context, jobListingID int, competencies []schema.ScorecardCompetency) ([]schema.ScorecardCompetency, error) {
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	keep := make([]int, 0, len(competencies))
+	for i, competency := range competencies {
+		if competency.ID == 0 {
+			continue
+		}
+
+		var scaleMax int
+		var rated bool
+		query := `
+			SELECT scale_max, EXISTS (SELECT 1 FROM interview_feedback_scores s WHERE s.competency_id = c.id)
+			FROM scorecard_competencies c
+			WHERE id = $1 AND job_listing_id = $2
+			FOR UPDATE`
+		err := tx.QueryRow(ctx, query, competency.ID, jobListingID).Scan(&scaleMax, &rated)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrCompetencyNotInJob
+		}
+		if err != nil {
+			return nil, err
+		}
+		if rated && scaleMax != competency.ScaleMax {
+			return nil, ErrCompetencyInUse
+		}
+
+		query = `UPDATE scorecard_competencies SET name = $1, description = $2, position = $3, scale_max = $4 WHERE id = $5`
+		if _, err := tx.Exec(ctx, query, competency.Name, competency.Description, i+1, competency.ScaleMax, competency.ID); err != nil {
+			return nil, err
+		}
+		keep = append(keep, competency.ID)
+	}
+
+	var inUse bool
+	inUseQuery := `
+		SELECT EXISTS (
+			SELECT 1 FROM interview_feedback_scores s
+			JOIN scorecard_competencies c ON s.competency_id = c.id
+			WHERE c.job_listing_id = $1 AND NOT (c.id = ANY($2))
+		)`
+	if err := tx.QueryRow(ctx, inUseQuery, jobListingID, keep).Scan(&inUse); err != nil {
+		return nil, err
+	}
+	if inUse {
+		return nil, ErrCompetencyInUse
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM scorecard_competencies WHERE job_listing_id = $1 AND NOT (id = ANY($2))`, jobListingID, keep)
+	if err != nil {
+		return nil, err
+	}
+
+	insertQuery := `INSERT INTO scorecard_competencies (job_listing_id, name, description, position, scale_max) VALUES ($1, $2, $3, $4, $5)`
+	for i, competency := range competencies {
+		if competency.ID != 0 {
+			continue
+		}
+		if _, err := tx.Exec(ctx, insertQuery, jobListingID, competency.Name, competency.Description, i+1, competency.ScaleMax); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := getScorecard(ctx, tx, jobListingID)
+	if err != nil {
+		return nil, err
+	}
+	return result, tx.Commit(ctx)
+}
+
+// SubmitFeedback stores an interviewer's scorecard for an interview they were
+// on. The interview must have started, and every competency of the job's
+// scorecard must be rated exactly once. Feedback cannot be changed afterwards.
+func SubmitFeedback(ctx context.Context, feedback schema.InterviewFeedback) (schema.InterviewFeedback, error) {
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		return schema.InterviewFeedback{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	var (
+		applicationID int
+		jobListingID  int
+		status        string
+		scheduledDate time.Time
+		roundName     *string
+		onPanel       bool
+	)
+	query := `
+		SELECT i.application_id, ia.job_listing_id, i.status, i.scheduled_date, r.name,
+		       EXISTS (SELECT 1 FROM interview_interviewers p WHERE p.interview_id = i.id AND p.employer_id = $2)
+		FROM interviews i
+		JOIN applications ia ON i.application_id = ia.id
+		LEFT JOIN interview_plan_rounds r ON i.round_id = r.id
+		WHERE i.id = $1`
+	err = tx.QueryRow(ctx, query, feedback.InterviewID, feedback.InterviewerID).
+		Scan(&applicationID, &jobListingID, &status, &scheduledDate, &roundName, &onPanel)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return schema.InterviewFeedback{}, ErrInterviewNotFound
+	}
+	if err != nil {
+		return schema.InterviewFeedback{}, err
+	}
+	if !onPanel {
+		return schema.InterviewFeedback{}, ErrNotOnPanel
+	}
+	if status == "Cancelled" || status == "No Show" || scheduledDate.After(time.Now()) {
+		return schema.InterviewFeedback{}, ErrFeedbackNotOpen
+	}
+
+	competencies, err := getScorecard(ctx, tx, jobListingID)
+	if err != nil {
+		return schema.InterviewFeedback{}, err
+	}
+	scales := make(map[int]int, len(competencies))
+	for _, competency := range competencies {
+		scales[competency.ID] = competency.ScaleMax
+	}
+	if len(feedback.Ratings) != len(competencies) {
+		return schema.InterviewFeedback{}, ErrInvalidRatings
+	}
+	for _, rating := range feedback.Ratings {
+		scaleMax, ok := scales[rating.CompetencyID]
+		if !ok || rating.Rating < 1 || rating.Rating > scaleMax {
+			return schema.InterviewFeedback{}, ErrInvalidRatings
+		}
+		// Deleting the entry rejects a competency that is rated twice
+		delete(scales, rating.CompetencyID)
+	}
+
+	query = `
+		INSERT INTO interview_feedback (interview_id, interviewer_id, recommendation, notes)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id`
+	err = tx.QueryRow(ctx, query, feedback.InterviewID, feedback.InterviewerID, feedback.Recommendation, feedback.Notes).Scan(&feedback.ID)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return schema.InterviewFeedback{}, ErrFeedbackSubmitted
+	}
+	if err != nil {
+		return schema.InterviewFeedback{}, err
+	}
+
+	scoreQuery := `INSERT INTO interview_feedback_scores (feedback_id, competency_id, rating, comment) VALUES ($1, $2, $3, $4)`
+	for _, rating := range feedback.Ratings {
+		if _, err := tx.Exec(ctx, scoreQuery, feedback.ID, rating.CompetencyID, rating.Rating, rating.Comment); err != nil {
+			return schema.InterviewFeedback{}, err
+		}
+	}
+
+	// The recommendation is left out of the history, which other interviewers can read
+	err = recordApplicationEvent(ctx, tx, schema.ApplicationEvent{
+		ApplicationID: applicationID,
+		EventType:     schema.EventFeedbackSubmitted,
+		ActorType:     "employer",
+		ActorID:       &feedback.InterviewerID,
+		Reason:        roundName,
+	})
+	if err != nil {
+		return schema.InterviewFeedback{}, err
+	}
+
+	submitted, err := queryFeedback(ctx, tx, `f.id = $1`, feedback.ID)
+	if err != nil {
+		return schema.InterviewFeedback{}, err
+	}
+	if len(submitted) == 0 {
+		return schema.InterviewFeedback{}, pgx.ErrNoRows
+	}
+	return submitted[0], tx.Commit(ctx)
+}
+
+// GetApplicationFeedback returns the interview feedback of an application as
+// seen by a member of the hiring team. While the viewer is on the panel of an
+// interview of the application and has not submitted feedback for it, they
+// only see their own feedback, so others' scores cannot sway theirs. Results
+// are aggregated over the visible feedback only.
+func GetApplicationFeedback(ctx context.Context, applicationID, viewerID int) (schema.FeedbackSummary, error) {
+	summary := schema.FeedbackSummary{
+		ApplicationID:    applicationID,
+		Recommendations:  make(map[string]int, len(schema.Recommendations)),
+		Competencies:     []schema.CompetencyResult{},
+		AwaitingFeedback: []int{},
+	}
+	for _, recommendation := range schema.Recommendations {
+		summary.Recommendations[recommendation] = 0
+	}
+
+	awaitingQuery := `
+		SELECT i.id
+		FROM interviews i
+		JOIN interview_interviewers p ON p.interview_id = i.id AND p.employer_id = $2
+		WHERE i.application_id = $1 AND i.status NOT IN ('Cancelled', 'No Show')
+		  AND NOT EXISTS (SELECT 1 FROM interview_feedback f WHERE f.interview_id = i.id AND f.interviewer_id = $2)
+		ORDER BY i.scheduled_date, i.id`
+	rows, err := config.DB.Query(ctx, awaitingQuery, applicationID, viewerID)
+	if err != nil {
+		return summary, err
+	}
+	for rows.Next() {
+		var interviewID int
+		if err := rows.Scan(&interviewID); err != nil {
+			rows.Close()
+			return summary, err
+		}
+		summary.AwaitingFeedback = append(summary.AwaitingFeedback, interviewID)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return summary, err
+	}
+
+	summary.Feedback, err = queryFeedback(ctx, config.DB, `i.application_id = $1 AND ($2 OR f.interviewer_id = $3)`,
+		applicationID, len(summary.AwaitingFeedback) == 0, viewerID)
+	if err != nil {
+		return summary, err
+	}
+	summary.Count = len(summary.Feedback)
+
+	var jobListingID int
+	if err := config.DB.QueryRow(ctx, `SELECT job_listing_id FROM applications WHERE id = $1`, applicationID).Scan(&jobListingID); err != nil {
+		return summary, err
+	}
+	competencies, err := GetScorecard(ctx, jobListingID)
+	if err != nil {
+		return summary, err
+	}
+
+	totals := make(map[int]int)
+	counts := make(map[int]int)
+	for _, feedback := range summary.Feedback {
+		summary.Recommendations[feedback.Recommendation]++
+		for _, rating := range feedback.Ratings {
+			totals[rating.CompetencyID] += rating.Rating
+			counts[rating.CompetencyID]++
+		}
+	}
+	for _, competency := range competencies {
+		result := schema.CompetencyResult{
+			CompetencyID: competency.ID,
+			Name:         competency.Name,
+			ScaleMax:     competency.ScaleMax,
+			Count:        counts[competency.ID],
+		}
+		if result.Count > 0 {
+			average := float64(totals[competency.ID]) / float64(result.Count)
+			result.Average = &average
+		}
+		summary.Competencies = append(summary.Competencies, result)
+	}
+	return summary, nil
+}
+
+// queryFeedback returns the feedback matching a condition on interview_feedback f
+// and interviews i, oldest first, with the ratings of each
+func queryFeedback(ctx context.Context, q querier, where string, args ...any) ([]schema.InterviewFeedback, error) {
+	query := `
+		SELECT f.id, f.interview_id, f.interviewer_id, e.contact_person, r.name, f.recommendation, f.notes, f.submitted_at
+		FROM interview_feedback f
+		JOIN interviews i ON f.interview_id = i.id
+		JOIN employers e ON f.interviewer_id = e.id
+		LEFT JOIN interview_plan_rounds r ON i.round_id = r.id
+		WHERE ` + where + `
+		ORDER BY f.submitted_at, f.id`
+	rows, err := q.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	feedback := []schema.InterviewFeedback{}
+	index := make(map[int]int)
+	for rows.Next() {
+		entry := schema.InterviewFeedback{Ratings: []schema.CompetencyRating{}}
+		err := rows.Scan(&entry.ID, &entry.InterviewID, &entry.InterviewerID, &entry.InterviewerName, &entry.RoundName,
+			&entry.Recommendation, &entry.Notes, &entry.SubmittedAt)
+		if err != nil {
+			return nil, err
+		}
+		index[entry.ID] = len(feedback)
+		feedback = append(feedback, entry)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+	if len(feedback) == 0 {
+		return feedback, nil
+	}
+
+	ids := make([]int, 0, len(feedback))
+	for _, entry := range feedback {
+		ids = append(ids, entry.ID)
+	}
+	scoreQuery := `
+		SELECT s.feedback_id, s.competency_id, c.name, s.rating, s.comment
+		FROM interview_feedback_scores s
+		JOIN scorecard_competencies c ON s.competency_id = c.id
+		WHERE s.feedback_id = ANY($1)
+		ORDER BY c.position, c.id`
+	scoreRows, err := q.Query(ctx, scoreQuery, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer scoreRows.Close()
+
+	for scoreRows.Next() {
+		var feedbackID int
+		var rating schema.CompetencyRating
+		if err := scoreRows.Scan(&feedbackID, &rating.CompetencyID, &rating.CompetencyName, &rating.Rating, &rating.Comment); err != nil {
+			return nil, err
+		}
+		i := index[feedbackID]
+		feedback[i].Ratings = append(feedback[i].Ratings, rating)
+	}
+	return feedback, scoreRows.Err()
+}
@@ -125,7 +125,7 @@ func RegisterEmployer(ctx context.Context, employer schema.InputEmployer) (int,
 func GetJobSeeker(ctx context.Context, id int) (schema.JobSeeker, error) {
 	// Fetch main JobSeeker details
 	query := `
-		SELECT id, first_name, last_name, email, password, location, profile_picture, phone_number, linkedin_url 
+		SELECT id, first_name, last_name, email, password, location, profile_picture, phone_number, linkedin_url, time_zone
 		FROM job_seekers WHERE id = $1
 	`
 
@@ -141,6 +141,7 @@ func GetJobSeeker(ctx context.Context, id int) (schema.JobSeeker, error) {
 		&jobSeeker.ProfilePicture,
 		&jobSeeker.PhoneNumber,
 		&jobSeeker.LinkedinURL,
+		&jobSeeker.TimeZone,
 	)
 	if err != nil {
 		return schema.JobSeeker{}, err
@@ -249,6 +250,7 @@ func GetEmployer(ctx context.Context, id int) (schema.Employer, error) {
 			e.description, 
 			e.contact_person, 
 			e.contact_number,
+			e.time_zone,
 			c.company_name,
 			c.industry,
 			c.website
@@ -266,6 +268,7 @@ func GetEmployer(ctx context.Context, id int) (schema.Employer, error) {
 		&employer.Description,
 		&employer.ContactPerson,
 		&employer.ContactNumber,
+		&employer.TimeZone,
 		&employer.CompanyName,
 		&employer.Industry,
 		&employer.Website,
@@ -361,8 +364,9 @@ func UpdateJobSeeker(ctx context.Context, jobSeeker schema.JobSeeker) error {
 			location = $5,
 			profile_picture = $6,
 			phone_number = $7,
-			linkedin_url = $8
-		WHERE id = $9
+			linkedin_url = $8,
+			time_zone = $9
+		WHERE id = $10
 	`
 	_, err = tx.Exec(ctx, jobSeekerQuery,
 		jobSeeker.FirstName,
@@ -373,18 +377,44 @@ func UpdateJobSeeker(ctx context.Context, jobSeeker schema.JobSeeker) error {
 		jobSeeker.ProfilePicture,
 		jobSeeker.PhoneNumber,
 		jobSeeker.LinkedinURL,
+		jobSeeker.TimeZone,
 		jobSeeker.ID,
 	)
 	if err != nil {
 		return err
 	}
+	if err := replaceJobSeekerDetails(ctx, tx, jobSeeker); err != nil {
+		return err
+	}
+
+	// Commit transaction
+	err = tx.Commit(ctx)
+	return err
+}
+
+// UpdateJobSeekerDetails replaces a job seeker's skills, education and
+// experience, leaving the account fields untouched
+func UpdateJobSeekerDetails(ctx context.Context, jobSeeker schema.JobSeeker) error {
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := replaceJobSeekerDetails(ctx, tx, jobSeeker); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func replaceJobSeekerDetails(ctx context.Context, q querier, jobSeeker schema.JobSeeker) error {
 	// Update Skills
-	_, err = tx.Exec(ctx, `DELETE FROM job_seeker_skills WHERE job_seeker_id = $1`, jobSeeker.ID)
+	_, err := q.Exec(ctx, `DELETE FROM job_seeker_skills WHERE job_seeker_id = $1`, jobSeeker.ID)
 	if err != nil {
 		return err
 	}
 	for _, skill := range jobSeeker.Skills {
-		_, err = tx.Exec(ctx, `
+		_, err = q.Exec(ctx, `
 			INSERT INTO job_seeker_skills (job_seeker_id, skill_name, skill_level)
 			VALUES ($1, $2, $3)`,
 			jobSeeker.ID, skill.SkillName, skill.SkillProficiency)
@@ -394,12 +424,12 @@ func UpdateJobSeeker(ctx context.Context, jobSeeker schema.JobSeeker) error {
 	}
 
 	// Update Education
-	_, err = tx.Exec(ctx, `DELETE FROM education WHERE job_seeker_id = $1`, jobSeeker.ID)
+	_, err = q.Exec(ctx, `DELETE FROM education WHERE job_seeker_id = $1`, jobSeeker.ID)
 	if err != nil {
 		return err
 	}
 	for _, edu := range jobSeeker.Education {
-		_, err = tx.Exec(ctx, `
+		_, err = q.Exec(ctx, `
 			INSERT INTO education (job_seeker_id, education_level, institution_name, field_of_study, start_year, end_year, grade)
 			VALUES ($1, $2, $3, $4, $5, $6, $7)`,
 			jobSeeker.ID, edu.Level, edu.Institution, edu.FieldOfStudy, edu.StartYear, edu.EndYear, edu.Grade)
@@ -409,12 +439,12 @@ func UpdateJobSeeker(ctx context.Context, jobSeeker schema.JobSeeker) error {
 	}
 
 	// Update Experience
-	_, err = tx.Exec(ctx, `DELETE FROM experience WHERE job_seeker_id = $1`, jobSeeker.ID)
+	_, err = q.Exec(ctx, `DELETE FROM experience WHERE job_seeker_id = $1`, jobSeeker.ID)
 	if err != nil {
 		return err
 	}
 	for _, exp := range jobSeeker.Experience {
-		_, err = tx.Exec(ctx, `
+		_, err = q.Exec(ctx, `
 			INSERT INTO experience (job_seeker_id, job_title, company_name, location, start_date, end_date)
 			VALUES ($1, $2, $3, $4, $5, $6)`,
 			jobSeeker.ID, exp.JobTitle, exp.CompanyName, exp.Location, exp.StartDate, exp.EndDate)
@@ -423,9 +453,7 @@ func UpdateJobSeeker(ctx context.Context, jobSeeker schema.JobSeeker) error {
 		}
 	}
 
-	// Commit transaction
-	err = tx.Commit(ctx)
-	return err
+	return nil
 }
 
 
@@ -438,8 +466,9 @@ func UpdateEmployer(ctx context.Context, employer schema.Employer) error {
 			password = $3,
 			description = $4,
 			contact_person = $5,
-			contact_number = $6
-		WHERE id = $7
+			contact_number = $6,
+			time_zone = $7
+		WHERE id = $8
 	`
 	_, err := config.DB.Exec(ctx, query,
 		employer.CompanyID,
@@ -448,11 +477,35 @@ func UpdateEmployer(ctx context.Context, employer schema.Employer) error {
 		employer.Description,
 		employer.ContactPerson,
 		employer.ContactNumber,
+		employer.TimeZone,
 		employer.ID,
 	)
 	return err
 }
 
+// GetUserTimeZone returns the time zone a job seeker or employer has chosen
+func GetUserTimeZone(ctx context.Context, userType string, userID int) (string, error) {
+	table := "job_seekers"
+	if userType == "employer" {
+		table = "employers"
+	}
+	var timeZone string
+	err := config.DB.QueryRow(ctx, `SELECT time_zone FROM `+table+` WHERE id = $1`, userID).Scan(&timeZone)
+	return timeZone, err
+}
+
+// GetUserContact returns the email address and phone number of a job seeker
+// or employer. The phone number is empty when none is on file.
+func GetUserContact(ctx context.Context, userType string, userID int) (string, string, error) {
+	query := `SELECT email, COALESCE(phone_number, '') FROM job_seekers WHERE id = $1`
+	if userType == "employer" {
+		query = `SELECT email, COALESCE(contact_number, '') FROM employers WHERE id = $1`
+	}
+	var email, phone string
+	err := config.DB.QueryRow(ctx, query, userID).Scan(&email, &phone)
+	return email, phone, err
+}
+
 func DeleteJobSeeker(ctx context.Context, userID int) error {
 	// var query string
 
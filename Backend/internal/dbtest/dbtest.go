@@ -0,0 +1,178 @@
+// Package dbtest runs tests against a throwaway copy of the schema in the
+// PostgreSQL database named by TEST_DB_URL. Each test binary gets its own
+// schema, created from tables.sql and dropped afterwards. Tests that need the
+// database are skipped when TEST_DB_URL is not set.
+package dbtest
+
+import (
+	"Backend/config"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	available bool
+	sequence  atomic.Int64
+)
+
+// Main sets up the test database, if one is configured, runs the tests and
+// removes it again. Call it from TestMain: os.Exit(dbtest.Main(m)).
+func Main(m *testing.M) int {
+	url := os.Getenv("TEST_DB_URL")
+	if url == "" {
+		return m.Run()
+	}
+
+	ctx := context.Background()
+	admin, err := pgx.Connect(ctx, url)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "dbtest: unable to connect:", err)
+		return 1
+	}
+	defer admin.Close(ctx)
+
+	schemaName := fmt.Sprintf("test_%d_%d", os.Getpid(), time.Now().UnixNano())
+	if _, err := admin.Exec(ctx, "CREATE SCHEMA "+schemaName); err != nil {
+		fmt.Fprintln(os.Stderr, "dbtest: unable to create schema:", err)
+		return 1
+	}
+	defer admin.Exec(ctx, "DROP SCHEMA "+schemaName+" CASCADE")
+
+	poolConfig, err := pgxpool.ParseConfig(url)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "dbtest:", err)
+		return 1
+	}
+	poolConfig.ConnConfig.RuntimeParams["search_path"] = schemaName
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "dbtest:", err)
+		return 1
+	}
+	defer pool.Close()
+
+	tables, err := os.ReadFile(tablesFile())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "dbtest:", err)
+		return 1
+	}
+	// Without arguments the statements are sent together over the simple protocol
+	if _, err := pool.Exec(ctx, string(tables)); err != nil {
+		fmt.Fprintln(os.Stderr, "dbtest: unable to load tables.sql:", err)
+		return 1
+	}
+
+	config.DB = pool
+	available = true
+	return m.Run()
+}
+
+// tablesFile finds tables.sql in the working directory or one of its parents
+func tablesFile() string {
+	dir, _ := os.Getwd()
+	for {
+		path := filepath.Join(dir, "tables.sql")
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "tables.sql"
+		}
+		dir = parent
+	}
+}
+
+// Require skips the test when there is no test database and otherwise
+// empties every table, so each test starts from a clean schema
+func Require(t testing.TB) {
+	t.Helper()
+	if !available {
+		t.Skip("TEST_DB_URL is not set")
+	}
+
+	ctx := context.Background()
+	rows, err := config.DB.Query(ctx, `SELECT tablename FROM pg_tables WHERE schemaname = current_schema()`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tables, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := config.DB.Exec(ctx, "TRUNCATE "+strings.Join(tables, ", ")+" RESTART IDENTITY CASCADE"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// insert runs an INSERT ... RETURNING id and returns the id
+func insert(t testing.TB, query string, args ...any) int {
+	t.Helper()
+	var id int
+	if err := config.DB.QueryRow(context.Background(), query, args...).Scan(&id); err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+// Company adds a company
+func Company(t testing.TB) int {
+	return insert(t, `INSERT INTO company (company_name) VALUES ($1) RETURNING id`,
+		fmt.Sprintf("Company %d", sequence.Add(1)))
+}
+
+// Employer adds an employer of a company
+func Employer(t testing.TB, companyID int) int {
+	return insert(t, `
+		INSERT INTO employers (companyid, email, password, contact_person)
+		VALUES ($1, $2, 'hash', 'Test Employer') RETURNING id`,
+		companyID, fmt.Sprintf("employer%d@example.com", sequence.Add(1)))
+}
+
+// JobSeeker adds a job seeker
+func JobSeeker(t testing.TB) int {
+	return insert(t, `
+		INSERT INTO job_seekers (first_name, last_name, email, password)
+		VALUES ('Test', 'Seeker', $1, 'hash') RETURNING id`,
+		fmt.Sprintf("seeker%d@example.com", sequence.Add(1)))
+}
+
+// Job adds an open job listing posted by an employer
+func Job(t testing.TB, employerID int) int {
+	return insert(t, `
+		INSERT INTO job_listings (employer_id, job_title, expiry_date, job_category)
+		VALUES ($1, 'Engineer', NOW() + INTERVAL '30 days', 'Engineering') RETURNING id`, employerID)
+}
+
+// Application adds an application in the given status
+func Application(t testing.TB, jobSeekerID, jobListingID int, status string) int {
+	return insert(t, `
+		INSERT INTO applications (job_seeker_id, job_listing_id, application_status)
+		VALUES ($1, $2, $3) RETURNING id`, jobSeekerID, jobListingID, status)
+}
+
+// Availability adds an availability window for an interviewer
+func Availability(t testing.TB, employerID int, from, to time.Time) int {
+	return insert(t, `
+		INSERT INTO interviewer_availability (employer_id, starts_at, ends_at)
+		VALUES ($1, $2, $3) RETURNING id`, employerID, from, to)
+}
+
+// BookingLink adds an hour-long booking link with an interviewer and returns
+// its token
+func BookingLink(t testing.TB, applicationID, interviewerID int, expiresAt time.Time) string {
+	token := fmt.Sprintf("token-%d", sequence.Add(1))
+	insert(t, `
+		INSERT INTO interview_booking_links (token, application_id, interviewer_id, duration_minutes, interview_mode, expires_at)
+		VALUES ($1, $2, $3, 60, 'Phone', $4) RETURNING id`, token, applicationID, interviewerID, expiresAt)
+	return token
+}
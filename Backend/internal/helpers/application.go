@@ -1 +1,97 @@
-package helpers
\ No newline at end of file
+package helpers
+
+import (
+	"Backend/internal/schema"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// mentionPattern matches colleagues mentioned by email, e.g. "@hr@technova.com"
+var mentionPattern = regexp.MustCompile(`(?:^|\s)@([A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,})`)
+
+// ComputeTimeInStage walks an application's history (oldest first) and returns
+// how long the application spent in each stage. An event starts a new stage
+// when it carries a stage name or a status different from the current one.
+// The last stage is measured up to now.
+func ComputeTimeInStage(events []schema.ApplicationEvent, now time.Time) []schema.StageDuration {
+	var durations []schema.StageDuration
+	current := ""
+
+	for _, event := range events {
+		label := ""
+		if event.StageName != nil {
+			label = *event.StageName
+		} else if event.ToStatus != nil {
+			label = *event.ToStatus
+		}
+		if label == "" || label == current {
+			continue
+		}
+
+		if n := len(durations); n > 0 {
+			leftAt := event.CreatedAt
+			durations[n-1].LeftAt = &leftAt
+			durations[n-1].Seconds = int64(leftAt.Sub(durations[n-1].EnteredAt).Seconds())
+		}
+		durations = append(durations, schema.StageDuration{Stage: label, EnteredAt: event.CreatedAt})
+		current = label
+	}
+
+	if n := len(durations); n > 0 {
+		durations[n-1].Seconds = int64(now.Sub(durations[n-1].EnteredAt).Seconds())
+	}
+	return durations
+}
+
+// seekerInterviewEvents are the interview events shown on the job seeker's timeline
+var seekerInterviewEvents = map[string]bool{
+	schema.EventInterviewScheduled:   true,
+	schema.EventInterviewCompleted:   true,
+	schema.EventInterviewCancelled:   true,
+	schema.EventInterviewRescheduled: true,
+	schema.EventRescheduleRequested:  true,
+	schema.EventRescheduleDeclined:   true,
+	schema.EventInterviewNoShow:      true,
+	schema.EventNoShowReported:       true,
+}
+
+// SeekerTimeline reduces an application's history to what the job seeker may
+// see: only changes of the mapped status and interview events, without
+// internal stage names, reviewer identities or reasons.
+func SeekerTimeline(events []schema.ApplicationEvent) []schema.ApplicationEvent {
+	var timeline []schema.ApplicationEvent
+	status := ""
+
+	for _, event := range events {
+		visible := seekerInterviewEvents[event.EventType]
+		if event.ToStatus != nil && *event.ToStatus != status {
+			status = *event.ToStatus
+			visible = true
+		}
+		if !visible {
+			continue
+		}
+
+		event.StageID = nil
+		event.StageName = nil
+		event.ActorID = nil
+		event.Reason = nil
+		timeline = append(timeline, event)
+	}
+	return timeline
+}
+
+// ParseMentions returns the distinct email addresses mentioned in a note
+func ParseMentions(note string) []string {
+	var emails []string
+	seen := make(map[string]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatch(note, -1) {
+		email := strings.ToLower(strings.TrimRight(match[1], "."))
+		if !seen[email] {
+			seen[email] = true
+			emails = append(emails, email)
+		}
+	}
+	return emails
+}
@@ -0,0 +1,71 @@
+package helpers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// Limits for files attached to messages
+const (
+	MaxAttachmentSize  = 10 << 20
+	MaxAttachmentCount = 5
+)
+
+var ErrUnsupportedAttachment = errors.New("attachments must be PDF, DOCX, plain text, PNG, JPEG or GIF files")
+
+// attachmentExtensions maps accepted attachment types to their stored extension
+var attachmentExtensions = map[string]string{
+	MimePDF:                     ".pdf",
+	MimeDOCX:                    ".docx",
+	"text/plain; charset=utf-8": ".txt",
+	"image/png":                 ".png",
+	"image/jpeg":                ".jpg",
+	"image/gif":                 ".gif",
+}
+
+// DetectAttachmentType sniffs an uploaded attachment and returns its MIME type
+// if it is one of the accepted types
+func DetectAttachmentType(file multipart.File, size int64, filename string) (string, error) {
+	if mimeType, err := DetectResumeType(file, size, filename); err == nil {
+		return mimeType, nil
+	}
+
+	header := make([]byte, 512)
+	n, err := file.Read(header)
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	mimeType := http.DetectContentType(header[:n])
+	if _, ok := attachmentExtensions[mimeType]; !ok {
+		return "", ErrUnsupportedAttachment
+	}
+	return mimeType, nil
+}
+
+// AttachmentFileName builds an unguessable stored file name for an attachment
+func AttachmentFileName(mimeType string) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(random) + attachmentExtensions[mimeType], nil
+}
+
+// CleanFileName strips any directory from a client-supplied file name
+func CleanFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, `\`, "/"))
+	if name == "." || name == "/" {
+		return "attachment"
+	}
+	return name
+}
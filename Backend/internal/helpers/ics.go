@@ -0,0 +1,110 @@
+package helpers
+
+import (
+	"Backend/internal/schema"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// iCalendar methods used in interview emails and feeds (RFC 5546)
+const (
+	ICSMethodPublish = "PUBLISH"
+	ICSMethodRequest = "REQUEST"
+	ICSMethodCancel  = "CANCEL"
+)
+
+const (
+	icsDateFormat = "20060102T150405Z"
+	icsDomain     = "dazzledate.com"
+	icsOrganizer  = "noreply.dazzledate@gmail.com"
+)
+
+var icsTextEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// InterviewUID is the stable iCalendar UID of an interview, so calendar
+// clients update the same event when it is rescheduled or cancelled
+func InterviewUID(interviewID int) string {
+	return fmt.Sprintf("interview-%d@%s", interviewID, icsDomain)
+}
+
+// BuildInterviewICS renders interviews as an iCalendar object. REQUEST and
+// CANCEL are meant for a single interview emailed to the attendee; PUBLISH
+// is used for subscription feeds, which have no attendee. Cancelled
+// interviews are marked CANCELLED so clients drop them.
+func BuildInterviewICS(method, calendarName, attendee string, interviews []schema.Interview, now time.Time) []byte {
+	var b strings.Builder
+	writeICSLine(&b, "BEGIN:VCALENDAR")
+	writeICSLine(&b, "VERSION:2.0")
+	writeICSLine(&b, "PRODID:-//DazzleDate//Job Portal//EN")
+	writeICSLine(&b, "CALSCALE:GREGORIAN")
+	writeICSLine(&b, "METHOD:"+method)
+	if calendarName != "" {
+		writeICSLine(&b, "X-WR-CALNAME:"+icsTextEscaper.Replace(calendarName))
+	}
+
+	for _, interview := range interviews {
+		duration := time.Duration(interview.DurationMinutes) * time.Minute
+		if duration <= 0 {
+			duration = time.Hour
+		}
+
+		summary := "Interview"
+		if interview.RoundName != nil {
+			summary = *interview.RoundName + " interview"
+		}
+		if interview.JobTitle != "" {
+			summary += ": " + interview.JobTitle
+		}
+		description := fmt.Sprintf("Interview for application #%d (%s).", interview.ApplicationID, interview.InterviewMode)
+		if interview.InterviewerName != nil {
+			description += " Interviewer: " + *interview.InterviewerName + "."
+		}
+
+		status := "CONFIRMED"
+		if method == ICSMethodCancel || interview.Status == "Cancelled" {
+			status = "CANCELLED"
+		}
+
+		writeICSLine(&b, "BEGIN:VEVENT")
+		writeICSLine(&b, "UID:"+InterviewUID(interview.ID))
+		writeICSLine(&b, fmt.Sprintf("SEQUENCE:%d", interview.Sequence))
+		writeICSLine(&b, "DTSTAMP:"+now.UTC().Format(icsDateFormat))
+		writeICSLine(&b, "DTSTART:"+interview.ScheduledDate.UTC().Format(icsDateFormat))
+		writeICSLine(&b, "DTEND:"+interview.ScheduledDate.Add(duration).UTC().Format(icsDateFormat))
+		writeICSLine(&b, "SUMMARY:"+icsTextEscaper.Replace(summary))
+		writeICSLine(&b, "DESCRIPTION:"+icsTextEscaper.Replace(description))
+		if interview.InterviewLink != nil && *interview.InterviewLink != "" {
+			writeICSLine(&b, "LOCATION:"+icsTextEscaper.Replace(*interview.InterviewLink))
+			writeICSLine(&b, "URL:"+*interview.InterviewLink)
+		}
+		writeICSLine(&b, "ORGANIZER;CN=DazzleDate Job Portal:mailto:"+icsOrganizer)
+		if attendee != "" {
+			writeICSLine(&b, "ATTENDEE;ROLE=REQ-PARTICIPANT;RSVP=FALSE:mailto:"+attendee)
+		}
+		writeICSLine(&b, "STATUS:"+status)
+		writeICSLine(&b, "END:VEVENT")
+	}
+
+	writeICSLine(&b, "END:VCALENDAR")
+	return []byte(b.String())
+}
+
+// writeICSLine writes a content line ending in CRLF, folding it so no line
+// is longer than 75 octets without splitting a UTF-8 character
+func writeICSLine(b *strings.Builder, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space, which counts towards the limit
+		limit = 74
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
@@ -0,0 +1,152 @@
+package helpers
+
+import (
+	"Backend/internal/schema"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+// unfoldICS joins folded content lines back together (RFC 5545 3.1)
+func unfoldICS(ics string) []string {
+	return strings.Split(strings.TrimSuffix(strings.ReplaceAll(ics, "\r\n ", ""), "\r\n"), "\r\n")
+}
+
+func hasLine(lines []string, want string) bool {
+	for _, line := range lines {
+		if line == want {
+			return true
+		}
+	}
+	return false
+}
+
+func TestWriteICSLine(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{"short", "SUMMARY:Interview"},
+		{"exactly 75 octets", "SUMMARY:" + strings.Repeat("a", 67)},
+		{"76 octets", "SUMMARY:" + strings.Repeat("a", 68)},
+		{"several folds", "DESCRIPTION:" + strings.Repeat("abcdefghij", 30)},
+		{"multi-byte characters", "DESCRIPTION:" + strings.Repeat("é€😀", 40)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			writeICSLine(&b, tt.line)
+			out := b.String()
+
+			if !strings.HasSuffix(out, "\r\n") {
+				t.Fatalf("line does not end in CRLF: %q", out)
+			}
+			physical := strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+			for i, line := range physical {
+				if len(line) > 75 {
+					t.Errorf("line %d is %d octets long", i, len(line))
+				}
+				if i > 0 && !strings.HasPrefix(line, " ") {
+					t.Errorf("continuation line %d does not start with a space: %q", i, line)
+				}
+				if !utf8.ValidString(strings.TrimPrefix(line, " ")) {
+					t.Errorf("line %d splits a UTF-8 character: %q", i, line)
+				}
+			}
+			if len(tt.line) <= 75 && len(physical) != 1 {
+				t.Errorf("line of %d octets was folded into %d lines", len(tt.line), len(physical))
+			}
+			if got := unfoldICS(out); len(got) != 1 || got[0] != tt.line {
+				t.Errorf("unfolded = %q, want %q", got, tt.line)
+			}
+		})
+	}
+}
+
+func TestBuildInterviewICS(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 8, 0, 0, 0, time.UTC)
+	link := "https://meet.example.com/abc"
+	round := "Technical"
+	interviewer := "Jane Doe, Lead"
+	interview := schema.Interview{
+		ID:              7,
+		ApplicationID:   42,
+		ScheduledDate:   time.Date(2024, time.March, 4, 10, 30, 0, 0, time.FixedZone("CET", 3600)),
+		InterviewMode:   "Video",
+		Status:          "Scheduled",
+		InterviewerName: &interviewer,
+		InterviewLink:   &link,
+		RoundName:       &round,
+		DurationMinutes: 45,
+		JobTitle:        "Engineer; Backend",
+		Sequence:        3,
+	}
+
+	t.Run("request", func(t *testing.T) {
+		lines := unfoldICS(string(BuildInterviewICS(ICSMethodRequest, "", "seeker@example.com", []schema.Interview{interview}, now)))
+		for _, want := range []string{
+			"BEGIN:VCALENDAR",
+			"METHOD:REQUEST",
+			"BEGIN:VEVENT",
+			"UID:interview-7@dazzledate.com",
+			"SEQUENCE:3",
+			"DTSTAMP:20240301T080000Z",
+			"DTSTART:20240304T093000Z",
+			"DTEND:20240304T101500Z",
+			`SUMMARY:Technical interview: Engineer\; Backend`,
+			`DESCRIPTION:Interview for application #42 (Video). Interviewer: Jane Doe\, Lead.`,
+			"LOCATION:" + link,
+			"URL:" + link,
+			"ATTENDEE;ROLE=REQ-PARTICIPANT;RSVP=FALSE:mailto:seeker@example.com",
+			"STATUS:CONFIRMED",
+			"END:VEVENT",
+			"END:VCALENDAR",
+		} {
+			if !hasLine(lines, want) {
+				t.Errorf("missing line %q in %q", want, lines)
+			}
+		}
+		if lines[len(lines)-1] != "END:VCALENDAR" {
+			t.Errorf("calendar does not end with END:VCALENDAR")
+		}
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		lines := unfoldICS(string(BuildInterviewICS(ICSMethodCancel, "", "seeker@example.com", []schema.Interview{interview}, now)))
+		if !hasLine(lines, "METHOD:CANCEL") || !hasLine(lines, "STATUS:CANCELLED") {
+			t.Errorf("cancellation is not marked cancelled: %q", lines)
+		}
+	})
+
+	t.Run("feed", func(t *testing.T) {
+		cancelled := interview
+		cancelled.ID = 8
+		cancelled.Status = "Cancelled"
+		cancelled.InterviewLink = nil
+		cancelled.RoundName = nil
+		cancelled.DurationMinutes = 0
+
+		ics := string(BuildInterviewICS(ICSMethodPublish, "My interviews", "", []schema.Interview{interview, cancelled}, now))
+		lines := unfoldICS(ics)
+		if !hasLine(lines, "METHOD:PUBLISH") || !hasLine(lines, "X-WR-CALNAME:My interviews") {
+			t.Errorf("feed header is missing: %q", lines)
+		}
+		if n := strings.Count(ics, "BEGIN:VEVENT"); n != 2 {
+			t.Errorf("got %d events, want 2", n)
+		}
+		if strings.Contains(ics, "ATTENDEE") {
+			t.Errorf("feed has an attendee")
+		}
+		// The second event has no link, falls back to an hour and is cancelled
+		for _, want := range []string{"UID:interview-8@dazzledate.com", "SUMMARY:Interview: Engineer\\; Backend", "DTEND:20240304T103000Z", "STATUS:CANCELLED"} {
+			if !hasLine(lines, want) {
+				t.Errorf("missing line %q", want)
+			}
+		}
+		if n := strings.Count(ics, "LOCATION:"); n != 1 {
+			t.Errorf("got %d locations, want 1", n)
+		}
+	})
+}
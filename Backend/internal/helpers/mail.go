@@ -1,13 +1,30 @@
 package helpers
 
 import (
+	"bytes"
+	"encoding/base64"
 	"fmt"
+	"mime/multipart"
 	"net/smtp"
+	"net/textproto"
 	"strings"
 )
 
+// MailAttachment is a file sent along with an email notification
+type MailAttachment struct {
+	FileName    string
+	ContentType string
+	Data        []byte
+}
+
 // SendMail sends an email notification with HTML formatting and headers
 func SendMail(mailID string, message string) error {
+	return SendMailWithAttachments(mailID, message)
+}
+
+// SendMailWithAttachments sends an email notification with files attached,
+// such as a calendar invite. Without attachments it is a plain HTML email.
+func SendMailWithAttachments(mailID string, message string, attachments ...MailAttachment) error {
 	smtpServer := "smtp.gmail.com"
 	port := "587"
 	senderEmail := "noreply.dazzledate@gmail.com"
@@ -49,11 +66,23 @@ func SendMail(mailID string, message string) error {
 		"Subject: Job Portal Notification",
 		"Reply-To: support@dazzledate.com",
 		"MIME-Version: 1.0",
-		"Content-Type: text/html; charset=UTF-8",
+	}
+
+	var content string
+	if len(attachments) == 0 {
+		headers = append(headers, "Content-Type: text/html; charset=UTF-8")
+		content = htmlMessage
+	} else {
+		body, boundary, err := buildMultipartBody(htmlMessage, attachments)
+		if err != nil {
+			return fmt.Errorf("failed to build email: %w", err)
+		}
+		headers = append(headers, "Content-Type: multipart/mixed; boundary="+boundary)
+		content = body
 	}
 
 	// Combine headers and message
-	emailBody := strings.Join(headers, "\r\n") + "\r\n\r\n" + htmlMessage
+	emailBody := strings.Join(headers, "\r\n") + "\r\n\r\n" + content
 
 	// Send the email
 	err := smtp.SendMail(smtpServer+":"+port, auth, senderEmail, []string{mailID}, []byte(emailBody))
@@ -62,3 +91,44 @@ func SendMail(mailID string, message string) error {
 	}
 	return nil
 }
+
+// buildMultipartBody puts the HTML message and the attachments in a
+// multipart/mixed body and returns it with its boundary
+func buildMultipartBody(htmlMessage string, attachments []MailAttachment) (string, string, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	htmlPart, err := writer.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/html; charset=UTF-8"}})
+	if err != nil {
+		return "", "", err
+	}
+	if _, err := htmlPart.Write([]byte(htmlMessage)); err != nil {
+		return "", "", err
+	}
+
+	for _, attachment := range attachments {
+		part, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {attachment.ContentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {fmt.Sprintf("attachment; filename=%q", attachment.FileName)},
+		})
+		if err != nil {
+			return "", "", err
+		}
+		encoded := base64.StdEncoding.EncodeToString(attachment.Data)
+		for len(encoded) > 76 {
+			if _, err := part.Write([]byte(encoded[:76] + "\r\n")); err != nil {
+				return "", "", err
+			}
+			encoded = encoded[76:]
+		}
+		if _, err := part.Write([]byte(encoded)); err != nil {
+			return "", "", err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return "", "", err
+	}
+	return buf.String(), writer.Boundary(), nil
+}
@@ -0,0 +1,229 @@
+package helpers
+
+import (
+	"Backend/internal/schema"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Weights of each component in the overall match score
+const (
+	skillWeight      = 0.60
+	experienceWeight = 0.25
+	educationWeight  = 0.15
+)
+
+// yearsPattern matches experience requirements such as "3+ years" or "5 yrs of Go"
+var yearsPattern = regexp.MustCompile(`(?i)(\d+(?:\.\d+)?)\s*\+?\s*(?:years?|yrs?)`)
+
+// skillLevelWeights credits a matched skill by the applicant's proficiency
+var skillLevelWeights = map[string]float64{
+	"Beginner":     0.4,
+	"Intermediate": 0.7,
+	"Advanced":     0.9,
+	"Expert":       1.0,
+}
+
+// educationLevels ranks education keywords; higher is more advanced
+var educationLevels = []struct {
+	rank     int
+	keywords []string
+}{
+	{5, []string{"phd", "ph.d", "doctorate", "doctoral"}},
+	{4, []string{"master", "m.tech", "mtech", "m.sc", "msc", "mba", "m.e", "postgraduate"}},
+	{3, []string{"bachelor", "b.tech", "btech", "b.sc", "bsc", "b.e", "b.com", "bca", "undergraduate", "graduate", "degree"}},
+	{2, []string{"diploma", "associate"}},
+	{1, []string{"high school", "secondary", "12th", "10th"}},
+}
+
+// JobRequirements are a listing's free-text requirements split by kind
+type JobRequirements struct {
+	Skills            []string
+	MinYears          float64
+	EducationRank     int
+	EducationRequired string
+}
+
+// ParseRequirements classifies each requirement as a years-of-experience
+// requirement, an education requirement or, failing both, a skill
+func ParseRequirements(requirements []string) JobRequirements {
+	var parsed JobRequirements
+	for _, requirement := range requirements {
+		requirement = strings.TrimSpace(requirement)
+		if requirement == "" {
+			continue
+		}
+
+		if match := yearsPattern.FindStringSubmatch(requirement); match != nil {
+			if years, err := strconv.ParseFloat(match[1], 64); err == nil && years > parsed.MinYears {
+				parsed.MinYears = years
+			}
+			continue
+		}
+
+		if rank := educationRank(requirement); rank > 0 {
+			if rank > parsed.EducationRank {
+				parsed.EducationRank = rank
+				parsed.EducationRequired = requirement
+			}
+			continue
+		}
+
+		parsed.Skills = append(parsed.Skills, requirement)
+	}
+	return parsed
+}
+
+// ScoreApplication scores an applicant against parsed job requirements. Only
+// components the job has requirements for contribute; their weights are
+// rescaled so the overall score stays between 0 and 100.
+func ScoreApplication(requirements JobRequirements, application schema.ApplicationDetails, now time.Time) schema.RankedApplication {
+	breakdown := schema.ScoreBreakdown{MatchedSkills: []string{}, MissingSkills: []string{}}
+	total, weights := 0.0, 0.0
+
+	if len(requirements.Skills) > 0 {
+		levels := make(map[string]string, len(application.Skills))
+		for _, skill := range application.Skills {
+			levels[normalizeSkill(skill)] = application.SkillLevels[skill]
+		}
+
+		credit := 0.0
+		for _, required := range requirements.Skills {
+			level, ok := levels[normalizeSkill(required)]
+			if !ok {
+				breakdown.MissingSkills = append(breakdown.MissingSkills, required)
+				continue
+			}
+			breakdown.MatchedSkills = append(breakdown.MatchedSkills, required)
+			if weight, known := skillLevelWeights[level]; known {
+				credit += weight
+			} else {
+				credit += skillLevelWeights["Beginner"]
+			}
+		}
+
+		score := round(credit / float64(len(requirements.Skills)) * 100)
+		breakdown.SkillScore = &score
+		total += score * skillWeight
+		weights += skillWeight
+	}
+
+	breakdown.ExperienceYears = round(ExperienceYears(application.Experience, now))
+	if requirements.MinYears > 0 {
+		breakdown.RequiredYears = requirements.MinYears
+		score := round(math.Min(breakdown.ExperienceYears/requirements.MinYears, 1) * 100)
+		breakdown.ExperienceScore = &score
+		total += score * experienceWeight
+		weights += experienceWeight
+	}
+
+	highest := 0
+	for _, education := range application.Education {
+		if rank := educationRank(education.Level); rank > highest {
+			highest = rank
+			breakdown.EducationLevel = education.Level
+		}
+	}
+	if requirements.EducationRank > 0 {
+		breakdown.RequiredEducation = requirements.EducationRequired
+		score := round(math.Min(float64(highest)/float64(requirements.EducationRank), 1) * 100)
+		breakdown.EducationScore = &score
+		total += score * educationWeight
+		weights += educationWeight
+	}
+
+	ranked := schema.RankedApplication{ApplicationDetails: application, Breakdown: breakdown}
+	if weights > 0 {
+		ranked.Score = round(total / weights)
+	}
+	return ranked
+}
+
+// SortRankedApplications orders ranked applications by score or by
+// applied_date. Applications with equal scores stay in the order they applied
+// in, whichever direction the scores are sorted.
+func SortRankedApplications(ranked []schema.RankedApplication, sortBy string, descending bool) {
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if sortBy != "applied_date" && a.Score != b.Score {
+			if descending {
+				return a.Score > b.Score
+			}
+			return a.Score < b.Score
+		}
+		if sortBy == "applied_date" && descending {
+			return b.AppliedDate.Before(a.AppliedDate)
+		}
+		return a.AppliedDate.Before(b.AppliedDate)
+	})
+}
+
+// ExperienceYears sums the years covered by the given experience entries.
+// Overlapping periods are counted once and ongoing roles run until now.
+func ExperienceYears(experience []schema.ExperienceDetails, now time.Time) float64 {
+	type period struct{ start, end time.Time }
+	periods := make([]period, 0, len(experience))
+	for _, entry := range experience {
+		end := now
+		if entry.EndDate != nil {
+			end = *entry.EndDate
+		}
+		if end.After(entry.StartDate) {
+			periods = append(periods, period{entry.StartDate, end})
+		}
+	}
+	sort.Slice(periods, func(i, j int) bool { return periods[i].start.Before(periods[j].start) })
+
+	var total time.Duration
+	var current *period
+	for i := range periods {
+		p := periods[i]
+		if current != nil && !p.start.After(current.end) {
+			if p.end.After(current.end) {
+				current.end = p.end
+			}
+			continue
+		}
+		if current != nil {
+			total += current.end.Sub(current.start)
+		}
+		current = &p
+	}
+	if current != nil {
+		total += current.end.Sub(current.start)
+	}
+
+	return total.Hours() / (24 * 365.25)
+}
+
+func educationRank(text string) int {
+	text = strings.ToLower(text)
+	for _, level := range educationLevels {
+		for _, keyword := range level.keywords {
+			if strings.Contains(text, keyword) {
+				return level.rank
+			}
+		}
+	}
+	return 0
+}
+
+// normalizeSkill lowercases a skill and drops separators so that "Node.js"
+// matches "nodejs" and "Machine Learning" matches "machine-learning"
+func normalizeSkill(skill string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(skill) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '+' || r == '#' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func round(value float64) float64 {
+	return math.Round(value*100) / 100
+}
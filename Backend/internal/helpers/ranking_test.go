@@ -0,0 +1,255 @@
+package helpers
+
+import (
+	"Backend/internal/schema"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestParseRequirements(t *testing.T) {
+	tests := []struct {
+		name         string
+		requirements []string
+		want         JobRequirements
+	}{
+		{
+			name:         "empty",
+			requirements: nil,
+			want:         JobRequirements{},
+		},
+		{
+			name:         "skills only, blanks skipped",
+			requirements: []string{" Go ", "", "PostgreSQL"},
+			want:         JobRequirements{Skills: []string{"Go", "PostgreSQL"}},
+		},
+		{
+			name:         "highest years requirement wins",
+			requirements: []string{"2 years of Go", "5+ yrs experience", "3 years"},
+			want:         JobRequirements{MinYears: 5},
+		},
+		{
+			name:         "fractional years",
+			requirements: []string{"1.5 years"},
+			want:         JobRequirements{MinYears: 1.5},
+		},
+		{
+			name:         "highest education requirement wins",
+			requirements: []string{"Bachelor's degree", "Master's in CS", "Diploma"},
+			want:         JobRequirements{EducationRank: 4, EducationRequired: "Master's in CS"},
+		},
+		{
+			name:         "mixed",
+			requirements: []string{"React", "PhD preferred", "4 years"},
+			want: JobRequirements{
+				Skills:            []string{"React"},
+				MinYears:          4,
+				EducationRank:     5,
+				EducationRequired: "PhD preferred",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseRequirements(tt.requirements)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseRequirements(%q) = %+v, want %+v", tt.requirements, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExperienceYears(t *testing.T) {
+	now := date(2024, 1, 1)
+	end := func(t time.Time) *time.Time { return &t }
+
+	tests := []struct {
+		name       string
+		experience []schema.ExperienceDetails
+		want       float64
+	}{
+		{
+			name: "none",
+			want: 0,
+		},
+		{
+			name: "single finished role",
+			experience: []schema.ExperienceDetails{
+				{StartDate: date(2018, 1, 1), EndDate: end(date(2020, 1, 1))},
+			},
+			want: 2,
+		},
+		{
+			name: "ongoing role runs until now",
+			experience: []schema.ExperienceDetails{
+				{StartDate: date(2021, 1, 1)},
+			},
+			want: 3,
+		},
+		{
+			name: "overlapping roles are counted once",
+			experience: []schema.ExperienceDetails{
+				{StartDate: date(2016, 1, 1), EndDate: end(date(2019, 1, 1))},
+				{StartDate: date(2018, 1, 1), EndDate: end(date(2020, 1, 1))},
+			},
+			want: 4,
+		},
+		{
+			name: "a role inside another adds nothing",
+			experience: []schema.ExperienceDetails{
+				{StartDate: date(2015, 1, 1), EndDate: end(date(2020, 1, 1))},
+				{StartDate: date(2016, 1, 1), EndDate: end(date(2017, 1, 1))},
+			},
+			want: 5,
+		},
+		{
+			name: "gaps are not counted",
+			experience: []schema.ExperienceDetails{
+				{StartDate: date(2022, 1, 1), EndDate: end(date(2023, 1, 1))},
+				{StartDate: date(2010, 1, 1), EndDate: end(date(2011, 1, 1))},
+			},
+			want: 2,
+		},
+		{
+			name: "end before start is ignored",
+			experience: []schema.ExperienceDetails{
+				{StartDate: date(2020, 1, 1), EndDate: end(date(2019, 1, 1))},
+			},
+			want: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ExperienceYears(tt.experience, now)
+			if math.Abs(got-tt.want) > 0.01 {
+				t.Errorf("ExperienceYears() = %.3f, want %.3f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScoreApplication(t *testing.T) {
+	now := date(2024, 1, 1)
+	fiveYears := date(2019, 1, 1)
+
+	tests := []struct {
+		name         string
+		requirements []string
+		application  schema.ApplicationDetails
+		want         float64
+		matched      []string
+		missing      []string
+	}{
+		{
+			name:         "no requirements scores zero",
+			requirements: nil,
+			application:  schema.ApplicationDetails{Skills: []string{"Go"}},
+			want:         0,
+			matched:      []string{},
+			missing:      []string{},
+		},
+		{
+			name:         "skills are credited by level and matched loosely",
+			requirements: []string{"Node.js", "Go"},
+			application: schema.ApplicationDetails{
+				Skills:      []string{"nodejs", "go"},
+				SkillLevels: map[string]string{"nodejs": "Expert", "go": "Beginner"},
+			},
+			want:    70,
+			matched: []string{"Node.js", "Go"},
+			missing: []string{},
+		},
+		{
+			name:         "unknown level counts as beginner",
+			requirements: []string{"Go", "Rust"},
+			application: schema.ApplicationDetails{
+				Skills: []string{"Go"},
+			},
+			want:    20,
+			matched: []string{"Go"},
+			missing: []string{"Rust"},
+		},
+		{
+			name:         "experience is capped at the requirement",
+			requirements: []string{"3 years"},
+			application: schema.ApplicationDetails{
+				Experience: []schema.ExperienceDetails{{StartDate: fiveYears}},
+			},
+			want:    100,
+			matched: []string{},
+			missing: []string{},
+		},
+		{
+			name:         "weights are rescaled to the components required",
+			requirements: []string{"Go", "10 years", "Master's degree"},
+			application: schema.ApplicationDetails{
+				Skills:      []string{"Go"},
+				SkillLevels: map[string]string{"Go": "Expert"},
+				Experience:  []schema.ExperienceDetails{{StartDate: fiveYears}},
+				Education:   []schema.EducationDetails{{Level: "Bachelor of Science"}},
+			},
+			// skills 100*0.60 + experience 50*0.25 + education 75*0.15
+			want:    83.75,
+			matched: []string{"Go"},
+			missing: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ScoreApplication(ParseRequirements(tt.requirements), tt.application, now)
+			if math.Abs(got.Score-tt.want) > 0.1 {
+				t.Errorf("Score = %.2f, want %.2f (breakdown %+v)", got.Score, tt.want, got.Breakdown)
+			}
+			if !reflect.DeepEqual(got.Breakdown.MatchedSkills, tt.matched) {
+				t.Errorf("MatchedSkills = %q, want %q", got.Breakdown.MatchedSkills, tt.matched)
+			}
+			if !reflect.DeepEqual(got.Breakdown.MissingSkills, tt.missing) {
+				t.Errorf("MissingSkills = %q, want %q", got.Breakdown.MissingSkills, tt.missing)
+			}
+		})
+	}
+}
+
+func TestSortRankedApplications(t *testing.T) {
+	ranked := func() []schema.RankedApplication {
+		return []schema.RankedApplication{
+			{ApplicationDetails: schema.ApplicationDetails{ApplicationID: 1, AppliedDate: date(2024, 1, 3)}, Score: 50},
+			{ApplicationDetails: schema.ApplicationDetails{ApplicationID: 2, AppliedDate: date(2024, 1, 1)}, Score: 80},
+			{ApplicationDetails: schema.ApplicationDetails{ApplicationID: 3, AppliedDate: date(2024, 1, 2)}, Score: 50},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		sortBy     string
+		descending bool
+		want       []int
+	}{
+		{"score descending keeps earliest first on ties", "score", true, []int{2, 3, 1}},
+		{"score ascending keeps earliest first on ties", "score", false, []int{3, 1, 2}},
+		{"applied date ascending", "applied_date", false, []int{2, 3, 1}},
+		{"applied date descending", "applied_date", true, []int{1, 3, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			applications := ranked()
+			SortRankedApplications(applications, tt.sortBy, tt.descending)
+			got := make([]int, len(applications))
+			for i, application := range applications {
+				got[i] = application.ApplicationID
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("order = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
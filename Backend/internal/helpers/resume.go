@@ -0,0 +1,71 @@
+package helpers
+
+import (
+	"archive/zip"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// Accepted resume MIME types
+const (
+	MimePDF  = "application/pdf"
+	MimeDOCX = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+)
+
+// MaxResumeSize is the largest resume file accepted, in bytes
+const MaxResumeSize = 5 << 20
+
+var ErrUnsupportedResume = errors.New("resume must be a PDF or DOCX file")
+
+// DetectResumeType sniffs the content of an uploaded resume rather than
+// trusting its extension or Content-Type header. DOCX files are ZIP archives,
+// so they are additionally checked for a Word document part.
+func DetectResumeType(file multipart.File, size int64, filename string) (string, error) {
+	header := make([]byte, 512)
+	n, err := file.Read(header)
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	switch http.DetectContentType(header[:n]) {
+	case MimePDF:
+		return MimePDF, nil
+	case "application/zip":
+		if !strings.EqualFold(filepath.Ext(filename), ".docx") {
+			return "", ErrUnsupportedResume
+		}
+		archive, err := zip.NewReader(file, size)
+		if err != nil {
+			return "", ErrUnsupportedResume
+		}
+		for _, part := range archive.File {
+			if part.Name == "word/document.xml" {
+				return MimeDOCX, nil
+			}
+		}
+	}
+	return "", ErrUnsupportedResume
+}
+
+// ResumeFileName builds an unguessable stored file name for a resume
+func ResumeFileName(mimeType string) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+
+	extension := ".pdf"
+	if mimeType == MimeDOCX {
+		extension = ".docx"
+	}
+	return hex.EncodeToString(random) + extension, nil
+}
@@ -0,0 +1,358 @@
+package helpers
+
+import (
+	"Backend/internal/schema"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sectionHeadings maps common resume headings to the section they start.
+// Headings of sections that are not extracted end the previous section.
+var sectionHeadings = map[string]string{
+	"education":               "education",
+	"academic background":     "education",
+	"academic qualifications": "education",
+	"qualifications":          "education",
+	"experience":              "experience",
+	"work experience":         "experience",
+	"professional experience": "experience",
+	"employment history":      "experience",
+	"work history":            "experience",
+	"employment":              "experience",
+	"skills":                  "skills",
+	"technical skills":        "skills",
+	"key skills":              "skills",
+	"core competencies":       "skills",
+	"summary":                 "other",
+	"profile":                 "other",
+	"objective":               "other",
+	"projects":                "other",
+	"certifications":          "other",
+	"languages":               "other",
+	"interests":               "other",
+	"hobbies":                 "other",
+	"awards":                  "other",
+	"achievements":            "other",
+	"publications":            "other",
+	"references":              "other",
+}
+
+const monthPattern = `(?:jan|feb|mar|apr|may|jun|jul|aug|sep|sept|oct|nov|dec)[a-z]*\.?\s+\d{4}`
+
+var (
+	dateRangePattern   = regexp.MustCompile(`(?i)(` + monthPattern + `|\d{1,2}/\d{4}|\d{4})\s*(?:-|–|—|to)\s*(` + monthPattern + `|\d{1,2}/\d{4}|\d{4}|present|current|now)`)
+	yearPattern        = regexp.MustCompile(`\b(19|20)\d{2}\b`)
+	gradePattern       = regexp.MustCompile(`(?i)(?:c?gpa|grade|percentage|score)\s*[:\-]?\s*([\d.]+\s*%?(?:\s*/\s*[\d.]+)?)`)
+	skillLevelPattern  = regexp.MustCompile(`(?i)\s*[(\-–:]?\s*\b(beginner|intermediate|advanced|expert)\b\s*\)?`)
+	skillSeparators    = regexp.MustCompile(`[,;|•·]`)
+	headerSeparators   = regexp.MustCompile(`\s+(?:at|@|\||-|–|—)\s+|,\s+`)
+	institutionPattern = regexp.MustCompile(`(?i)\b(university|college|institute|school|academy|polytechnic)\b`)
+	bulletPrefix       = regexp.MustCompile(`^[\s•·*\-–▪◦]+`)
+)
+
+// ParseResumeText extracts education, experience and skills from resume text.
+// It relies on section headings and date ranges, so the result is a draft:
+// entries it could not fully read are kept and explained in Warnings.
+func ParseResumeText(text string, now time.Time) schema.ProfileDraft {
+	draft := schema.ProfileDraft{
+		Education:  []schema.Education{},
+		Experience: []schema.Experience{},
+		Skills:     []schema.Skill{},
+	}
+
+	sections := make(map[string][]string)
+	current := ""
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		heading := strings.ToLower(strings.TrimRight(line, ": "))
+		if section, ok := sectionHeadings[heading]; ok {
+			current = section
+			continue
+		}
+		if current != "" {
+			sections[current] = append(sections[current], line)
+		}
+	}
+
+	if len(sections["education"]) == 0 && len(sections["experience"]) == 0 && len(sections["skills"]) == 0 {
+		draft.Warnings = append(draft.Warnings, "No education, experience or skills sections were recognised")
+	}
+
+	draft.Skills = parseSkills(sections["skills"])
+	draft.Experience = parseExperience(sections["experience"], now, &draft.Warnings)
+	draft.Education = parseEducation(sections["education"], &draft.Warnings)
+	return draft
+}
+
+func parseSkills(lines []string) []schema.Skill {
+	skills := []schema.Skill{}
+	seen := make(map[string]bool)
+	for _, line := range lines {
+		line = bulletPrefix.ReplaceAllString(line, "")
+		// Drop category labels such as "Languages: Go, Python"
+		if label, rest, found := strings.Cut(line, ":"); found && len(label) < 30 && !skillSeparators.MatchString(label) {
+			line = rest
+		}
+
+		for _, item := range skillSeparators.Split(line, -1) {
+			level := "Intermediate"
+			if match := skillLevelPattern.FindStringSubmatch(item); match != nil {
+				level = capitalize(match[1])
+				item = skillLevelPattern.ReplaceAllString(item, "")
+			}
+			name := strings.Trim(strings.TrimSpace(item), ".()")
+			key := normalizeSkill(name)
+			if key == "" || len(name) > 50 || seen[key] {
+				continue
+			}
+			seen[key] = true
+			skills = append(skills, schema.Skill{SkillName: name, SkillProficiency: level})
+		}
+	}
+	return skills
+}
+
+// parseExperience starts a new entry at every line holding a date range. The
+// rest of that line, or failing that the plain lines just before it, is read
+// as "title at company, location". Bullet lines are descriptions and skipped.
+func parseExperience(lines []string, now time.Time, warnings *[]string) []schema.Experience {
+	experience := []schema.Experience{}
+	var header []string
+
+	for _, line := range lines {
+		if bulletPrefix.MatchString(line) && !dateRangePattern.MatchString(line) {
+			header = nil
+			continue
+		}
+
+		match := dateRangePattern.FindStringSubmatchIndex(line)
+		if match == nil {
+			header = append(header, line)
+			if len(header) > 2 {
+				header = header[1:]
+			}
+			continue
+		}
+
+		start, ok := parseResumeDate(line[match[2]:match[3]], false)
+		if !ok {
+			*warnings = append(*warnings, fmt.Sprintf("Could not read the dates in %q", line))
+			header = nil
+			continue
+		}
+		var end *time.Time
+		if endDate, ok := parseResumeDate(line[match[4]:match[5]], true); ok && endDate.Before(now) {
+			end = &endDate
+		}
+
+		rest := strings.Trim(line[:match[0]]+" "+line[match[1]:], " ,|-–—()")
+		if rest != "" {
+			header = append(header, rest)
+		}
+		parts := headerSeparators.Split(strings.Join(header, ", "), -1)
+		header = nil
+
+		entry := schema.Experience{StartDate: start, EndDate: end}
+		if len(parts) > 0 {
+			entry.JobTitle = strings.TrimSpace(parts[0])
+		}
+		if len(parts) > 1 {
+			entry.CompanyName = strings.TrimSpace(parts[1])
+		}
+		if len(parts) > 2 {
+			entry.Location = strings.TrimSpace(strings.Join(parts[2:], ", "))
+		}
+		if entry.JobTitle == "" || entry.CompanyName == "" {
+			*warnings = append(*warnings, fmt.Sprintf("Could not determine the job title and company for the role starting %s", start.Format("Jan 2006")))
+		}
+		experience = append(experience, entry)
+	}
+	return experience
+}
+
+// parseEducation groups lines into one entry per degree. A line naming a
+// degree starts a new entry, as does a second institution line.
+func parseEducation(lines []string, warnings *[]string) []schema.Education {
+	var blocks [][]string
+	hasDegree, hasInstitution := false, false
+	for _, line := range lines {
+		line = bulletPrefix.ReplaceAllString(line, "")
+		isDegree, isInstitution := false, false
+		for _, segment := range educationSegments(line) {
+			if institutionPattern.MatchString(segment) {
+				isInstitution = true
+			} else if educationRank(segment) > 0 {
+				isDegree = true
+			}
+		}
+
+		if len(blocks) == 0 || (isDegree && hasDegree) || (isInstitution && hasInstitution) {
+			blocks = append(blocks, nil)
+			hasDegree, hasInstitution = false, false
+		}
+		hasDegree = hasDegree || isDegree
+		hasInstitution = hasInstitution || isInstitution
+		blocks[len(blocks)-1] = append(blocks[len(blocks)-1], line)
+	}
+
+	education := []schema.Education{}
+	for _, block := range blocks {
+		var entry schema.Education
+		var years []int
+		for _, line := range block {
+			for _, year := range yearPattern.FindAllString(line, -1) {
+				value, _ := strconv.Atoi(year)
+				years = append(years, value)
+			}
+			if match := gradePattern.FindStringSubmatch(line); match != nil && entry.Grade == "" {
+				entry.Grade = strings.TrimSpace(match[1])
+			}
+
+			for _, segment := range educationSegments(line) {
+				switch {
+				case institutionPattern.MatchString(segment):
+					if entry.Institution == "" {
+						entry.Institution = segment
+					}
+				case educationRank(segment) > 0 && entry.Level == "":
+					entry.Level, entry.FieldOfStudy = splitDegree(segment)
+				case entry.Level != "" && entry.FieldOfStudy == "":
+					entry.FieldOfStudy = segment
+				}
+			}
+		}
+
+		if len(years) > 0 {
+			first, last := years[0], years[0]
+			for _, year := range years {
+				first = min(first, year)
+				last = max(last, year)
+			}
+			entry.StartYear, entry.EndYear = strconv.Itoa(first), strconv.Itoa(last)
+		}
+
+		if entry.Level == "" && entry.Institution == "" {
+			continue
+		}
+		if entry.Level == "" || entry.Institution == "" || entry.FieldOfStudy == "" || len(years) < 2 {
+			*warnings = append(*warnings, fmt.Sprintf("Education entry %q is incomplete; please review it", strings.Join(block, " ")))
+		}
+		education = append(education, entry)
+	}
+	return education
+}
+
+// educationSegments splits an education line such as "B.Tech in CSE, ABC
+// University | 2014 - 2018 | CGPA 8.5" into its text parts, without the
+// dates and grade
+func educationSegments(line string) []string {
+	line = yearPattern.ReplaceAllString(dateRangePattern.ReplaceAllString(gradePattern.ReplaceAllString(line, ""), ""), "")
+
+	var segments []string
+	for _, segment := range headerSeparators.Split(line, -1) {
+		if segment = strings.Trim(segment, " ,|-–—():"); segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return segments
+}
+
+// splitDegree separates "Bachelor of Technology in Computer Science" into the
+// degree and the field of study
+func splitDegree(segment string) (string, string) {
+	if degree, field, found := strings.Cut(segment, " in "); found {
+		return strings.TrimSpace(degree), strings.TrimSpace(field)
+	}
+	return segment, ""
+}
+
+// parseResumeDate reads "Mar 2021", "03/2021" or "2021". End dates such as
+// "Present" are reported as not parsed so the role is treated as ongoing.
+// Year-only end dates are placed at the end of that year.
+func parseResumeDate(value string, isEnd bool) (time.Time, bool) {
+	value = strings.TrimSpace(strings.ToLower(strings.ReplaceAll(value, ".", "")))
+	switch value {
+	case "present", "current", "now":
+		return time.Time{}, false
+	}
+
+	if fields := strings.Fields(value); len(fields) == 2 && len(fields[0]) >= 3 {
+		if date, err := time.Parse("Jan 2006", capitalize(fields[0][:3])+" "+fields[1]); err == nil {
+			return date, true
+		}
+	}
+	if date, err := time.Parse("1/2006", value); err == nil {
+		return date, true
+	}
+	if year, err := strconv.Atoi(value); err == nil && year > 1900 {
+		if isEnd {
+			return time.Date(year, time.December, 1, 0, 0, 0, 0, time.UTC), true
+		}
+		return time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC), true
+	}
+	return time.Time{}, false
+}
+
+func capitalize(word string) string {
+	if word == "" {
+		return word
+	}
+	return strings.ToUpper(word[:1]) + strings.ToLower(word[1:])
+}
+
+// MergeProfileDraft adds an accepted draft to a job seeker's profile. With
+// replace the draft's sections overwrite the profile's; otherwise only
+// entries the profile does not already contain are added.
+func MergeProfileDraft(profile *schema.JobSeeker, draft schema.ProfileDraft, replace bool) {
+	if replace {
+		profile.Education = draft.Education
+		profile.Experience = draft.Experience
+		profile.Skills = draft.Skills
+		return
+	}
+
+	for _, education := range draft.Education {
+		exists := false
+		for _, existing := range profile.Education {
+			if strings.EqualFold(existing.Level, education.Level) && strings.EqualFold(existing.Institution, education.Institution) {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			profile.Education = append(profile.Education, education)
+		}
+	}
+
+	for _, experience := range draft.Experience {
+		exists := false
+		for _, existing := range profile.Experience {
+			if strings.EqualFold(existing.CompanyName, experience.CompanyName) && existing.StartDate.Equal(experience.StartDate) {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			profile.Experience = append(profile.Experience, experience)
+		}
+	}
+
+	for _, skill := range draft.Skills {
+		exists := false
+		for _, existing := range profile.Skills {
+			if normalizeSkill(existing.SkillName) == normalizeSkill(skill.SkillName) {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			profile.Skills = append(profile.Skills, skill)
+		}
+	}
+}
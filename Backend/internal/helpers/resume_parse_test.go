@@ -0,0 +1,193 @@
+package helpers
+
+import (
+	"Backend/internal/schema"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseResumeTextSkills(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []schema.Skill
+	}{
+		{
+			name: "comma separated with default level",
+			text: "Skills\nGo, PostgreSQL",
+			want: []schema.Skill{{SkillName: "Go", SkillProficiency: "Intermediate"}, {SkillName: "PostgreSQL", SkillProficiency: "Intermediate"}},
+		},
+		{
+			name: "levels and category labels",
+			text: "Technical Skills:\n• Languages: Go (Expert); Python - beginner",
+			want: []schema.Skill{{SkillName: "Go", SkillProficiency: "Expert"}, {SkillName: "Python", SkillProficiency: "Beginner"}},
+		},
+		{
+			name: "duplicates are dropped",
+			text: "Skills\nNode.js | nodejs | React",
+			want: []schema.Skill{{SkillName: "Node.js", SkillProficiency: "Intermediate"}, {SkillName: "React", SkillProficiency: "Intermediate"}},
+		},
+		{
+			name: "other sections end the skills section",
+			text: "Skills\nGo\nInterests\nChess",
+			want: []schema.Skill{{SkillName: "Go", SkillProficiency: "Intermediate"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseResumeText(tt.text, time.Now()).Skills
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Skills = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseResumeTextExperience(t *testing.T) {
+	now := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+	month := func(year int, month time.Month) time.Time { return time.Date(year, month, 1, 0, 0, 0, 0, time.UTC) }
+	end := func(t time.Time) *time.Time { return &t }
+
+	tests := []struct {
+		name     string
+		text     string
+		want     []schema.Experience
+		warnings int
+	}{
+		{
+			name: "header and dates on one line",
+			text: "Experience\nSoftware Engineer at Acme, Berlin  Mar 2020 - Present\n• Built things",
+			want: []schema.Experience{{JobTitle: "Software Engineer", CompanyName: "Acme", Location: "Berlin", StartDate: month(2020, time.March)}},
+		},
+		{
+			name: "header on the lines before the dates",
+			text: "Work Experience\nBackend Developer\nGlobex\n01/2018 - 12/2019",
+			want: []schema.Experience{{JobTitle: "Backend Developer", CompanyName: "Globex", StartDate: month(2018, time.January), EndDate: end(month(2019, time.December))}},
+		},
+		{
+			name: "year-only end dates fall at the end of the year",
+			text: "Experience\nIntern | Initech 2015 to 2016",
+			want: []schema.Experience{{JobTitle: "Intern", CompanyName: "Initech", StartDate: month(2015, time.January), EndDate: end(month(2016, time.December))}},
+		},
+		{
+			name:     "missing company is warned about",
+			text:     "Experience\nFreelancer 2019 - 2020",
+			want:     []schema.Experience{{JobTitle: "Freelancer", StartDate: month(2019, time.January), EndDate: end(month(2020, time.December))}},
+			warnings: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			draft := ParseResumeText(tt.text, now)
+			if !reflect.DeepEqual(draft.Experience, tt.want) {
+				t.Errorf("Experience = %+v, want %+v", draft.Experience, tt.want)
+			}
+			if len(draft.Warnings) != tt.warnings {
+				t.Errorf("Warnings = %q, want %d", draft.Warnings, tt.warnings)
+			}
+		})
+	}
+}
+
+func TestParseResumeTextEducation(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		want     []schema.Education
+		warnings int
+	}{
+		{
+			name: "single line entry",
+			text: "Education\nB.Tech in Computer Science, ABC University | 2014 - 2018 | CGPA 8.5",
+			want: []schema.Education{{Level: "B.Tech", FieldOfStudy: "Computer Science", Institution: "ABC University", StartYear: "2014", EndYear: "2018", Grade: "8.5"}},
+		},
+		{
+			name: "entry spread over lines",
+			text: "Education\nMaster of Science in Data Science\nXYZ Institute of Technology\n2019 - 2021",
+			want: []schema.Education{{Level: "Master of Science", FieldOfStudy: "Data Science", Institution: "XYZ Institute of Technology", StartYear: "2019", EndYear: "2021"}},
+		},
+		{
+			name: "a second degree starts a new entry",
+			text: "Education\nPhD in Physics, Oxford University, 2015 - 2019\nBachelor in Physics, Leeds University, 2011 - 2014",
+			want: []schema.Education{
+				{Level: "PhD", FieldOfStudy: "Physics", Institution: "Oxford University", StartYear: "2015", EndYear: "2019"},
+				{Level: "Bachelor", FieldOfStudy: "Physics", Institution: "Leeds University", StartYear: "2011", EndYear: "2014"},
+			},
+		},
+		{
+			name:     "incomplete entries are warned about",
+			text:     "Education\nSpringfield High School",
+			want:     []schema.Education{{Institution: "Springfield High School"}},
+			warnings: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			draft := ParseResumeText(tt.text, time.Now())
+			if !reflect.DeepEqual(draft.Education, tt.want) {
+				t.Errorf("Education = %+v, want %+v", draft.Education, tt.want)
+			}
+			if len(draft.Warnings) != tt.warnings {
+				t.Errorf("Warnings = %q, want %d", draft.Warnings, tt.warnings)
+			}
+		})
+	}
+}
+
+func TestParseResumeTextWithoutSections(t *testing.T) {
+	draft := ParseResumeText("Jane Doe\njane@example.com", time.Now())
+	if len(draft.Warnings) != 1 || !strings.Contains(draft.Warnings[0], "No education") {
+		t.Errorf("Warnings = %q, want the no sections warning", draft.Warnings)
+	}
+	if draft.Skills == nil || draft.Experience == nil || draft.Education == nil {
+		t.Error("sections should be empty slices, not nil")
+	}
+}
+
+func TestMergeProfileDraft(t *testing.T) {
+	start := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	profile := func() schema.JobSeeker {
+		return schema.JobSeeker{
+			Password:   "hash",
+			Skills:     []schema.Skill{{SkillName: "Node.js", SkillProficiency: "Expert"}},
+			Education:  []schema.Education{{Level: "BSc", Institution: "ABC University"}},
+			Experience: []schema.Experience{{JobTitle: "Engineer", CompanyName: "Acme", StartDate: start}},
+		}
+	}
+	draft := schema.ProfileDraft{
+		Skills:     []schema.Skill{{SkillName: "nodejs", SkillProficiency: "Beginner"}, {SkillName: "Go", SkillProficiency: "Advanced"}},
+		Education:  []schema.Education{{Level: "bsc", Institution: "abc university"}, {Level: "MSc", Institution: "XYZ Institute"}},
+		Experience: []schema.Experience{{JobTitle: "Senior Engineer", CompanyName: "ACME", StartDate: start}, {JobTitle: "Lead", CompanyName: "Globex", StartDate: start}},
+	}
+
+	t.Run("merge adds only new entries", func(t *testing.T) {
+		merged := profile()
+		MergeProfileDraft(&merged, draft, false)
+		if len(merged.Skills) != 2 || merged.Skills[0].SkillProficiency != "Expert" || merged.Skills[1].SkillName != "Go" {
+			t.Errorf("Skills = %+v", merged.Skills)
+		}
+		if len(merged.Education) != 2 || merged.Education[1].Level != "MSc" {
+			t.Errorf("Education = %+v", merged.Education)
+		}
+		if len(merged.Experience) != 2 || merged.Experience[0].JobTitle != "Engineer" || merged.Experience[1].CompanyName != "Globex" {
+			t.Errorf("Experience = %+v", merged.Experience)
+		}
+		if merged.Password != "hash" {
+			t.Errorf("Password = %q, want it untouched", merged.Password)
+		}
+	})
+
+	t.Run("replace overwrites the sections", func(t *testing.T) {
+		replaced := profile()
+		MergeProfileDraft(&replaced, draft, true)
+		if !reflect.DeepEqual(replaced.Skills, draft.Skills) || !reflect.DeepEqual(replaced.Education, draft.Education) ||
+			!reflect.DeepEqual(replaced.Experience, draft.Experience) {
+			t.Errorf("profile = %+v, want the draft's sections", replaced)
+		}
+	})
+}
@@ -0,0 +1,258 @@
+package helpers
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"encoding/xml"
+	"errors"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// maxExtractedSize bounds how much decompressed data is read from a resume,
+// protecting the server from compression bombs
+const maxExtractedSize = 20 << 20
+
+var (
+	pdfStreamPattern = regexp.MustCompile(`>>\s*stream\r?\n`)
+	blankLines       = regexp.MustCompile(`\n\s*\n+`)
+)
+
+// ExtractResumeText converts a stored PDF or DOCX resume into plain text with
+// one line per paragraph or text line. It works offline and only understands
+// text-based documents; scanned resumes yield no text.
+func ExtractResumeText(path, mimeType string) (string, error) {
+	var text string
+	var err error
+	switch mimeType {
+	case MimePDF:
+		var data []byte
+		data, err = os.ReadFile(path)
+		if err == nil {
+			text = extractPDFText(data)
+		}
+	case MimeDOCX:
+		text, err = extractDOCXText(path)
+	default:
+		return "", ErrUnsupportedResume
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(blankLines.ReplaceAllString(text, "\n")), nil
+}
+
+func extractDOCXText(path string) (string, error) {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return "", err
+	}
+	defer archive.Close()
+
+	for _, part := range archive.File {
+		if part.Name != "word/document.xml" {
+			continue
+		}
+		reader, err := part.Open()
+		if err != nil {
+			return "", err
+		}
+		defer reader.Close()
+
+		var out strings.Builder
+		decoder := xml.NewDecoder(io.LimitReader(reader, maxExtractedSize))
+		inText := false
+		for {
+			token, err := decoder.Token()
+			if err == io.EOF {
+				return out.String(), nil
+			}
+			if err != nil {
+				return "", err
+			}
+			switch element := token.(type) {
+			case xml.StartElement:
+				switch element.Name.Local {
+				case "t":
+					inText = true
+				case "tab":
+					out.WriteString("\t")
+				case "br":
+					out.WriteString("\n")
+				}
+			case xml.EndElement:
+				switch element.Name.Local {
+				case "t":
+					inText = false
+				case "p":
+					out.WriteString("\n")
+				}
+			case xml.CharData:
+				if inText {
+					out.Write(element)
+				}
+			}
+		}
+	}
+	return "", errors.New("document has no word/document.xml part")
+}
+
+// extractPDFText reads the text-showing operators of every content stream.
+// Compressed streams are inflated; streams that are not page content (fonts,
+// images) simply produce no text.
+func extractPDFText(data []byte) string {
+	var out strings.Builder
+	for _, match := range pdfStreamPattern.FindAllIndex(data, -1) {
+		// The stream dictionary starts after the "obj" keyword of its object
+		dictionaryStart := bytes.LastIndex(data[:match[0]], []byte("obj"))
+		if dictionaryStart < 0 {
+			dictionaryStart = 0
+		}
+		dictionary := data[dictionaryStart:match[0]]
+		if bytes.Contains(dictionary, []byte("/Image")) || bytes.Contains(dictionary, []byte("/Length1")) {
+			continue
+		}
+
+		start := match[1]
+		end := bytes.Index(data[start:], []byte("endstream"))
+		if end < 0 {
+			break
+		}
+		content := data[start : start+end]
+
+		if bytes.Contains(dictionary, []byte("/FlateDecode")) {
+			reader, err := zlib.NewReader(bytes.NewReader(content))
+			if err != nil {
+				continue
+			}
+			// Truncated streams still yield their readable prefix
+			content, _ = io.ReadAll(io.LimitReader(reader, maxExtractedSize))
+			reader.Close()
+		}
+		extractPDFContentText(content, &out)
+	}
+	return out.String()
+}
+
+// extractPDFContentText interprets just enough of a PDF content stream to
+// collect the strings passed to Tj, TJ, ' and " and to break lines on text
+// positioning operators. Large negative kerning inside TJ arrays is read as a
+// word space.
+func extractPDFContentText(content []byte, out *strings.Builder) {
+	var pending []string
+	inArray := false
+	newline := func() {
+		if s := out.String(); len(s) > 0 && !strings.HasSuffix(s, "\n") {
+			out.WriteString("\n")
+		}
+	}
+
+	for i := 0; i < len(content); {
+		ch := content[i]
+		switch {
+		case ch == '(':
+			text, next := readPDFString(content, i)
+			pending = append(pending, text)
+			i = next
+		case ch == '<' && i+1 < len(content) && content[i+1] == '<':
+			i += 2
+		case ch == '<':
+			// Hex strings need the font's character map, which is not parsed
+			for i < len(content) && content[i] != '>' {
+				i++
+			}
+			i++
+		case ch == '[':
+			inArray = true
+			i++
+		case ch == ']':
+			inArray = false
+			i++
+		case ch == '%':
+			for i < len(content) && content[i] != '\n' && content[i] != '\r' {
+				i++
+			}
+		case ch == '-' || ch == '.' || (ch >= '0' && ch <= '9'):
+			start := i
+			for i < len(content) && (content[i] == '-' || content[i] == '.' || (content[i] >= '0' && content[i] <= '9')) {
+				i++
+			}
+			if inArray && content[start] == '-' && len(content[start:i]) >= 4 {
+				pending = append(pending, " ")
+			}
+		case (ch >= 'A' && ch <= 'Z') || (ch >= 'a' && ch <= 'z') || ch == '\'' || ch == '"' || ch == '*':
+			start := i
+			for i < len(content) && ((content[i] >= 'A' && content[i] <= 'Z') || (content[i] >= 'a' && content[i] <= 'z') || content[i] == '*' || content[i] == '\'' || content[i] == '"') {
+				i++
+			}
+			switch string(content[start:i]) {
+			case "Tj", "TJ":
+				out.WriteString(strings.Join(pending, ""))
+			case "'", "\"":
+				newline()
+				out.WriteString(strings.Join(pending, ""))
+			case "Td", "TD", "T*", "Tm", "ET":
+				newline()
+			}
+			pending = pending[:0]
+		default:
+			i++
+		}
+	}
+	newline()
+}
+
+// readPDFString decodes a literal string starting at the opening parenthesis
+// and returns it with the index just past the closing parenthesis
+func readPDFString(content []byte, start int) (string, int) {
+	var text strings.Builder
+	depth := 0
+	for i := start; i < len(content); i++ {
+		ch := content[i]
+		switch ch {
+		case '(':
+			if depth > 0 {
+				text.WriteByte(ch)
+			}
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return text.String(), i + 1
+			}
+			text.WriteByte(ch)
+		case '\\':
+			i++
+			if i >= len(content) {
+				break
+			}
+			switch escaped := content[i]; escaped {
+			case 'n':
+				text.WriteByte('\n')
+			case 'r', '\n':
+				// Line continuation or carriage return; neither adds text
+			case 't':
+				text.WriteByte('\t')
+			case 'b', 'f':
+			default:
+				if escaped >= '0' && escaped <= '7' {
+					value := 0
+					for n := 0; n < 3 && i < len(content) && content[i] >= '0' && content[i] <= '7'; n++ {
+						value = value*8 + int(content[i]-'0')
+						i++
+					}
+					i--
+					text.WriteRune(rune(value & 0xff))
+				} else {
+					text.WriteByte(escaped)
+				}
+			}
+		default:
+			text.WriteRune(rune(ch))
+		}
+	}
+	return text.String(), len(content)
+}
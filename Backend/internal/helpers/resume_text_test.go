@@ -0,0 +1,190 @@
+package helpers
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeDOCX writes a minimal DOCX whose body is the given document.xml content
+func writeDOCX(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "resume.docx")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	archive := zip.NewWriter(file)
+	part, err := archive.Create("word/document.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprintf(part, `<?xml version="1.0" encoding="UTF-8"?>`+
+		`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>%s</w:body></w:document>`, body)
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// writePDF writes a minimal PDF with one content stream per entry of streams.
+// Streams are stored compressed when compress is set.
+func writePDF(t *testing.T, compress bool, streams ...string) string {
+	t.Helper()
+	var pdf bytes.Buffer
+	pdf.WriteString("%PDF-1.4\n")
+	for i, stream := range streams {
+		content := []byte(stream)
+		filter := ""
+		if compress {
+			var compressed bytes.Buffer
+			writer := zlib.NewWriter(&compressed)
+			writer.Write(content)
+			writer.Close()
+			content = compressed.Bytes()
+			filter = " /Filter /FlateDecode"
+		}
+		fmt.Fprintf(&pdf, "%d 0 obj\n<< /Length %d%s >>\nstream\n", i+1, len(content), filter)
+		pdf.Write(content)
+		pdf.WriteString("\nendstream\nendobj\n")
+	}
+	pdf.WriteString("%%EOF\n")
+
+	path := filepath.Join(t.TempDir(), "resume.pdf")
+	if err := os.WriteFile(path, pdf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestExtractResumeTextDOCX(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "one line per paragraph",
+			body: `<w:p><w:r><w:t>Jane Doe</w:t></w:r></w:p><w:p><w:r><w:t>Skills</w:t></w:r></w:p>`,
+			want: "Jane Doe\nSkills",
+		},
+		{
+			name: "runs of a paragraph are joined",
+			body: `<w:p><w:r><w:t xml:space="preserve">Go, </w:t></w:r><w:r><w:t>SQL</w:t></w:r></w:p>`,
+			want: "Go, SQL",
+		},
+		{
+			name: "tabs and breaks",
+			body: `<w:p><w:r><w:t>Engineer</w:t><w:tab/><w:t>2020</w:t><w:br/><w:t>Acme</w:t></w:r></w:p>`,
+			want: "Engineer\t2020\nAcme",
+		},
+		{
+			name: "empty paragraphs collapse",
+			body: `<w:p><w:r><w:t>A</w:t></w:r></w:p><w:p/><w:p/><w:p><w:r><w:t>B</w:t></w:r></w:p>`,
+			want: "A\nB",
+		},
+		{
+			name: "entities are decoded",
+			body: `<w:p><w:r><w:t>R&amp;D &lt;Team&gt;</w:t></w:r></w:p>`,
+			want: "R&D <Team>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExtractResumeText(writeDOCX(t, tt.body), MimeDOCX)
+			if err != nil {
+				t.Fatalf("ExtractResumeText() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ExtractResumeText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractResumeTextDOCXWithoutDocument(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "empty.docx")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive := zip.NewWriter(file)
+	archive.Create("word/styles.xml")
+	archive.Close()
+	file.Close()
+
+	if _, err := ExtractResumeText(path, MimeDOCX); err == nil {
+		t.Error("ExtractResumeText() error = nil, want an error for a DOCX without word/document.xml")
+	}
+}
+
+func TestExtractResumeTextPDF(t *testing.T) {
+	tests := []struct {
+		name     string
+		compress bool
+		streams  []string
+		want     string
+	}{
+		{
+			name:    "Tj strings with text positioning",
+			streams: []string{"BT /F1 12 Tf 72 720 Td (Jane Doe) Tj 0 -14 Td (Skills) Tj ET"},
+			want:    "Jane Doe\nSkills",
+		},
+		{
+			name:     "compressed stream",
+			compress: true,
+			streams:  []string{"BT (Go, SQL) Tj ET"},
+			want:     "Go, SQL",
+		},
+		{
+			name:    "TJ arrays read large kerning as a space",
+			streams: []string{"BT [(Senior)-250(Engineer) 12 (ing)] TJ ET"},
+			want:    "Senior Engineering",
+		},
+		{
+			name:    "quote operator starts a new line",
+			streams: []string{"BT (First) Tj (Second) ' ET"},
+			want:    "First\nSecond",
+		},
+		{
+			name:    "escapes and nested parentheses",
+			streams: []string{`BT (R\(D\) \050x\051 \\ (a)) Tj ET`},
+			want:    `R(D) (x) \ (a)`,
+		},
+		{
+			name:    "hex strings and dictionaries are skipped",
+			streams: []string{"BT << /MCID 0 >> BDC <48656c6c6f> Tj (Text) Tj EMC ET"},
+			want:    "Text",
+		},
+		{
+			name:    "every content stream is read",
+			streams: []string{"BT (Page one) Tj ET", "BT (Page two) Tj ET"},
+			want:    "Page one\nPage two",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExtractResumeText(writePDF(t, tt.compress, tt.streams...), MimePDF)
+			if err != nil {
+				t.Fatalf("ExtractResumeText() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ExtractResumeText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractResumeTextUnsupported(t *testing.T) {
+	if _, err := ExtractResumeText("resume.txt", "text/plain"); err != ErrUnsupportedResume {
+		t.Errorf("ExtractResumeText() error = %v, want %v", err, ErrUnsupportedResume)
+	}
+}
@@ -0,0 +1,66 @@
+package helpers
+
+import (
+	"Backend/internal/schema"
+	"crypto/rand"
+	"encoding/hex"
+	"sort"
+	"time"
+)
+
+// URLToken builds an unguessable token for links that are their own
+// credential, such as booking links and calendar feeds
+func URLToken() (string, error) {
+	random := make([]byte, 24)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(random), nil
+}
+
+// GenerateSlots splits availability windows into consecutive slots of the
+// given duration, starting at each window's start. Slots that begin before
+// notBefore, end after notAfter or overlap a busy period are left out.
+func GenerateSlots(windows []schema.AvailabilityWindow, busy []schema.TimeSlot, duration time.Duration, notBefore, notAfter time.Time) []schema.TimeSlot {
+	slots := []schema.TimeSlot{}
+	if duration <= 0 {
+		return slots
+	}
+
+	seen := make(map[time.Time]bool)
+	for _, window := range windows {
+		for start := window.StartsAt; !start.Add(duration).After(window.EndsAt); start = start.Add(duration) {
+			slot := schema.TimeSlot{StartsAt: start, EndsAt: start.Add(duration)}
+			if slot.StartsAt.Before(notBefore) || slot.EndsAt.After(notAfter) || seen[slot.StartsAt] {
+				continue
+			}
+			if overlapsAny(slot, busy) {
+				continue
+			}
+			seen[slot.StartsAt] = true
+			slots = append(slots, slot)
+		}
+	}
+
+	sort.Slice(slots, func(i, j int) bool { return slots[i].StartsAt.Before(slots[j].StartsAt) })
+	return slots
+}
+
+// SlotInWindows reports whether a slot lies entirely inside one of the windows
+func SlotInWindows(slot schema.TimeSlot, windows []schema.AvailabilityWindow) bool {
+	for _, window := range windows {
+		if !slot.StartsAt.Before(window.StartsAt) && !slot.EndsAt.After(window.EndsAt) {
+			return true
+		}
+	}
+	return false
+}
+
+func overlapsAny(slot schema.TimeSlot, busy []schema.TimeSlot) bool {
+	for _, period := range busy {
+		if slot.StartsAt.Before(period.EndsAt) && period.StartsAt.Before(slot.EndsAt) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package helpers
+
+import (
+	"Backend/internal/schema"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func at(hour, minute int) time.Time {
+	return time.Date(2024, time.March, 4, hour, minute, 0, 0, time.UTC)
+}
+
+func window(from, to time.Time) schema.AvailabilityWindow {
+	return schema.AvailabilityWindow{StartsAt: from, EndsAt: to}
+}
+
+func slot(from, to time.Time) schema.TimeSlot {
+	return schema.TimeSlot{StartsAt: from, EndsAt: to}
+}
+
+func TestGenerateSlots(t *testing.T) {
+	dayStart, dayEnd := at(0, 0), at(23, 59)
+
+	tests := []struct {
+		name      string
+		windows   []schema.AvailabilityWindow
+		busy      []schema.TimeSlot
+		duration  time.Duration
+		notBefore time.Time
+		notAfter  time.Time
+		want      []schema.TimeSlot
+	}{
+		{
+			name:      "window split into whole slots",
+			windows:   []schema.AvailabilityWindow{window(at(9, 0), at(10, 45))},
+			duration:  30 * time.Minute,
+			notBefore: dayStart, notAfter: dayEnd,
+			want: []schema.TimeSlot{slot(at(9, 0), at(9, 30)), slot(at(9, 30), at(10, 0)), slot(at(10, 0), at(10, 30))},
+		},
+		{
+			name:      "window shorter than the duration",
+			windows:   []schema.AvailabilityWindow{window(at(9, 0), at(9, 20))},
+			duration:  30 * time.Minute,
+			notBefore: dayStart, notAfter: dayEnd,
+			want: []schema.TimeSlot{},
+		},
+		{
+			name:      "busy periods remove overlapping slots only",
+			windows:   []schema.AvailabilityWindow{window(at(9, 0), at(11, 0))},
+			busy:      []schema.TimeSlot{slot(at(9, 15), at(9, 45)), slot(at(10, 30), at(11, 0))},
+			duration:  30 * time.Minute,
+			notBefore: dayStart, notAfter: dayEnd,
+			want: []schema.TimeSlot{slot(at(10, 0), at(10, 30))},
+		},
+		{
+			name:      "busy period touching a slot does not block it",
+			windows:   []schema.AvailabilityWindow{window(at(9, 0), at(10, 0))},
+			busy:      []schema.TimeSlot{slot(at(8, 0), at(9, 0)), slot(at(10, 0), at(11, 0))},
+			duration:  time.Hour,
+			notBefore: dayStart, notAfter: dayEnd,
+			want: []schema.TimeSlot{slot(at(9, 0), at(10, 0))},
+		},
+		{
+			name:      "slots outside the bounds are left out",
+			windows:   []schema.AvailabilityWindow{window(at(9, 0), at(12, 0))},
+			duration:  time.Hour,
+			notBefore: at(9, 30), notAfter: at(11, 30),
+			want: []schema.TimeSlot{slot(at(10, 0), at(11, 0))},
+		},
+		{
+			name:      "overlapping windows yield each start once, sorted",
+			windows:   []schema.AvailabilityWindow{window(at(14, 0), at(15, 0)), window(at(9, 0), at(11, 0)), window(at(10, 0), at(11, 0))},
+			duration:  time.Hour,
+			notBefore: dayStart, notAfter: dayEnd,
+			want: []schema.TimeSlot{slot(at(9, 0), at(10, 0)), slot(at(10, 0), at(11, 0)), slot(at(14, 0), at(15, 0))},
+		},
+		{
+			name:      "zero duration",
+			windows:   []schema.AvailabilityWindow{window(at(9, 0), at(10, 0))},
+			duration:  0,
+			notBefore: dayStart, notAfter: dayEnd,
+			want: []schema.TimeSlot{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GenerateSlots(tt.windows, tt.busy, tt.duration, tt.notBefore, tt.notAfter)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GenerateSlots() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlotInWindows(t *testing.T) {
+	windows := []schema.AvailabilityWindow{window(at(9, 0), at(11, 0)), window(at(11, 0), at(12, 0))}
+
+	tests := []struct {
+		name string
+		slot schema.TimeSlot
+		want bool
+	}{
+		{"inside", slot(at(9, 30), at(10, 30)), true},
+		{"exactly a window", slot(at(11, 0), at(12, 0)), true},
+		{"starts before the window", slot(at(8, 30), at(9, 30)), false},
+		{"ends after the window", slot(at(11, 30), at(12, 30)), false},
+		{"spans two adjacent windows", slot(at(10, 30), at(11, 30)), false},
+		{"outside every window", slot(at(13, 0), at(14, 0)), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SlotInWindows(tt.slot, windows); got != tt.want {
+				t.Errorf("SlotInWindows(%v) = %v, want %v", tt.slot, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package helpers
+
+import (
+	"Backend/internal/schema"
+	"strings"
+)
+
+// RenderTemplate replaces {{placeholder}} markers in a message with values.
+// Placeholders without a value are left untouched.
+func RenderTemplate(template string, values map[string]string) string {
+	pairs := make([]string, 0, len(values)*2)
+	for key, value := range values {
+		pairs = append(pairs, "{{"+key+"}}", value)
+	}
+	return strings.NewReplacer(pairs...).Replace(template)
+}
+
+// ContactTemplateValues returns the placeholder values available for messages
+// sent to a candidate about an application
+func ContactTemplateValues(contact schema.ApplicationContact) map[string]string {
+	return map[string]string{
+		"candidate_name": strings.TrimSpace(contact.FirstName + " " + contact.LastName),
+		"first_name":     contact.FirstName,
+		"job_title":      contact.JobTitle,
+		"company":        contact.CompanyName,
+	}
+}
@@ -0,0 +1,35 @@
+package helpers
+
+import (
+	"strings"
+	"time"
+)
+
+// DefaultTimeZone is used for users who have not chosen a time zone
+const DefaultTimeZone = "UTC"
+
+// ValidTimeZone reports whether name is an IANA time zone such as Europe/Berlin
+func ValidTimeZone(name string) bool {
+	if strings.TrimSpace(name) == "" || strings.EqualFold(name, "Local") {
+		return false
+	}
+	_, err := time.LoadLocation(name)
+	return err == nil
+}
+
+// LoadTimeZone returns the location of an IANA time zone, or UTC when the
+// name is empty or unknown
+func LoadTimeZone(name string) *time.Location {
+	if !ValidTimeZone(name) {
+		return time.UTC
+	}
+	location, _ := time.LoadLocation(name)
+	return location
+}
+
+// FormatInZone renders a time for a recipient in their time zone, with the
+// zone spelled out so the reader cannot mistake it
+func FormatInZone(t time.Time, zone string) string {
+	location := LoadTimeZone(zone)
+	return t.In(location).Format("Mon, Jan 2, 2006 15:04 MST") + " (" + location.String() + ")"
+}
@@ -0,0 +1,60 @@
+package meeting
+
+import (
+	"Backend/internal/helpers"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	jitsiProviderName   = "jitsi"
+	defaultJitsiBaseURL = "https://meet.jit.si"
+)
+
+// JitsiProvider generates rooms on a Jitsi Meet server, self-hosted or the
+// public one. Rooms are created when the first person joins, so a link only
+// needs an unguessable room name.
+type JitsiProvider struct {
+	baseURL string
+}
+
+// NewJitsiProvider returns a provider for the Jitsi server at baseURL. With
+// an empty baseURL, JITSI_BASE_URL is read when a meeting is created, falling
+// back to the public meet.jit.si server.
+func NewJitsiProvider(baseURL string) *JitsiProvider {
+	return &JitsiProvider{baseURL: baseURL}
+}
+
+func (p *JitsiProvider) Name() string {
+	return jitsiProviderName
+}
+
+func (p *JitsiProvider) CreateMeeting(ctx context.Context, request Request) (Meeting, error) {
+	token, err := helpers.URLToken()
+	if err != nil {
+		return Meeting{}, err
+	}
+
+	baseURL := p.baseURL
+	if baseURL == "" {
+		baseURL = os.Getenv("JITSI_BASE_URL")
+	}
+	if baseURL == "" {
+		baseURL = defaultJitsiBaseURL
+	}
+
+	room := fmt.Sprintf("DazzleDateInterview%d-%s", request.InterviewID, token)
+	return Meeting{
+		Provider: jitsiProviderName,
+		ID:       room,
+		JoinURL:  strings.TrimRight(baseURL, "/") + "/" + room,
+	}, nil
+}
+
+// RevokeMeeting has nothing to call: Jitsi rooms have no API and vanish when
+// empty. Once the interview stops handing out the room name, nobody can find it.
+func (p *JitsiProvider) RevokeMeeting(ctx context.Context, meeting Meeting) error {
+	return nil
+}
@@ -0,0 +1,99 @@
+// Package meeting generates video meeting links for online interviews.
+package meeting
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ModeOnline is the interview mode that gets a generated meeting link
+const ModeOnline = "Online"
+
+// ErrUnknownProvider is returned for a provider name nothing is registered under
+var ErrUnknownProvider = errors.New("unknown meeting provider")
+
+// Request describes the interview a meeting is created for
+type Request struct {
+	InterviewID     int
+	Title           string
+	StartsAt        time.Time
+	DurationMinutes int
+}
+
+// Meeting is a meeting created by a provider. ID is what the provider needs
+// to revoke it later.
+type Meeting struct {
+	Provider string
+	ID       string
+	JoinURL  string
+}
+
+// MeetingProvider creates and revokes video meetings. Interviews remember the
+// name of the provider that created their meeting, so it can be revoked by
+// the same provider after the default was changed.
+type MeetingProvider interface {
+	Name() string
+	CreateMeeting(ctx context.Context, request Request) (Meeting, error)
+	RevokeMeeting(ctx context.Context, meeting Meeting) error
+}
+
+var (
+	mu        sync.RWMutex
+	providers = map[string]MeetingProvider{}
+)
+
+func init() {
+	Register(NewJitsiProvider(""))
+	Register(NewStubProvider())
+}
+
+// Register makes a provider available under its name, replacing any
+// provider registered under the same name
+func Register(provider MeetingProvider) {
+	mu.Lock()
+	defer mu.Unlock()
+	providers[provider.Name()] = provider
+}
+
+// Get returns the provider registered under a name
+func Get(name string) (MeetingProvider, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	provider, ok := providers[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	return provider, nil
+}
+
+// Default returns the provider named by MEETING_PROVIDER, or the Jitsi
+// provider when it is unset. It returns nil when MEETING_PROVIDER is "none",
+// which leaves interview links to the employer.
+func Default() (MeetingProvider, error) {
+	name := strings.ToLower(strings.TrimSpace(os.Getenv("MEETING_PROVIDER")))
+	switch name {
+	case "none":
+		return nil, nil
+	case "":
+		name = jitsiProviderName
+	}
+	return Get(name)
+}
+
+// Revoke revokes a meeting with the provider that created it
+func Revoke(ctx context.Context, meeting Meeting) error {
+	provider, err := Get(meeting.Provider)
+	if err != nil {
+		return err
+	}
+	return provider.RevokeMeeting(ctx, meeting)
+}
+
+// IsOnline reports whether an interview mode calls for a video meeting
+func IsOnline(mode string) bool {
+	return strings.EqualFold(strings.TrimSpace(mode), ModeOnline)
+}
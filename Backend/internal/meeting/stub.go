@@ -0,0 +1,56 @@
+package meeting
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+const stubProviderName = "stub"
+
+// StubProvider hands out predictable links without calling any service and
+// keeps track of which meetings are live. Select it with MEETING_PROVIDER=stub
+// for tests and local development.
+type StubProvider struct {
+	mu     sync.Mutex
+	next   int
+	active map[string]bool
+}
+
+// NewStubProvider returns an empty stub provider
+func NewStubProvider() *StubProvider {
+	return &StubProvider{active: make(map[string]bool)}
+}
+
+func (p *StubProvider) Name() string {
+	return stubProviderName
+}
+
+func (p *StubProvider) CreateMeeting(ctx context.Context, request Request) (Meeting, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.next++
+	id := fmt.Sprintf("interview-%d-%d", request.InterviewID, p.next)
+	p.active[id] = true
+	return Meeting{
+		Provider: stubProviderName,
+		ID:       id,
+		JoinURL:  "https://meet.example.invalid/" + id,
+	}, nil
+}
+
+func (p *StubProvider) RevokeMeeting(ctx context.Context, meeting Meeting) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.active, meeting.ID)
+	return nil
+}
+
+// Active reports whether a meeting was created and not revoked yet
+func (p *StubProvider) Active(id string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.active[id]
+}
@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"Backend/internal/db"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// IdempotencyKeyTTL is how long a key and its stored response are kept
+	IdempotencyKeyTTL = 24 * time.Hour
+	// IdempotencyKeyLease is how long a key stays reserved by a request that
+	// has not answered yet. Requests that die without answering, e.g. when
+	// the server is restarted, leave a reservation behind; after the lease
+	// the key can be used again.
+	IdempotencyKeyLease = 2 * time.Minute
+	// maxIdempotencyKeyLength matches the idempotency_keys column
+	maxIdempotencyKeyLength = 255
+)
+
+// idempotencyWriter keeps a copy of the response so it can be replayed
+type idempotencyWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *idempotencyWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *idempotencyWriter) WriteString(data string) (int, error) {
+	w.body.WriteString(data)
+	return w.ResponseWriter.WriteString(data)
+}
+
+// IdempotencyMiddleware makes POST requests sent with an Idempotency-Key
+// header safe to retry. The first request with a key runs normally and its
+// response is stored; repeats with the same key and body get the stored
+// response back with an Idempotent-Replayed header instead of running again.
+// Keys are scoped to the caller's token and the request path.
+func IdempotencyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader("Idempotency-Key")
+		if c.Request.Method != http.MethodPost || key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key must be at most 255 characters"})
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		scopeHash := sha256.Sum256([]byte(c.GetHeader("Authorization") + "\n" + c.Request.Method + " " + c.Request.URL.Path))
+		scope := hex.EncodeToString(scopeHash[:])
+		bodyHash := sha256.Sum256(body)
+		requestHash := hex.EncodeToString(bodyHash[:])
+
+		record, reserved, err := db.ReserveIdempotencyKey(context.Background(), key, scope, requestHash, IdempotencyKeyTTL, IdempotencyKeyLease)
+		if err != nil {
+			log.Println("Failed to reserve idempotency key:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process Idempotency-Key"})
+			c.Abort()
+			return
+		}
+
+		if !reserved {
+			switch {
+			case record.RequestHash != requestHash:
+				c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Idempotency-Key was already used with a different request"})
+			case record.StatusCode == nil:
+				c.JSON(http.StatusConflict, gin.H{"error": "A request with this Idempotency-Key is still being processed"})
+			default:
+				contentType := "application/json; charset=utf-8"
+				if record.ContentType != nil {
+					contentType = *record.ContentType
+				}
+				c.Header("Idempotent-Replayed", "true")
+				c.Data(*record.StatusCode, contentType, record.ResponseBody)
+			}
+			c.Abort()
+			return
+		}
+
+		release := func() {
+			if err := db.ReleaseIdempotencyKey(context.Background(), key, scope); err != nil {
+				log.Println("Failed to release idempotency key:", err)
+			}
+		}
+		// A panicking handler never answers; free the key before the panic
+		// is passed on to the recovery middleware
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				release()
+				panic(recovered)
+			}
+		}()
+
+		writer := &idempotencyWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+
+		// Server errors are not stored so the client can retry with the same key
+		status := writer.Status()
+		if status >= http.StatusInternalServerError {
+			release()
+			return
+		}
+		err = db.CompleteIdempotencyKey(context.Background(), key, scope, status, writer.Header().Get("Content-Type"), writer.body.Bytes())
+		if err != nil {
+			log.Println("Failed to store idempotent response:", err)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"Backend/internal/dbtest"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func idempotentRouter(handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(gin.Recovery(), IdempotencyMiddleware())
+	router.POST("/apply", handler)
+	return router
+}
+
+func postWithKey(router *gin.Engine, key, body string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodPost, "/apply", strings.NewReader(body))
+	request.Header.Set("Authorization", "job-seeker-token")
+	request.Header.Set("Idempotency-Key", key)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestIdempotencyMiddlewareConcurrentRequests(t *testing.T) {
+	dbtest.Require(t)
+
+	var runs atomic.Int32
+	router := idempotentRouter(func(c *gin.Context) {
+		runs.Add(1)
+		time.Sleep(100 * time.Millisecond)
+		c.JSON(http.StatusCreated, gin.H{"application_id": 1})
+	})
+
+	const requests = 8
+	var wg sync.WaitGroup
+	codes := make(chan int, requests)
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes <- postWithKey(router, "same-key", `{"job_id":1}`).Code
+		}()
+	}
+	wg.Wait()
+	close(codes)
+
+	if got := runs.Load(); got != 1 {
+		t.Errorf("handler ran %d times, want once", got)
+	}
+	for code := range codes {
+		if code != http.StatusCreated && code != http.StatusConflict {
+			t.Errorf("status = %d, want %d or %d", code, http.StatusCreated, http.StatusConflict)
+		}
+	}
+
+	replay := postWithKey(router, "same-key", `{"job_id":1}`)
+	if replay.Code != http.StatusCreated || replay.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("retry: status = %d, replayed = %q; want the stored response", replay.Code, replay.Header().Get("Idempotent-Replayed"))
+	}
+	if got := runs.Load(); got != 1 {
+		t.Errorf("handler ran %d times after the retry, want once", got)
+	}
+
+	if mismatch := postWithKey(router, "same-key", `{"job_id":2}`); mismatch.Code != http.StatusUnprocessableEntity {
+		t.Errorf("different body: status = %d, want %d", mismatch.Code, http.StatusUnprocessableEntity)
+	}
+}
+
+func TestIdempotencyMiddlewareReleasesKeyOnFailure(t *testing.T) {
+	dbtest.Require(t)
+
+	tests := []struct {
+		name string
+		fail gin.HandlerFunc
+	}{
+		{"panic", func(c *gin.Context) { panic("handler crashed") }},
+		{"server error", func(c *gin.Context) { c.JSON(http.StatusInternalServerError, gin.H{"error": "failed"}) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failing := true
+			router := idempotentRouter(func(c *gin.Context) {
+				if failing {
+					tt.fail(c)
+					return
+				}
+				c.JSON(http.StatusCreated, gin.H{"ok": true})
+			})
+
+			key := "retry-" + strings.ReplaceAll(tt.name, " ", "-")
+			if first := postWithKey(router, key, `{}`); first.Code != http.StatusInternalServerError {
+				t.Fatalf("first attempt: status = %d, want %d", first.Code, http.StatusInternalServerError)
+			}
+
+			failing = false
+			if retry := postWithKey(router, key, `{}`); retry.Code != http.StatusCreated || retry.Header().Get("Idempotent-Replayed") != "" {
+				t.Errorf("retry: status = %d, replayed = %q; want the request to run again", retry.Code, retry.Header().Get("Idempotent-Replayed"))
+			}
+		})
+	}
+}
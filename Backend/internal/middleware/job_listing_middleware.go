@@ -1,7 +1,11 @@
 package middleware
 
 import (
+	"Backend/internal/helpers"
+	"crypto/subtle"
 	"net/http"
+	"os"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -18,24 +22,92 @@ func AuthMiddleware(userType string) gin.HandlerFunc {
 		}
 
 		// Example: Validate token and extract role
-		// In real use, replace this with JWT validation
-		role := validateToken(token)
+		role, userID := validateToken(token)
 		if role != userType {
 			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden: Access denied"})
 			c.Abort()
 			return
 		}
 
+		setCaller(c, role, userID)
 		c.Next() // Proceed to route
 	}
 }
 
-// Mock function to simulate token validation
-func validateToken(token string) string {
+// AuthAnyMiddleware admits any of the given user types and stores the
+// caller's role under "user_type" for handlers that behave per role
+func AuthAnyMiddleware(userTypes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.GetHeader("Authorization")
+
+		if token == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized: No token provided"})
+			c.Abort()
+			return
+		}
+
+		role, userID := validateToken(token)
+		for _, userType := range userTypes {
+			if role == userType {
+				setCaller(c, role, userID)
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden: Access denied"})
+		c.Abort()
+	}
+}
+
+// OptionalAuthMiddleware identifies the caller when a valid token is sent
+// and lets every request through, for routes that show signed-in users more
+func OptionalAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token := c.GetHeader("Authorization"); token != "" {
+			if role, userID := validateToken(token); role != "" {
+				setCaller(c, role, userID)
+			}
+		}
+		c.Next()
+	}
+}
+
+// setCaller stores the caller's role under "user_type" and, for signed
+// tokens, their ID under "user_id"
+func setCaller(c *gin.Context, role string, userID int) {
+	c.Set("user_type", role)
+	if userID != 0 {
+		c.Set("user_id", userID)
+	}
+}
+
+// isAdminToken reports whether token is the admin credential set in
+// ADMIN_TOKEN. Without one configured no token is accepted as admin.
+func isAdminToken(token string) bool {
+	adminToken := os.Getenv("ADMIN_TOKEN")
+	if adminToken == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) == 1
+}
+
+// validateToken returns the role of a token and, for the signed tokens issued
+// at login, the ID of the user it was issued to. The fixed development tokens
+// and the admin credential carry a role only.
+func validateToken(token string) (string, int) {
+	token = strings.TrimPrefix(token, "Bearer ")
 	if token == "employer-token" {
-		return "employer"
+		return "employer", 0
 	} else if token == "job-seeker-token" {
-		return "job_seeker"
+		return "job_seeker", 0
+	} else if isAdminToken(token) {
+		return "admin", 0
+	}
+
+	claims, err := helpers.ValidateToken(token)
+	if err != nil {
+		return "", 0
 	}
-	return ""
+	return claims.UserType, claims.UserID
 }
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"Backend/internal/helpers"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func adminStatus(token string) int {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/admin", AuthMiddleware("admin"), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/admin", nil)
+	request.Header.Set("Authorization", token)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder.Code
+}
+
+func TestAdminToken(t *testing.T) {
+	t.Setenv("ADMIN_TOKEN", "")
+	for _, token := range []string{"admin-token", "Bearer "} {
+		if code := adminStatus(token); code != http.StatusForbidden {
+			t.Errorf("no ADMIN_TOKEN, token %q: status = %d, want %d", token, code, http.StatusForbidden)
+		}
+	}
+
+	t.Setenv("ADMIN_TOKEN", "s3cret-admin")
+	tests := []struct {
+		token string
+		want  int
+	}{
+		{"s3cret-admin", http.StatusOK},
+		{"Bearer s3cret-admin", http.StatusOK},
+		{"admin-token", http.StatusForbidden},
+		{"s3cret", http.StatusForbidden},
+		{"employer-token", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		if code := adminStatus(tt.token); code != tt.want {
+			t.Errorf("token %q: status = %d, want %d", tt.token, code, tt.want)
+		}
+	}
+}
+
+func TestOptionalAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/jobs", OptionalAuthMiddleware(), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"user_type": c.GetString("user_type"), "user_id": c.GetInt("user_id")})
+	})
+
+	signed, err := helpers.GenerateJWT(7, "employer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		token string
+		want  string
+	}{
+		{"", `{"user_id":0,"user_type":""}`},
+		{"not-a-token", `{"user_id":0,"user_type":""}`},
+		{"employer-token", `{"user_id":0,"user_type":"employer"}`},
+		{"Bearer " + signed, `{"user_id":7,"user_type":"employer"}`},
+	}
+	for _, tt := range tests {
+		request := httptest.NewRequest(http.MethodGet, "/jobs", nil)
+		if tt.token != "" {
+			request.Header.Set("Authorization", tt.token)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		if recorder.Code != http.StatusOK || recorder.Body.String() != tt.want {
+			t.Errorf("token %q: got %d %s, want 200 %s", tt.token, recorder.Code, recorder.Body.String(), tt.want)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+package middleware
+
+import (
+	"Backend/internal/dbtest"
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(dbtest.Main(m))
+}
@@ -0,0 +1,29 @@
+package notify
+
+import (
+	"Backend/internal/helpers"
+	"context"
+)
+
+// EmailChannel emails the notification to the user's account address
+type EmailChannel struct{}
+
+func (EmailChannel) Name() string {
+	return ChannelEmail
+}
+
+func (EmailChannel) Accepts(recipient Recipient) bool {
+	return recipient.Email != ""
+}
+
+func (EmailChannel) Send(ctx context.Context, recipient Recipient, notification Notification) error {
+	body := notification.EmailBody
+	if body == "" {
+		body = notification.Message
+	}
+	var attachments []helpers.MailAttachment
+	if notification.Attachments != nil {
+		attachments = notification.Attachments(recipient.Email)
+	}
+	return helpers.SendMailWithAttachments(recipient.Email, body, attachments...)
+}
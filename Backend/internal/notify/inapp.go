@@ -0,0 +1,34 @@
+package notify
+
+import (
+	"Backend/internal/db"
+	"Backend/internal/schema"
+	"context"
+)
+
+// InAppChannel stores the notification for the user's notification list.
+// Prepare stores in-app notifications with the change they announce, so they
+// never pass through the outbox.
+type InAppChannel struct{}
+
+func (InAppChannel) Name() string {
+	return ChannelInApp
+}
+
+func (InAppChannel) Accepts(recipient Recipient) bool {
+	return true
+}
+
+func (InAppChannel) Send(ctx context.Context, recipient Recipient, notification Notification) error {
+	notificationID, err := db.GenerateNotificationID(ctx)
+	if err != nil {
+		return err
+	}
+	return db.StoreNotification(ctx, schema.Notification{
+		ID:       notificationID,
+		UserID:   recipient.UserID,
+		UserType: recipient.UserType,
+		Message:  notification.Message,
+		IsRead:   false,
+	})
+}
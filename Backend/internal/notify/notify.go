@@ -0,0 +1,253 @@
+// Package notify delivers notifications to job seekers and employers over
+// pluggable channels. Each event is routed to the channels it should reach.
+// Prepare builds what a change announces, which the change stores in its own
+// transaction: in-app notifications directly, everything else in the outbox
+// for the outbox worker to deliver, with retries.
+package notify
+
+import (
+	"Backend/internal/db"
+	"Backend/internal/helpers"
+	"Backend/internal/schema"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+)
+
+// Event is the kind of thing a notification is about. Routing rules are kept
+// per event.
+type Event string
+
+const (
+	EventApplicationSubmitted     Event = "application.submitted"
+	EventApplicationStatusChanged Event = "application.status_changed"
+	EventApplicationWithdrawn     Event = "application.withdrawn"
+	EventNoteMention              Event = "application.note_mention"
+	EventCandidateInvited         Event = "candidate.invited"
+	EventMessageReceived          Event = "message.received"
+	EventBookingLinkSent          Event = "interview.booking_link_sent"
+	EventInterviewScheduled       Event = "interview.scheduled"
+	EventInterviewBooked          Event = "interview.booked"
+	EventInterviewCancelled       Event = "interview.cancelled"
+	EventInterviewRescheduled     Event = "interview.rescheduled"
+	EventRescheduleRequested      Event = "interview.reschedule_requested"
+	EventRescheduleDeclined       Event = "interview.reschedule_declined"
+	EventInterviewNoShow          Event = "interview.no_show"
+	EventNoShowReported           Event = "interview.no_show_reported"
+	EventInterviewCompleted       Event = "interview.completed"
+	EventInterviewReminder        Event = "interview.reminder"
+	EventInterviewCalendar        Event = "interview.calendar"
+	EventOfferSent                Event = "offer.sent"
+	EventOfferWithdrawn           Event = "offer.withdrawn"
+	EventOfferResponded           Event = "offer.responded"
+	EventOfferExpired             Event = "offer.expired"
+	EventOfferReminder            Event = "offer.reminder"
+)
+
+// Channel names
+const (
+	ChannelInApp   = "in_app"
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+	ChannelSMS     = "sms"
+)
+
+// ErrUnknownChannel is returned for a channel name nothing is registered under
+var ErrUnknownChannel = errors.New("unknown notification channel")
+
+// Notification is one message to one user
+type Notification struct {
+	Event    Event
+	UserType string // "job_seeker" or "employer"
+	UserID   int
+	Message  string
+	// EmailBody replaces Message in the email, e.g. for HTML or a longer text
+	EmailBody string
+	// Attachments returns the files to attach to the email sent to an address
+	Attachments func(email string) []helpers.MailAttachment
+}
+
+// Recipient is the user a notification goes to, with the contact details
+// channels outside the app deliver to
+type Recipient struct {
+	UserType string
+	UserID   int
+	Email    string
+	Phone    string
+}
+
+// Channel delivers notifications one way, such as in-app or by email.
+// Accepts reports whether the channel can reach a recipient at all, e.g.
+// whether they have a phone number; nothing is queued for channels that can't.
+type Channel interface {
+	Name() string
+	Accepts(recipient Recipient) bool
+	Send(ctx context.Context, recipient Recipient, notification Notification) error
+}
+
+var (
+	mu       sync.RWMutex
+	channels = map[string]Channel{}
+
+	// defaultRoute is used for events without a rule of their own
+	defaultRoute = []string{ChannelInApp, ChannelEmail, ChannelWebhook}
+
+	// routes lists the channels of each event. Interview changes that are
+	// emailed with a calendar update skip the email channel, and changes to
+	// interviews reach the user's phone. Calendar updates are email only, as
+	// the in-app notification is sent with the change itself.
+	routes = map[Event][]string{
+		EventInterviewCalendar:    {ChannelEmail},
+		EventInterviewScheduled:   {ChannelInApp, ChannelEmail, ChannelSMS, ChannelWebhook},
+		EventInterviewBooked:      {ChannelInApp, ChannelWebhook},
+		EventInterviewCancelled:   {ChannelInApp, ChannelSMS, ChannelWebhook},
+		EventInterviewRescheduled: {ChannelInApp, ChannelSMS, ChannelWebhook},
+		EventInterviewReminder:    {ChannelInApp, ChannelEmail, ChannelSMS, ChannelWebhook},
+	}
+)
+
+func init() {
+	Register(InAppChannel{})
+	Register(EmailChannel{})
+	Register(NewWebhookChannel(""))
+	Register(SMSStubChannel{})
+}
+
+// Register makes a channel available under its name, replacing any channel
+// registered under the same name
+func Register(channel Channel) {
+	mu.Lock()
+	defer mu.Unlock()
+	channels[channel.Name()] = channel
+}
+
+// Route sets the channels an event is delivered over
+func Route(event Event, channelNames ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+	routes[event] = channelNames
+}
+
+// Channels returns the channels an event is delivered over
+func Channels(event Event) ([]Channel, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	names, ok := routes[event]
+	if !ok {
+		names = defaultRoute
+	}
+	routed := make([]Channel, 0, len(names))
+	for _, name := range names {
+		channel, ok := channels[name]
+		if !ok {
+			return nil, ErrUnknownChannel
+		}
+		routed = append(routed, channel)
+	}
+	return routed, nil
+}
+
+// Prepare turns a notification into what is stored for it: an in-app
+// notification if the event is routed in-app, and an outbox message for each
+// other channel that can reach the user. The outbox worker delivers the
+// messages. Attachments are built here, for the recipient's address.
+func Prepare(ctx context.Context, notification Notification) (schema.Outbox, error) {
+	var outbox schema.Outbox
+	routed, err := Channels(notification.Event)
+	if err != nil {
+		return outbox, err
+	}
+
+	var recipient *Recipient
+	for _, channel := range routed {
+		if channel.Name() == ChannelInApp {
+			outbox.Notifications = append(outbox.Notifications, schema.Notification{
+				UserID:   notification.UserID,
+				UserType: notification.UserType,
+				Message:  notification.Message,
+				IsRead:   false,
+			})
+			continue
+		}
+
+		if recipient == nil {
+			email, phone, err := db.GetUserContact(ctx, notification.UserType, notification.UserID)
+			if err != nil {
+				return outbox, err
+			}
+			recipient = &Recipient{UserType: notification.UserType, UserID: notification.UserID, Email: email, Phone: phone}
+		}
+		if !channel.Accepts(*recipient) {
+			continue
+		}
+
+		message, err := outboxMessage(channel.Name(), *recipient, notification)
+		if err != nil {
+			return outbox, err
+		}
+		outbox.Messages = append(outbox.Messages, message)
+	}
+	return outbox, nil
+}
+
+// outboxMessage builds the outbox message of a notification for one channel,
+// with what Deliver needs to send it to the recipient later
+func outboxMessage(channelName string, recipient Recipient, notification Notification) (schema.OutboxMessage, error) {
+	message := payload{
+		Email:     recipient.Email,
+		Phone:     recipient.Phone,
+		Message:   notification.Message,
+		EmailBody: notification.EmailBody,
+	}
+	if channelName == ChannelEmail && notification.Attachments != nil {
+		message.Attachments = notification.Attachments(recipient.Email)
+	}
+	data, err := json.Marshal(message)
+	if err != nil {
+		return schema.OutboxMessage{}, err
+	}
+	return schema.OutboxMessage{
+		Channel:  channelName,
+		Event:    string(notification.Event),
+		UserID:   notification.UserID,
+		UserType: notification.UserType,
+		Payload:  data,
+	}, nil
+}
+
+// Deliver sends an outbox message over its channel
+func Deliver(ctx context.Context, message schema.OutboxMessage) error {
+	mu.RLock()
+	channel, ok := channels[message.Channel]
+	mu.RUnlock()
+	if !ok {
+		return ErrUnknownChannel
+	}
+
+	var stored payload
+	if err := json.Unmarshal(message.Payload, &stored); err != nil {
+		return err
+	}
+	recipient := Recipient{UserType: message.UserType, UserID: message.UserID, Email: stored.Email, Phone: stored.Phone}
+	return channel.Send(ctx, recipient, Notification{
+		Event:     Event(message.Event),
+		UserType:  message.UserType,
+		UserID:    message.UserID,
+		Message:   stored.Message,
+		EmailBody: stored.EmailBody,
+		Attachments: func(string) []helpers.MailAttachment {
+			return stored.Attachments
+		},
+	})
+}
+
+// payload is what an outbox message carries: the recipient's address at the
+// time of the change and the content to send
+type payload struct {
+	Email       string                   `json:"email,omitempty"`
+	Phone       string                   `json:"phone,omitempty"`
+	Message     string                   `json:"message"`
+	EmailBody   string                   `json:"email_body,omitempty"`
+	Attachments []helpers.MailAttachment `json:"attachments,omitempty"`
+}
@@ -0,0 +1,205 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"Backend/internal/helpers"
+)
+
+// fakeChannel is a channel that accepts everyone and sends nothing
+type fakeChannel struct{ name string }
+
+func (f fakeChannel) Name() string                                      { return f.name }
+func (fakeChannel) Accepts(Recipient) bool                              { return true }
+func (fakeChannel) Send(context.Context, Recipient, Notification) error { return nil }
+
+func channelNames(t *testing.T, event Event) []string {
+	t.Helper()
+	routed, err := Channels(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, len(routed))
+	for i, channel := range routed {
+		names[i] = channel.Name()
+	}
+	return names
+}
+
+// restoreRoute puts an event's routing rule back after the test
+func restoreRoute(t *testing.T, event Event) {
+	t.Helper()
+	mu.RLock()
+	previous, ok := routes[event]
+	mu.RUnlock()
+	t.Cleanup(func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if ok {
+			routes[event] = previous
+		} else {
+			delete(routes, event)
+		}
+	})
+}
+
+func TestChannels(t *testing.T) {
+	tests := []struct {
+		event Event
+		want  []string
+	}{
+		{EventApplicationSubmitted, defaultRoute},
+		{Event("unknown.event"), defaultRoute},
+		{EventInterviewCalendar, []string{ChannelEmail}},
+		{EventInterviewScheduled, []string{ChannelInApp, ChannelEmail, ChannelSMS, ChannelWebhook}},
+		{EventInterviewCancelled, []string{ChannelInApp, ChannelSMS, ChannelWebhook}},
+	}
+	for _, tt := range tests {
+		if got := channelNames(t, tt.event); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Channels(%s) = %v, want %v", tt.event, got, tt.want)
+		}
+	}
+}
+
+func TestDefaultRoute(t *testing.T) {
+	// Events without a rule of their own go in-app, by email and to the
+	// webhook, but never to the user's phone
+	unruled := []string{ChannelInApp, ChannelEmail, ChannelWebhook}
+	for _, event := range []Event{EventApplicationSubmitted, EventOfferSent, Event("unknown.event")} {
+		if got := channelNames(t, event); !reflect.DeepEqual(got, unruled) {
+			t.Errorf("%s: %v, want %v", event, got, unruled)
+		}
+	}
+
+	// Overriding one event's rule leaves the others on the default route
+	restoreRoute(t, EventOfferSent)
+	Route(EventOfferSent, ChannelEmail, ChannelSMS)
+	if got, want := channelNames(t, EventOfferSent), []string{ChannelEmail, ChannelSMS}; !reflect.DeepEqual(got, want) {
+		t.Errorf("overridden %s: %v, want %v", EventOfferSent, got, want)
+	}
+	if got := channelNames(t, EventApplicationSubmitted); !reflect.DeepEqual(got, unruled) {
+		t.Errorf("%s after override: %v, want %v", EventApplicationSubmitted, got, unruled)
+	}
+}
+
+func TestRoute(t *testing.T) {
+	event := EventOfferReminder
+	restoreRoute(t, event)
+	Register(fakeChannel{name: "test"})
+	t.Cleanup(func() {
+		mu.Lock()
+		defer mu.Unlock()
+		delete(channels, "test")
+	})
+
+	Route(event, "test", ChannelInApp)
+	if got, want := channelNames(t, event), []string{"test", ChannelInApp}; !reflect.DeepEqual(got, want) {
+		t.Errorf("after Route: %v, want %v", got, want)
+	}
+	if got := channelNames(t, EventOfferSent); !reflect.DeepEqual(got, defaultRoute) {
+		t.Errorf("other event: %v, want the default route", got)
+	}
+
+	// Routing to no channels silences the event instead of using the default
+	Route(event)
+	if got := channelNames(t, event); len(got) != 0 {
+		t.Errorf("after Route with no channels: %v, want none", got)
+	}
+
+	Route(event, "missing")
+	if _, err := Channels(event); !errors.Is(err, ErrUnknownChannel) {
+		t.Errorf("err = %v, want ErrUnknownChannel", err)
+	}
+}
+
+func TestMaskPhone(t *testing.T) {
+	tests := map[string]string{
+		"":              "",
+		"123":           "***",
+		"1234":          "****",
+		"+15551234567":  "********4567",
+		"+44 20 7946 0": "*********46 0",
+	}
+	for phone, want := range tests {
+		if got := maskPhone(phone); got != want {
+			t.Errorf("maskPhone(%q) = %q, want %q", phone, got, want)
+		}
+	}
+}
+
+// recordingChannel keeps what it was asked to send
+type recordingChannel struct {
+	name         string
+	recipient    Recipient
+	notification Notification
+}
+
+func (r *recordingChannel) Name() string         { return r.name }
+func (*recordingChannel) Accepts(Recipient) bool { return true }
+func (r *recordingChannel) Send(_ context.Context, recipient Recipient, notification Notification) error {
+	r.recipient, r.notification = recipient, notification
+	return nil
+}
+
+func TestOutboxPayloadRoundTrip(t *testing.T) {
+	email := &recordingChannel{name: ChannelEmail}
+	webhook := &recordingChannel{name: ChannelWebhook}
+	Register(email)
+	Register(webhook)
+	t.Cleanup(func() {
+		Register(EmailChannel{})
+		Register(NewWebhookChannel(""))
+	})
+
+	invite := helpers.MailAttachment{FileName: "invite.ics", ContentType: "text/calendar", Data: []byte("BEGIN:VCALENDAR\r\n")}
+	recipient := Recipient{UserType: "job_seeker", UserID: 7, Email: "seeker@example.com", Phone: "+15551234567"}
+	notification := Notification{
+		Event:     EventInterviewCalendar,
+		UserType:  recipient.UserType,
+		UserID:    recipient.UserID,
+		Message:   "Your interview was moved",
+		EmailBody: "<p>Your interview was moved</p>",
+		Attachments: func(address string) []helpers.MailAttachment {
+			if address != recipient.Email {
+				t.Errorf("attachments built for %q, want %q", address, recipient.Email)
+			}
+			return []helpers.MailAttachment{invite}
+		},
+	}
+
+	for _, channel := range []*recordingChannel{email, webhook} {
+		message, err := outboxMessage(channel.name, recipient, notification)
+		if err != nil {
+			t.Fatalf("%s: outboxMessage: %v", channel.name, err)
+		}
+		if err := Deliver(context.Background(), message); err != nil {
+			t.Fatalf("%s: Deliver: %v", channel.name, err)
+		}
+
+		if channel.recipient != recipient {
+			t.Errorf("%s: recipient = %+v, want %+v", channel.name, channel.recipient, recipient)
+		}
+		got := channel.notification
+		if got.Event != notification.Event || got.Message != notification.Message || got.EmailBody != notification.EmailBody {
+			t.Errorf("%s: notification = %+v, want %+v", channel.name, got, notification)
+		}
+		var want []helpers.MailAttachment
+		if channel.name == ChannelEmail {
+			want = []helpers.MailAttachment{invite}
+		}
+		if attachments := got.Attachments(recipient.Email); !reflect.DeepEqual(attachments, want) {
+			t.Errorf("%s: attachments = %+v, want %+v", channel.name, attachments, want)
+		}
+	}
+
+	message, err := outboxMessage("missing", recipient, notification)
+	if err != nil {
+		t.Fatalf("outboxMessage: %v", err)
+	}
+	if err := Deliver(context.Background(), message); !errors.Is(err, ErrUnknownChannel) {
+		t.Errorf("err = %v, want ErrUnknownChannel", err)
+	}
+}
@@ -0,0 +1,35 @@
+package notify
+
+import (
+	"context"
+	"log"
+	"strings"
+)
+
+// SMSStubChannel stands in for a text message gateway until one is chosen.
+// It logs the message it would send, with all but the last digits of the
+// phone number masked.
+type SMSStubChannel struct{}
+
+func (SMSStubChannel) Name() string {
+	return ChannelSMS
+}
+
+func (SMSStubChannel) Accepts(recipient Recipient) bool {
+	return recipient.Phone != ""
+}
+
+func (SMSStubChannel) Send(ctx context.Context, recipient Recipient, notification Notification) error {
+	log.Printf("SMS to %s (%s %d): %s\n", maskPhone(recipient.Phone), recipient.UserType, recipient.UserID, notification.Message)
+	return nil
+}
+
+// maskPhone hides all but the last four characters of a phone number
+func maskPhone(phone string) string {
+	const visible = 4
+	runes := []rune(phone)
+	if len(runes) <= visible {
+		return strings.Repeat("*", len(runes))
+	}
+	return strings.Repeat("*", len(runes)-visible) + string(runes[len(runes)-visible:])
+}
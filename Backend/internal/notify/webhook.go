@@ -0,0 +1,77 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+// WebhookChannel posts notifications as JSON to an HTTP endpoint, such as a
+// chat integration or an outside CRM
+type WebhookChannel struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookChannel returns a channel posting to url. With an empty url,
+// NOTIFY_WEBHOOK_URL is read each time, and nothing is queued while it is
+// unset.
+func NewWebhookChannel(url string) *WebhookChannel {
+	return &WebhookChannel{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (w *WebhookChannel) Name() string {
+	return ChannelWebhook
+}
+
+// endpoint returns the URL notifications are posted to
+func (w *WebhookChannel) endpoint() string {
+	if w.url != "" {
+		return w.url
+	}
+	return os.Getenv("NOTIFY_WEBHOOK_URL")
+}
+
+func (w *WebhookChannel) Accepts(recipient Recipient) bool {
+	return w.endpoint() != ""
+}
+
+// Send posts the notification. A message queued before the URL was unset is
+// dropped, as there is nowhere left to post it.
+func (w *WebhookChannel) Send(ctx context.Context, recipient Recipient, notification Notification) error {
+	url := w.endpoint()
+	if url == "" {
+		return nil
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"event":     notification.Event,
+		"user_type": recipient.UserType,
+		"user_id":   recipient.UserID,
+		"message":   notification.Message,
+		"sent_at":   time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := w.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", response.StatusCode)
+	}
+	return nil
+}
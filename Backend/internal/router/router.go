@@ -50,7 +50,8 @@ func SetupRouter() *gin.Engine {
 	config := cors.Config{
 		AllowOrigins:     []string{os.Getenv("WEB_URL")}, // Allowed frontend URL (from .env)
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Authorization", "Content-Type", "X-Requested-With", "Accept"},
+		AllowHeaders:     []string{"Origin", "Authorization", "Content-Type", "X-Requested-With", "Accept", "Idempotency-Key"},
+		ExposeHeaders:    []string{"Idempotent-Replayed"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour, // Cache preflight request for 12 hours
 	}
@@ -59,11 +60,14 @@ func SetupRouter() *gin.Engine {
 	// ✅ Rate Limiting Middleware
 	router.Use(middleware.RateLimiterMiddleware())
 
+	// ✅ Idempotency-Key support for POST requests
+	router.Use(middleware.IdempotencyMiddleware())
+
 	// ✅ Handle Preflight Requests (OPTIONS)
 	router.OPTIONS("/*path", func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", os.Getenv("WEB_URL"))
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, PATCH")
-		c.Header("Access-Control-Allow-Headers", "Authorization, Content-Type, X-Requested-With, Accept")
+		c.Header("Access-Control-Allow-Headers", "Authorization, Content-Type, X-Requested-With, Accept, Idempotency-Key")
 		c.Header("Access-Control-Allow-Credentials", "true")
 		c.Status(204) // No content response
 	})
@@ -22,12 +22,84 @@ func SetupRoutes(router *gin.Engine) {
 	{
 		applicationGroup.POST("/add_application", controller.CreateApplicationHandler)
 		applicationGroup.GET("/get_seeker_application/:id", controller.GetSeekerApplicationHandler)
-		applicationGroup.GET("/get_job_application/:id", controller.GetJobApplicationHandler)
-		applicationGroup.PATCH("/add_result/:id", controller.UpdateApplicationStatusHandler)
+		applicationGroup.GET("/get_job_application/:id", middleware.OptionalAuthMiddleware(), controller.GetJobApplicationHandler)
+		applicationGroup.GET("/get_ranked_applications/:id", controller.GetRankedApplicationsHandler)
+		applicationGroup.PATCH("/add_result/:id", middleware.OptionalAuthMiddleware(), controller.UpdateApplicationStatusHandler)
 		applicationGroup.GET("/get_accepted_application/:id", controller.GetAcceptedApplicationHandler)
 		applicationGroup.GET("/get_rejected_application/:id", controller.GetRejectedApplicationHandler)
 		applicationGroup.GET("/get_application_count/:id", controller.GetSeekerApplicationCountHandler)
 		applicationGroup.GET("/get_result_count/:id", controller.GetResultCountHandler)
+		applicationGroup.GET("/get_seeker_timeline/:id", middleware.AuthMiddleware("job_seeker"), controller.GetSeekerTimelineHandler)
+		applicationGroup.GET("/get_employer_timeline/:id", middleware.AuthMiddleware("employer"), controller.GetEmployerTimelineHandler)
+		applicationGroup.PATCH("/withdraw/:id", middleware.AuthMiddleware("job_seeker"), controller.WithdrawApplicationHandler)
+		applicationGroup.POST("/add_note/:id", middleware.AuthMiddleware("employer"), controller.AddApplicationNoteHandler)
+		applicationGroup.GET("/get_notes/:id", middleware.AuthMiddleware("employer"), controller.GetApplicationNotesHandler)
+		applicationGroup.PUT("/rate/:id", middleware.AuthMiddleware("employer"), controller.RateApplicationHandler)
+		applicationGroup.GET("/get_ratings/:id", middleware.AuthMiddleware("employer"), controller.GetApplicationRatingsHandler)
+		applicationGroup.POST("/bulk_action", middleware.AuthMiddleware("employer"), controller.BulkApplicationActionHandler)
+		applicationGroup.GET("/rejection_report/:id", middleware.AuthMiddleware("employer"), controller.GetRejectionReportHandler)
+	}
+
+	// Group routes for hiring pipeline stages
+	pipelineGroup := router.Group("/pipeline")
+	{
+		pipelineGroup.PUT("/set_company_stages/:id", middleware.AuthMiddleware("employer"), controller.SetCompanyStagesHandler)
+		pipelineGroup.GET("/get_company_stages/:id", middleware.AuthMiddleware("employer"), controller.GetCompanyStagesHandler)
+		pipelineGroup.PUT("/set_job_stages/:id", middleware.AuthMiddleware("employer"), controller.SetJobStagesHandler)
+		pipelineGroup.GET("/get_job_stages/:id", middleware.AuthMiddleware("employer"), controller.GetJobStagesHandler)
+		pipelineGroup.GET("/get_stage_counts/:id", middleware.AuthMiddleware("employer"), controller.GetStageCountsHandler)
+		pipelineGroup.PATCH("/move_application/:id", middleware.AuthMiddleware("employer"), controller.MoveApplicationStageHandler)
+		pipelineGroup.PUT("/set_blind_review/:id", middleware.AuthMiddleware("employer"), controller.SetBlindReviewHandler)
+		pipelineGroup.GET("/get_blind_review/:id", middleware.AuthMiddleware("employer"), controller.GetBlindReviewHandler)
+		pipelineGroup.GET("/get_identity_reveals/:id", middleware.AuthMiddleware("employer"), controller.GetIdentityRevealsHandler)
+	}
+
+	// Group routes for resume versions
+	resumeGroup := router.Group("/resume")
+	{
+		resumeGroup.POST("/upload/:id", middleware.AuthMiddleware("job_seeker"), controller.UploadResumeHandler)
+		resumeGroup.GET("/get_resumes/:id", middleware.AuthMiddleware("job_seeker"), controller.GetResumesHandler)
+		resumeGroup.PATCH("/set_default/:id", middleware.AuthMiddleware("job_seeker"), controller.SetDefaultResumeHandler)
+		resumeGroup.GET("/download/:id", middleware.AuthAnyMiddleware("job_seeker", "employer"), controller.DownloadResumeHandler)
+		resumeGroup.GET("/parse/:id", middleware.AuthMiddleware("job_seeker"), controller.ParseResumeHandler)
+		resumeGroup.POST("/accept_draft/:id", middleware.AuthMiddleware("job_seeker"), controller.AcceptProfileDraftHandler)
+	}
+
+	// Group routes for messaging between applicants and employers
+	messageGroup := router.Group("/message")
+	messageGroup.Use(middleware.AuthAnyMiddleware("job_seeker", "employer"))
+	{
+		messageGroup.POST("/send/:id", controller.SendMessageHandler)
+		messageGroup.GET("/get_threads/:id", controller.GetThreadsHandler)
+		messageGroup.GET("/get_thread/:id", controller.GetThreadMessagesHandler)
+		messageGroup.PATCH("/mark_read/:id", controller.MarkThreadReadHandler)
+		messageGroup.GET("/unread_count/:id", controller.GetUnreadMessageCountHandler)
+		messageGroup.GET("/attachment/:id", controller.DownloadAttachmentHandler)
+	}
+
+	// Group routes for job offers on accepted applications
+	offerGroup := router.Group("/offer")
+	{
+		offerGroup.POST("/create/:id", middleware.AuthMiddleware("employer"), controller.CreateOfferHandler)
+		offerGroup.PATCH("/send/:id", middleware.AuthMiddleware("employer"), controller.SendOfferHandler)
+		offerGroup.PATCH("/withdraw/:id", middleware.AuthMiddleware("employer"), controller.WithdrawOfferHandler)
+		offerGroup.GET("/get_application_offers/:id", middleware.AuthMiddleware("employer"), controller.GetApplicationOffersHandler)
+		offerGroup.PUT("/set_settings/:id", middleware.AuthMiddleware("employer"), controller.SetOfferSettingsHandler)
+		offerGroup.GET("/get_settings/:id", middleware.AuthMiddleware("employer"), controller.GetOfferSettingsHandler)
+		offerGroup.PATCH("/respond/:id", middleware.AuthMiddleware("job_seeker"), controller.RespondToOfferHandler)
+		offerGroup.GET("/get_seeker_offers/:id", middleware.AuthMiddleware("job_seeker"), controller.GetSeekerOffersHandler)
+		offerGroup.GET("/letter/:id", middleware.AuthAnyMiddleware("job_seeker", "employer"), controller.DownloadOfferLetterHandler)
+	}
+
+	// Group routes for company message templates
+	templateGroup := router.Group("/template")
+	templateGroup.Use(middleware.AuthMiddleware("employer"))
+	{
+		templateGroup.POST("/create", controller.CreateMessageTemplateHandler)
+		templateGroup.GET("/get_templates/:id", controller.GetMessageTemplatesHandler)
+		templateGroup.PUT("/update/:id", controller.UpdateMessageTemplateHandler)
+		templateGroup.DELETE("/delete/:id", controller.DeleteMessageTemplateHandler)
+		templateGroup.GET("/reason_codes", controller.GetRejectionReasonCodesHandler)
 	}
 
 	// Group routes for interview
@@ -38,6 +110,38 @@ func SetupRoutes(router *gin.Engine) {
 		interviewGroup.GET("/get_seeker_interview/:id", controller.GetSeekerInterviewHandler)
 		interviewGroup.PATCH("/update_interview", controller.UpdateInterviewHandler)
 		interviewGroup.GET("/get_interview_count/:id", controller.GetSeekerInterviewCountHandler)
+		interviewGroup.PATCH("/complete/:id", middleware.AuthMiddleware("employer"), controller.CompleteInterviewHandler)
+		interviewGroup.PATCH("/cancel/:id", middleware.AuthAnyMiddleware("job_seeker", "employer"), controller.CancelInterviewHandler)
+		interviewGroup.PATCH("/reschedule/:id", middleware.AuthMiddleware("employer"), controller.RescheduleInterviewHandler)
+		interviewGroup.POST("/request_reschedule/:id", middleware.AuthMiddleware("job_seeker"), controller.RequestRescheduleHandler)
+		interviewGroup.PATCH("/decline_reschedule/:id", middleware.AuthMiddleware("employer"), controller.DeclineRescheduleHandler)
+		interviewGroup.GET("/reschedule_requests/:id", middleware.AuthAnyMiddleware("job_seeker", "employer"), controller.GetRescheduleRequestsHandler)
+		interviewGroup.PATCH("/no_show/:id", middleware.AuthAnyMiddleware("job_seeker", "employer"), controller.MarkNoShowHandler)
+		interviewGroup.PUT("/set_plan/:id", middleware.AuthMiddleware("employer"), controller.SetInterviewPlanHandler)
+		interviewGroup.GET("/get_plan/:id", controller.GetInterviewPlanHandler)
+		interviewGroup.POST("/add_availability/:id", middleware.AuthMiddleware("employer"), controller.AddAvailabilityHandler)
+		interviewGroup.GET("/get_availability/:id", middleware.AuthMiddleware("employer"), controller.GetAvailabilityHandler)
+		interviewGroup.DELETE("/delete_availability/:id", middleware.AuthMiddleware("employer"), controller.DeleteAvailabilityHandler)
+		interviewGroup.POST("/booking_link/:id", middleware.AuthMiddleware("employer"), controller.CreateBookingLinkHandler)
+		interviewGroup.PUT("/set_scorecard/:id", middleware.AuthMiddleware("employer"), controller.SetScorecardHandler)
+		interviewGroup.GET("/get_scorecard/:id", controller.GetScorecardHandler)
+		interviewGroup.POST("/submit_feedback/:id", middleware.AuthMiddleware("employer"), controller.SubmitFeedbackHandler)
+		interviewGroup.GET("/get_feedback/:id", middleware.AuthMiddleware("employer"), controller.GetApplicationFeedbackHandler)
+	}
+
+	// Candidate booking links carry their own token, so they need no login
+	bookingGroup := router.Group("/booking")
+	{
+		bookingGroup.GET("/:token", controller.GetBookingSlotsHandler)
+		bookingGroup.POST("/:token", controller.BookSlotHandler)
+	}
+
+	// Calendar clients fetch the feed with the token in its URL and no login
+	calendarGroup := router.Group("/calendar")
+	{
+		calendarGroup.GET("/feed_url/:id", middleware.AuthAnyMiddleware("job_seeker", "employer"), controller.GetCalendarFeedHandler)
+		calendarGroup.POST("/rotate_feed/:id", middleware.AuthAnyMiddleware("job_seeker", "employer"), controller.RotateCalendarFeedHandler)
+		calendarGroup.GET("/feed/:token", controller.CalendarFeedHandler)
 	}
 
 	companyGroup := router.Group("/company")
@@ -65,6 +169,15 @@ func SetupRoutes(router *gin.Engine) {
 	notificationGroup := router.Group("/notification")
 	{
 		notificationGroup.GET("/get_notifications/:id", controller.GetNotificationsHandler)
+		notificationGroup.GET("/get_employer_notifications/:id", controller.GetEmployerNotificationsHandler)
+	}
+
+	// Admin Routes (Restricted)
+	adminRoutes := router.Group("/admin")
+	adminRoutes.Use(middleware.AuthMiddleware("admin"))
+	{
+		adminRoutes.GET("/get_outbox", controller.GetOutboxMessagesHandler)
+		adminRoutes.PATCH("/retry_outbox/:id", controller.RetryOutboxMessageHandler)
 	}
 
 	// Employer Routes (Restricted)
@@ -75,6 +188,12 @@ func SetupRoutes(router *gin.Engine) {
 		employerRoutes.GET("/jobs", controller.FetchJobsByEmployer) // Fetch jobs posted by employer
 		employerRoutes.PUT("/jobs/:id", controller.UpdateJob)       // Employer can update jobs
 		employerRoutes.DELETE("/jobs/:id", controller.DeleteJob)    // Employer can delete jobs
+		employerRoutes.POST("/candidates/search", controller.SearchCandidatesHandler)
+		employerRoutes.POST("/candidates/saved_searches", controller.SaveCandidateSearchHandler)
+		employerRoutes.GET("/candidates/saved_searches", controller.GetSavedCandidateSearchesHandler)
+		employerRoutes.GET("/candidates/saved_searches/:id/run", controller.RunSavedCandidateSearchHandler)
+		employerRoutes.DELETE("/candidates/saved_searches/:id", controller.DeleteSavedCandidateSearchHandler)
+		employerRoutes.POST("/candidates/:id/invite", controller.InviteCandidateHandler)
 	}
 
 	// Job Seeker Routes (Restricted)
@@ -86,6 +205,7 @@ func SetupRoutes(router *gin.Engine) {
 		jobSeekerRoutes.GET("/jobs/filter", controller.FilterJobs) // Job seeker can filter jobs
 		jobSeekerRoutes.POST("/apply", controller.ApplyJob)        // Job seeker can apply for jobs
 		jobSeekerRoutes.GET("/jobsApplied/:id", controller.GetAllJobsThatSeekerApplied)
+		jobSeekerRoutes.PATCH("/discoverable/:id", controller.SetDiscoverableHandler) // Opt in to candidate search
 	}
 
 	// Public Routes (No authentication required)
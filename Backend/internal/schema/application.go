@@ -9,6 +9,9 @@ type Application struct {
 	ApplicationStatus string `json:"application_status"`
 	AppliedDate      time.Time `json:"applied_date"`
 	CoverLetter      string `json:"cover_letter"`
+	StageID          *int   `json:"stage_id"`
+	ResumeID         *int   `json:"resume_id"` // Defaults to the seeker's default resume
+	RejectionReasonCode *string `json:"rejection_reason_code,omitempty"`
 }
 
 type ApplicationandJob struct {
@@ -31,13 +34,24 @@ type ApplicationDetails struct {
 	LastName         string    `json:"last_name"`
 	Email            string    `json:"email"`
 	PhoneNumber      string    `json:"phone_number"`
+	ProfilePicture   *string   `json:"profile_picture"`
+	LinkedinURL      *string   `json:"linkedin_url"`
+	CandidateAlias   string    `json:"candidate_alias,omitempty"` // Set when identity is hidden by blind review
+	IdentityHidden   bool      `json:"identity_hidden"`
 	Resume           *string    `json:"resume"`
+	ResumeID         *int       `json:"resume_id"`
 	AppliedDate      time.Time `json:"applied_date"`
 	CoverLetter      *string    `json:"cover_letter"`
 	Education        []EducationDetails `json:"education"`
 	Experience       []ExperienceDetails `json:"experience"`
 	Skills           []string  `json:"skills"`
+	SkillLevels      map[string]string `json:"skill_levels"` // Skill name to proficiency
 	ApplicationStatus string    `json:"application_status"`
+	StageID          *int      `json:"stage_id"`
+	StageName        *string   `json:"stage_name"`
+	Notes            []ApplicationNote `json:"notes,omitempty"`  // Hiring team only
+	Rating           *RatingSummary    `json:"rating,omitempty"` // Hiring team only
+	Tags             []string          `json:"tags"`
 }
 
 type EducationDetails struct {
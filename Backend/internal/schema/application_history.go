@@ -0,0 +1,53 @@
+package schema
+
+import "time"
+
+// Event types recorded in an application's history
+const (
+	EventApplied              = "Applied"
+	EventStageChanged         = "Stage Changed"
+	EventInterviewScheduled   = "Interview Scheduled"
+	EventInterviewCompleted   = "Interview Completed"
+	EventInterviewCancelled   = "Interview Cancelled"
+	EventInterviewRescheduled = "Interview Rescheduled"
+	EventRescheduleRequested  = "Reschedule Requested"
+	EventRescheduleDeclined   = "Reschedule Declined"
+	EventInterviewNoShow      = "Interview No Show"
+	EventNoShowReported       = "No Show Reported"
+	EventDecision             = "Decision"
+	EventWithdrawn            = "Withdrawn"
+	EventOfferSent            = "Offer Sent"
+	EventOfferAccepted        = "Offer Accepted"
+	EventOfferDeclined        = "Offer Declined"
+	EventFeedbackSubmitted    = "Feedback Submitted"
+)
+
+// Actor identifies who performed an action. ID is nil for system actions.
+type Actor struct {
+	Type string `json:"actor_type"`
+	ID   *int   `json:"actor_id,omitempty"`
+}
+
+// ApplicationEvent is one entry in the history of an application
+type ApplicationEvent struct {
+	ID            int       `json:"id"`
+	ApplicationID int       `json:"application_id"`
+	EventType     string    `json:"event_type"`
+	FromStatus    *string   `json:"from_status,omitempty"`
+	ToStatus      *string   `json:"to_status,omitempty"`
+	StageID       *int      `json:"stage_id,omitempty"`
+	StageName     *string   `json:"stage_name,omitempty"`
+	ActorType     string    `json:"actor_type"`
+	ActorID       *int      `json:"actor_id,omitempty"`
+	Reason        *string   `json:"reason,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// StageDuration is the time an application spent in one stage or status.
+// LeftAt is nil for the stage the application is currently in.
+type StageDuration struct {
+	Stage     string     `json:"stage"`
+	EnteredAt time.Time  `json:"entered_at"`
+	LeftAt    *time.Time `json:"left_at"`
+	Seconds   int64      `json:"seconds"`
+}
@@ -0,0 +1,30 @@
+package schema
+
+import "time"
+
+// ApplicationNote is a private note left by a member of the hiring team
+type ApplicationNote struct {
+	ID            int       `json:"id"`
+	ApplicationID int       `json:"application_id"`
+	EmployerID    int       `json:"employer_id"`
+	AuthorName    *string   `json:"author_name"`
+	Note          string    `json:"note"`
+	Mentions      []int     `json:"mentions"` // Employer IDs mentioned in the note
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// ApplicationRating is one reviewer's 1-5 rating of an application
+type ApplicationRating struct {
+	ApplicationID int       `json:"application_id"`
+	EmployerID    int       `json:"employer_id"`
+	AuthorName    *string   `json:"author_name"`
+	Rating        int       `json:"rating"`
+	RatedAt       time.Time `json:"rated_at"`
+}
+
+// RatingSummary aggregates the ratings given to an application
+type RatingSummary struct {
+	Average *float64            `json:"average"`
+	Count   int                 `json:"count"`
+	Ratings []ApplicationRating `json:"ratings"`
+}
@@ -0,0 +1,24 @@
+package schema
+
+import "time"
+
+// BlindReviewSettings control whether a job's applicants are shown without
+// identifying details. Identity is revealed once an application reaches
+// RevealStageID (or a later stage), or is accepted.
+type BlindReviewSettings struct {
+	JobListingID  int  `json:"job_listing_id"`
+	BlindReview   bool `json:"blind_review"`
+	RevealStageID *int `json:"reveal_stage_id"`
+}
+
+// IdentityReveal records when and why a blind-reviewed candidate's identity
+// was revealed to the hiring team
+type IdentityReveal struct {
+	ID            int       `json:"id"`
+	ApplicationID int       `json:"application_id"`
+	StageID       *int      `json:"stage_id"`
+	Trigger       string    `json:"trigger"` // "stage", "accepted" or "settings"
+	ActorType     string    `json:"actor_type"`
+	ActorID       *int      `json:"actor_id,omitempty"`
+	RevealedAt    time.Time `json:"revealed_at"`
+}
@@ -0,0 +1,37 @@
+package schema
+
+import "time"
+
+// AvailabilityWindow is a period in which a team member can interview
+type AvailabilityWindow struct {
+	ID         int       `json:"id"`
+	EmployerID int       `json:"employer_id"`
+	StartsAt   time.Time `json:"starts_at" binding:"required"`
+	EndsAt     time.Time `json:"ends_at" binding:"required"`
+}
+
+// BookingLink lets a candidate pick an interview slot until it expires or
+// is used. The token is the only credential the candidate needs.
+type BookingLink struct {
+	ID              int        `json:"id"`
+	Token           string     `json:"token,omitempty"`
+	ApplicationID   int        `json:"application_id"`
+	RoundID         *int       `json:"round_id"`
+	InterviewerID   int        `json:"interviewer_id"`
+	CreatedBy       *int       `json:"created_by,omitempty"`
+	DurationMinutes int        `json:"duration_minutes"`
+	InterviewMode   string     `json:"interview_mode"`
+	InterviewLink   *string    `json:"interview_link"`
+	ExpiresAt       time.Time  `json:"expires_at"`
+	UsedAt          *time.Time `json:"used_at,omitempty"`
+	InterviewID     *int       `json:"interview_id,omitempty"`
+	JobSeekerID     int        `json:"-"`
+	JobTitle        string     `json:"job_title"`
+	RoundName       *string    `json:"round_name,omitempty"`
+}
+
+// TimeSlot is a bookable interview time
+type TimeSlot struct {
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+}
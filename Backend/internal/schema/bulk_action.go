@@ -0,0 +1,41 @@
+package schema
+
+// BulkActionRequest applies one action to many applications of the same job.
+// Action is "move" (requires StageID), "reject" (optional Template or
+// TemplateID, Reason, ReasonCode and DelayMinutes) or "tag" (requires Tags).
+type BulkActionRequest struct {
+	JobListingID   int      `json:"job_listing_id" binding:"required"`
+	EmployerID     int      `json:"-"` // The signed-in employer, taken from the token
+	ApplicationIDs []int    `json:"application_ids" binding:"required"`
+	Action         string   `json:"action" binding:"required"`
+	StageID        int      `json:"stage_id"`
+	Template       string   `json:"template"`    // Rejection message with {{placeholders}}
+	TemplateID     *int     `json:"template_id"` // Stored company template, used instead of Template
+	Reason         *string  `json:"reason"`
+	ReasonCode     *string  `json:"reason_code"`
+	DelayMinutes   int      `json:"delay_minutes"` // Hold rejection messages back this long
+	Tags           []string `json:"tags"`
+}
+
+// BulkFailure explains why one application of a bulk action was skipped
+type BulkFailure struct {
+	ApplicationID int    `json:"application_id"`
+	Error         string `json:"error"`
+}
+
+// BulkActionResult summarises a bulk action
+type BulkActionResult struct {
+	Succeeded []int         `json:"succeeded"`
+	Failed    []BulkFailure `json:"failed"`
+}
+
+// ApplicationContact holds what is needed to address a candidate about an application
+type ApplicationContact struct {
+	ApplicationID int    `json:"application_id"`
+	JobSeekerID   int    `json:"job_seeker_id"`
+	FirstName     string `json:"first_name"`
+	LastName      string `json:"last_name"`
+	Email         string `json:"email"`
+	JobTitle      string `json:"job_title"`
+	CompanyName   string `json:"company_name"`
+}
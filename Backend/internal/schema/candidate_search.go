@@ -0,0 +1,56 @@
+package schema
+
+import "time"
+
+// CandidateSearchCriteria filters discoverable job seekers. Empty fields do
+// not filter. A candidate must have every listed skill, each at MinSkillLevel
+// or above when it is set.
+type CandidateSearchCriteria struct {
+	Skills             []string `json:"skills"`
+	MinSkillLevel      string   `json:"min_skill_level"`
+	Location           string   `json:"location"`
+	EducationLevel     string   `json:"education_level"`
+	FieldOfStudy       string   `json:"field_of_study"`
+	MinExperienceYears float64  `json:"min_experience_years"`
+	MaxExperienceYears *float64 `json:"max_experience_years"`
+}
+
+// CandidateSummary is what employers see of a candidate in search results.
+// Contact details are withheld until the candidate applies.
+type CandidateSummary struct {
+	JobSeekerID     int                `json:"job_seeker_id"`
+	FirstName       string             `json:"first_name"`
+	LastName        string             `json:"last_name"`
+	Location        *string            `json:"location"`
+	ExperienceYears float64            `json:"experience_years"`
+	Skills          map[string]string  `json:"skills"` // Skill name to proficiency
+	Education       []EducationDetails `json:"education"`
+}
+
+// CandidateSearchResult is one page of candidate search results
+type CandidateSearchResult struct {
+	Candidates []CandidateSummary `json:"candidates"`
+	Page       int                `json:"page"`
+	PageSize   int                `json:"page_size"`
+	Total      int                `json:"total"`
+}
+
+// SavedCandidateSearch is a named search an employer can run again
+type SavedCandidateSearch struct {
+	ID         int                     `json:"id"`
+	EmployerID int                     `json:"employer_id"`
+	Name       string                  `json:"name" binding:"required"`
+	Criteria   CandidateSearchCriteria `json:"criteria"`
+	CreatedAt  time.Time               `json:"created_at"`
+}
+
+// CandidateInvitation is an employer's invitation for a seeker to apply to a job
+type CandidateInvitation struct {
+	ID           int       `json:"id"`
+	EmployerID   int       `json:"employer_id"`
+	JobSeekerID  int       `json:"job_seeker_id"`
+	JobListingID int       `json:"job_listing_id"`
+	JobTitle     string    `json:"job_title"`
+	Message      *string   `json:"message"`
+	CreatedAt    time.Time `json:"created_at"`
+}
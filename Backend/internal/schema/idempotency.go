@@ -0,0 +1,12 @@
+package schema
+
+// IdempotencyRecord is the stored outcome of a request sent with an
+// Idempotency-Key header. StatusCode is nil while the request is running.
+type IdempotencyRecord struct {
+	Key          string
+	Scope        string
+	RequestHash  string
+	StatusCode   *int
+	ContentType  *string
+	ResponseBody []byte
+}
@@ -5,9 +5,79 @@ import "time"
 type Interview struct {
 	ID              int    `json:"id"`
 	ApplicationID   int    `json:"application_id"`
-	ScheduledDate   time.Time `json:"scheduled_date"`
+	ScheduledDate   time.Time `json:"scheduled_date"`      // Stored in UTC
+	TimeZone        string     `json:"time_zone"`          // IANA zone the interview was scheduled in
 	InterviewMode   string `json:"interview_mode"`
 	Status         string `json:"status"`
 	InterviewerName *string `json:"interviewer_name"`
 	InterviewLink   *string `json:"interview_link"`
-}
\ No newline at end of file
+	RoundID         *int       `json:"round_id"`             // Round of the job's interview plan, if any
+	RoundName       *string    `json:"round_name,omitempty"`
+	Outcome         *string    `json:"outcome,omitempty"`     // Set when the interview is completed
+	Feedback        *string    `json:"feedback,omitempty"`
+	CompletedAt     *time.Time `json:"completed_at,omitempty"`
+	InterviewerID   *int       `json:"interviewer_id"`       // Lead interviewer, who also owns booking links
+	InterviewerIDs  []int      `json:"interviewer_ids"`      // Everyone on the panel, including the lead
+	DurationMinutes int        `json:"duration_minutes"`
+	JobTitle        string     `json:"job_title,omitempty"`
+	Sequence        int        `json:"-"` // iCalendar SEQUENCE, raised on every change
+	StatusReason    *string    `json:"status_reason,omitempty"`     // Why it was cancelled or missed
+	StatusChangedBy *string    `json:"status_changed_by,omitempty"` // job_seeker, employer or system
+	NoShowParty     *string    `json:"no_show_party,omitempty"`     // Who did not attend a No Show interview
+	MeetingProvider *string    `json:"meeting_provider,omitempty"`  // Set when InterviewLink was generated
+	MeetingID       *string    `json:"-"`
+}
+
+// InterviewConflict is a scheduled interview that overlaps the time asked
+// for, either for the candidate or for some of the interviewers. Interviews
+// of other companies only show their time.
+type InterviewConflict struct {
+	InterviewID     *int      `json:"interview_id,omitempty"`
+	ApplicationID   *int      `json:"application_id,omitempty"`
+	ScheduledDate   time.Time `json:"scheduled_date"`
+	DurationMinutes int       `json:"duration_minutes"`
+	Candidate       bool      `json:"candidate"`       // The candidate is already interviewing then
+	InterviewerIDs  []int     `json:"interviewer_ids"` // Interviewers who are already busy then
+}
+
+// InterviewReminder is a reminder due some minutes before an interview
+type InterviewReminder struct {
+	ID            int       `json:"id"`
+	InterviewID   int       `json:"interview_id"`
+	MinutesBefore int       `json:"minutes_before"`
+	SendAt        time.Time `json:"send_at"`
+}
+
+// RescheduleRequest is a candidate asking to move an interview. It stays
+// Pending until the hiring team reschedules (Accepted) or declines it, and is
+// Closed when the interview is cancelled or held first.
+type RescheduleRequest struct {
+	ID            int         `json:"id"`
+	InterviewID   int         `json:"interview_id"`
+	JobSeekerID   int         `json:"job_seeker_id"`
+	Reason        string      `json:"reason"`
+	ProposedTimes []time.Time `json:"proposed_times"`
+	Status        string      `json:"status"`
+	RespondedBy   *int        `json:"responded_by,omitempty"`
+	Response      *string     `json:"response,omitempty"`
+	RespondedAt   *time.Time  `json:"responded_at,omitempty"`
+	CreatedAt     time.Time   `json:"created_at"`
+}
+
+// Outcomes recorded when an interview is completed
+const (
+	OutcomePassed    = "Passed"
+	OutcomeFailed    = "Failed"
+	OutcomeUndecided = "Undecided"
+)
+
+// InterviewRound is one step of a job's interview plan, such as a phone
+// screen or a technical interview. Position follows the plan's order.
+type InterviewRound struct {
+	ID              int     `json:"id"`
+	JobListingID    int     `json:"job_listing_id"`
+	Name            string  `json:"name" binding:"required,max=100"`
+	Position        int     `json:"position"`
+	DurationMinutes *int    `json:"duration_minutes" binding:"omitempty,min=5,max=480"`
+	Description     *string `json:"description"`
+}
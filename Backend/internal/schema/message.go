@@ -0,0 +1,45 @@
+package schema
+
+import "time"
+
+// MessageThread is a conversation about one application between the
+// applicant and the employer who posted the job
+type MessageThread struct {
+	ID            int        `json:"id"`
+	ApplicationID int        `json:"application_id"`
+	Subject       string     `json:"subject"`
+	CreatedByType string     `json:"created_by_type"`
+	CreatedByID   int        `json:"created_by_id"`
+	CreatedAt     time.Time  `json:"created_at"`
+	LastMessageAt *time.Time `json:"last_message_at"`
+	UnreadCount   int        `json:"unread_count"` // Unread by the requesting participant
+}
+
+// Message is one message in a thread. ReadAt is set when the recipient reads it.
+type Message struct {
+	ID          int                 `json:"id"`
+	ThreadID    int                 `json:"thread_id"`
+	SenderType  string              `json:"sender_type"`
+	SenderID    int                 `json:"sender_id"`
+	Body        string              `json:"body"`
+	CreatedAt   time.Time           `json:"created_at"`
+	ReadAt      *time.Time          `json:"read_at"`
+	Attachments []MessageAttachment `json:"attachments"`
+}
+
+// MessageAttachment is a file sent with a message
+type MessageAttachment struct {
+	ID        int    `json:"id"`
+	MessageID int    `json:"message_id"`
+	FileName  string `json:"file_name"`
+	FilePath  string `json:"-"`
+	MimeType  string `json:"mime_type"`
+	SizeBytes int64  `json:"size_bytes"`
+}
+
+// ApplicationParticipants are the only users allowed to message about an application
+type ApplicationParticipants struct {
+	ApplicationID int `json:"application_id"`
+	JobSeekerID   int `json:"job_seeker_id"`
+	EmployerID    int `json:"employer_id"`
+}
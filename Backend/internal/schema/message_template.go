@@ -0,0 +1,44 @@
+package schema
+
+import "time"
+
+// MessageTemplate is a reusable candidate message shared by a company. The
+// body may use the placeholders {{candidate_name}}, {{first_name}},
+// {{job_title}} and {{company}}.
+type MessageTemplate struct {
+	ID        int       `json:"id"`
+	CompanyID int       `json:"company_id"`
+	Name      string    `json:"name"`
+	Body      string    `json:"body"`
+	CreatedBy *int      `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// RejectionReasonCodes are the standard reasons recorded when rejecting an application
+var RejectionReasonCodes = []string{
+	"skills_mismatch",
+	"insufficient_experience",
+	"overqualified",
+	"position_filled",
+	"location_mismatch",
+	"salary_mismatch",
+	"interview_performance",
+	"other",
+}
+
+// ValidRejectionReason reports whether code is one of RejectionReasonCodes
+func ValidRejectionReason(code string) bool {
+	for _, valid := range RejectionReasonCodes {
+		if code == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// RejectionReasonCount is how many of a job's rejected applications carry a reason code
+type RejectionReasonCount struct {
+	ReasonCode string `json:"reason_code"`
+	Count      int    `json:"count"`
+}
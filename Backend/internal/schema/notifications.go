@@ -9,4 +9,15 @@ type Notification struct {
     Message   string    `json:"message" db:"message"`
     IsRead    bool      `json:"is_read" db:"is_read"`
     CreatedAt time.Time `json:"created_at" db:"created_at"`
-}
\ No newline at end of file
+}
+
+// ScheduledNotification is a notification held back until SendAt. It is
+// dropped if the application's status changes before then.
+type ScheduledNotification struct {
+	ID            int       `json:"id"`
+	ApplicationID *int      `json:"application_id,omitempty"`
+	UserID        int       `json:"user_id"`
+	UserType      string    `json:"user_type"`
+	Message       string    `json:"message"`
+	SendAt        time.Time `json:"send_at"`
+}
@@ -0,0 +1,44 @@
+package schema
+
+import "time"
+
+// Offer statuses. An offer starts as a draft, is sent to the candidate and
+// ends accepted, declined, expired or withdrawn by the employer.
+const (
+	OfferDraft     = "Draft"
+	OfferSent      = "Sent"
+	OfferAccepted  = "Accepted"
+	OfferDeclined  = "Declined"
+	OfferExpired   = "Expired"
+	OfferWithdrawn = "Withdrawn"
+)
+
+// Offer is a job offer made on an accepted application
+type Offer struct {
+	ID             int        `json:"id"`
+	ApplicationID  int        `json:"application_id"`
+	EmployerID     *int       `json:"employer_id"`
+	Salary         float64    `json:"salary"`
+	Currency       string     `json:"currency"`
+	StartDate      time.Time  `json:"start_date"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	Notes          *string    `json:"notes,omitempty"`
+	LetterFileName *string    `json:"letter_file_name,omitempty"`
+	LetterPath     *string    `json:"-"`
+	Status         string     `json:"status"`
+	DeclineReason  *string    `json:"decline_reason,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	SentAt         *time.Time `json:"sent_at,omitempty"`
+	RespondedAt    *time.Time `json:"responded_at,omitempty"`
+	JobListingID   int        `json:"job_listing_id"`
+	JobTitle       string     `json:"job_title"`
+	JobSeekerID    int        `json:"job_seeker_id"`
+}
+
+// OfferSettings control how many hires a job needs and whether it is marked
+// Filled once that many offers have been accepted
+type OfferSettings struct {
+	JobListingID   int  `json:"job_listing_id"`
+	Openings       int  `json:"openings"`
+	FillOnAccepted bool `json:"fill_on_accepted"`
+}
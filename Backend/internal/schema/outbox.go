@@ -0,0 +1,47 @@
+package schema
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Outbox message states. Pending messages are retried with backoff until
+// they are sent or run out of attempts and become Dead.
+const (
+	OutboxPending = "Pending"
+	OutboxSent    = "Sent"
+	OutboxDead    = "Dead"
+)
+
+// OutboxMessage is a notification queued for delivery by email, webhook or
+// SMS. Payload holds what the channel sends, including the recipient address.
+type OutboxMessage struct {
+	ID            int             `json:"id"`
+	Channel       string          `json:"channel"`
+	Event         string          `json:"event"`
+	UserID        int             `json:"user_id"`
+	UserType      string          `json:"user_type"`
+	Payload       json.RawMessage `json:"payload"`
+	Status        string          `json:"status"`
+	Attempts      int             `json:"attempts"`
+	NextAttemptAt time.Time       `json:"next_attempt_at"`
+	LastError     *string         `json:"last_error,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+	SentAt        *time.Time      `json:"sent_at,omitempty"`
+}
+
+// Outbox is everything a change announces: the in-app notifications, the
+// messages to deliver and the notifications held back until a later time.
+// It is stored in the same transaction as the change.
+type Outbox struct {
+	Notifications []Notification
+	Messages      []OutboxMessage
+	Scheduled     []ScheduledNotification
+}
+
+// Add appends the notifications and messages of another outbox
+func (o *Outbox) Add(other Outbox) {
+	o.Notifications = append(o.Notifications, other.Notifications...)
+	o.Messages = append(o.Messages, other.Messages...)
+	o.Scheduled = append(o.Scheduled, other.Scheduled...)
+}
@@ -0,0 +1,21 @@
+package schema
+
+// PipelineStage is one step of a hiring pipeline. A stage belongs either to a
+// company (the default pipeline for all of its jobs) or to a single job listing.
+type PipelineStage struct {
+	ID           int    `json:"id"`
+	CompanyID    *int   `json:"company_id,omitempty"`
+	JobListingID *int   `json:"job_listing_id,omitempty"`
+	StageName    string `json:"stage_name" binding:"required"`
+	Position     int    `json:"position"`
+	MappedStatus string `json:"mapped_status" binding:"required"` // Status shown to the job seeker
+}
+
+// StageCount is the number of applications of a job currently in a stage.
+// StageID is nil for applications that have not been placed in a stage yet.
+type StageCount struct {
+	StageID      *int   `json:"stage_id"`
+	StageName    string `json:"stage_name"`
+	MappedStatus string `json:"mapped_status"`
+	Count        int    `json:"count"`
+}
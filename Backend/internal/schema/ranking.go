@@ -0,0 +1,23 @@
+package schema
+
+// ScoreBreakdown explains how an applicant's match score was computed. Each
+// component score is between 0 and 100; components the job has no
+// requirement for are left out of the overall score.
+type ScoreBreakdown struct {
+	SkillScore        *float64 `json:"skill_score"`
+	MatchedSkills     []string `json:"matched_skills"`
+	MissingSkills     []string `json:"missing_skills"`
+	ExperienceScore   *float64 `json:"experience_score"`
+	ExperienceYears   float64  `json:"experience_years"`
+	RequiredYears     float64  `json:"required_years"`
+	EducationScore    *float64 `json:"education_score"`
+	EducationLevel    string   `json:"education_level"`
+	RequiredEducation string   `json:"required_education"`
+}
+
+// RankedApplication is an application with its match score against the job
+type RankedApplication struct {
+	ApplicationDetails
+	Score     float64        `json:"score"`
+	Breakdown ScoreBreakdown `json:"score_breakdown"`
+}
@@ -0,0 +1,26 @@
+package schema
+
+import "time"
+
+// Resume is one uploaded version of a job seeker's resume
+type Resume struct {
+	ID          int       `json:"id"`
+	JobSeekerID int       `json:"job_seeker_id"`
+	Version     int       `json:"version"`
+	FileName    string    `json:"file_name"`
+	FilePath    string    `json:"-"`
+	MimeType    string    `json:"mime_type"`
+	SizeBytes   int64     `json:"size_bytes"`
+	IsDefault   bool      `json:"is_default"`
+	UploadedAt  time.Time `json:"uploaded_at"`
+}
+
+// ProfileDraft is profile data extracted from a resume for the job seeker to
+// review. Nothing is saved until the seeker accepts the (possibly edited) draft.
+type ProfileDraft struct {
+	ResumeID   int          `json:"resume_id"`
+	Education  []Education  `json:"education" binding:"dive"`
+	Experience []Experience `json:"experience" binding:"dive"`
+	Skills     []Skill      `json:"skills" binding:"dive"`
+	Warnings   []string     `json:"warnings,omitempty"`
+}
@@ -0,0 +1,67 @@
+package schema
+
+import "time"
+
+// Recommendations an interviewer can give in their feedback, strongest first
+const (
+	RecommendationStrongHire   = "Strong Hire"
+	RecommendationHire         = "Hire"
+	RecommendationNoHire       = "No Hire"
+	RecommendationStrongNoHire = "Strong No Hire"
+)
+
+// Recommendations lists the valid recommendations in order
+var Recommendations = []string{RecommendationStrongHire, RecommendationHire, RecommendationNoHire, RecommendationStrongNoHire}
+
+// ScorecardCompetency is one competency of a job's scorecard, such as
+// communication or system design, rated from 1 to ScaleMax
+type ScorecardCompetency struct {
+	ID           int     `json:"id"`
+	JobListingID int     `json:"job_listing_id"`
+	Name         string  `json:"name" binding:"required,max=100"`
+	Description  *string `json:"description"`
+	Position     int     `json:"position"`
+	ScaleMax     int     `json:"scale_max" binding:"omitempty,min=2,max=10"` // Defaults to 5
+}
+
+// CompetencyRating is an interviewer's rating of one competency
+type CompetencyRating struct {
+	CompetencyID   int     `json:"competency_id" binding:"required"`
+	CompetencyName string  `json:"competency_name,omitempty"`
+	Rating         int     `json:"rating" binding:"required,min=1"`
+	Comment        *string `json:"comment"`
+}
+
+// InterviewFeedback is the scorecard one interviewer submitted for an interview
+type InterviewFeedback struct {
+	ID              int                `json:"id"`
+	InterviewID     int                `json:"interview_id"`
+	InterviewerID   int                `json:"interviewer_id"`
+	InterviewerName *string            `json:"interviewer_name"`
+	RoundName       *string            `json:"round_name,omitempty"`
+	Recommendation  string             `json:"recommendation"`
+	Notes           *string            `json:"notes"`
+	Ratings         []CompetencyRating `json:"ratings"`
+	SubmittedAt     time.Time          `json:"submitted_at"`
+}
+
+// CompetencyResult aggregates the ratings given to one competency
+type CompetencyResult struct {
+	CompetencyID int      `json:"competency_id"`
+	Name         string   `json:"name"`
+	ScaleMax     int      `json:"scale_max"`
+	Average      *float64 `json:"average"`
+	Count        int      `json:"count"`
+}
+
+// FeedbackSummary aggregates the interview feedback of an application that
+// the viewer may see. AwaitingFeedback lists the interviews the viewer still
+// owes feedback for; until there are none, only their own feedback is shown.
+type FeedbackSummary struct {
+	ApplicationID    int                 `json:"application_id"`
+	Count            int                 `json:"count"`
+	Recommendations  map[string]int      `json:"recommendations"`
+	Competencies     []CompetencyResult  `json:"competencies"`
+	Feedback         []InterviewFeedback `json:"feedback"`
+	AwaitingFeedback []int               `json:"awaiting_feedback"`
+}
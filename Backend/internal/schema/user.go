@@ -13,6 +13,7 @@ type JobSeeker struct {
 	PhoneNumber    *string      `json:"phone_number,omitempty"`
 	LinkedinURL    *string      `json:"linkedin_url,omitempty"`
 	Location       *string      `json:"location,omitempty"`
+	TimeZone       string       `json:"time_zone,omitempty"`
 	Education      []Education  `json:"education,omitempty"`
 	Experience     []Experience `json:"experience,omitempty"`
 	Skills         []Skill      `json:"skills,omitempty"`
@@ -27,6 +28,7 @@ type JobSeekerInput struct {
 	PhoneNumber    *string      `json:"phone_number,omitempty"`
 	LinkedinURL    *string      `json:"linkedin_url,omitempty"`
 	Location       *string      `json:"location,omitempty"`
+	TimeZone       string       `json:"time_zone,omitempty"` // IANA zone; left unchanged when empty
 	Education      []Education  `json:"education,omitempty"`
 	Experience     []Experience `json:"experience,omitempty"`
 	Skills         []Skill      `json:"skills,omitempty"`
@@ -64,6 +66,7 @@ type Employer struct {
 	Description   *string `json:"description,omitempty" db:"description"`
 	ContactPerson *string `json:"contact_person,omitempty" db:"contact_person"`
 	ContactNumber *string `json:"contact_number,omitempty" db:"contact_number"`
+	TimeZone      string  `json:"time_zone,omitempty" db:"time_zone"` // IANA zone; left unchanged when empty
 	// Company information
 	CompanyName string  `json:"company_name" db:"company_name"`
 	Industry    *string `json:"industry,omitempty" db:"industry"`
@@ -90,4 +93,5 @@ type LoginResponse struct {
 	UserID    int    `json:"user_id"`
 	FirstName string `json:"first_name,omitempty"`
 	Email     string `json:"email"`
+	Token     string `json:"token"` // Sent back as "Authorization: Bearer <token>"
 }
\ No newline at end of file
@@ -0,0 +1,31 @@
+package worker
+
+import (
+	"Backend/internal/db"
+	"Backend/internal/middleware"
+	"context"
+	"log"
+	"time"
+)
+
+// idempotencyPurgeInterval is how often expired idempotency keys are removed
+const idempotencyPurgeInterval = time.Hour
+
+// RunIdempotencyPurge removes expired idempotency keys. It blocks until ctx
+// is cancelled.
+func RunIdempotencyPurge(ctx context.Context) {
+	ticker := time.NewTicker(idempotencyPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := db.PurgeIdempotencyKeys(ctx, middleware.IdempotencyKeyTTL); err != nil {
+			log.Println("Failed to purge idempotency keys:", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
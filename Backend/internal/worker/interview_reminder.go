@@ -0,0 +1,99 @@
+package worker
+
+import (
+	"Backend/internal/db"
+	"Backend/internal/helpers"
+	"Backend/internal/notify"
+	"Backend/internal/schema"
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+const (
+	// interviewReminderInterval is how often interview reminders are checked
+	interviewReminderInterval = time.Minute
+	// interviewReminderBatchSize caps how many reminders one claim takes
+	interviewReminderBatchSize = 100
+)
+
+// RunInterviewReminders reminds candidates and interviewers of upcoming
+// interviews. It blocks until ctx is cancelled.
+func RunInterviewReminders(ctx context.Context) {
+	ticker := time.NewTicker(interviewReminderInterval)
+	defer ticker.Stop()
+
+	for {
+		sendInterviewReminders(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func sendInterviewReminders(ctx context.Context) {
+	for {
+		due, err := db.ClaimInterviewReminders(ctx, interviewReminderBatchSize, func(reminder schema.InterviewReminder) (schema.Outbox, error) {
+			return remindInterview(ctx, reminder)
+		})
+		if err != nil {
+			log.Println("Failed to send interview reminders:", err)
+			return
+		}
+		if len(due) < interviewReminderBatchSize {
+			return
+		}
+	}
+}
+
+// remindInterview builds the reminders to the candidate and the panel of an
+// interview, each with the time in their own time zone
+func remindInterview(ctx context.Context, reminder schema.InterviewReminder) (schema.Outbox, error) {
+	var outbox schema.Outbox
+	interview, err := db.GetInterview(ctx, reminder.InterviewID)
+	if err != nil {
+		return outbox, err
+	}
+	application, err := db.GetApplication(ctx, interview.ApplicationID)
+	if err != nil {
+		return outbox, err
+	}
+
+	type recipient struct {
+		userType string
+		userID   int
+	}
+	recipients := []recipient{{"job_seeker", application.JobSeekerID}}
+	for _, interviewerID := range interview.InterviewerIDs {
+		recipients = append(recipients, recipient{"employer", interviewerID})
+	}
+
+	startsIn := fmt.Sprintf("%d minutes", reminder.MinutesBefore)
+	if reminder.MinutesBefore == 60 {
+		startsIn = "1 hour"
+	} else if reminder.MinutesBefore%60 == 0 {
+		startsIn = fmt.Sprintf("%d hours", reminder.MinutesBefore/60)
+	}
+
+	for _, to := range recipients {
+		timeZone, err := db.GetUserTimeZone(ctx, to.userType, to.userID)
+		if err != nil {
+			log.Println("Failed to fetch time zone:", err)
+		}
+		message := fmt.Sprintf("Reminder: the %s interview for application %d starts in %s, on %s.",
+			interview.JobTitle, interview.ApplicationID, startsIn, helpers.FormatInZone(interview.ScheduledDate, timeZone))
+		if interview.InterviewLink != nil && *interview.InterviewLink != "" {
+			message += " Join at " + *interview.InterviewLink
+		}
+		prepared, err := userNotification(ctx, notify.EventInterviewReminder, to.userType, to.userID, message)
+		if err != nil {
+			return outbox, err
+		}
+		outbox.Add(prepared)
+	}
+	return outbox, nil
+}
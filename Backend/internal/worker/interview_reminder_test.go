@@ -0,0 +1,120 @@
+package worker
+
+import (
+	"Backend/config"
+	"Backend/internal/db"
+	"Backend/internal/dbtest"
+	"Backend/internal/schema"
+	"context"
+	"testing"
+	"time"
+)
+
+// pendingReminders returns when the unsent, uncancelled reminders of an
+// interview are due, earliest first
+func pendingReminders(t *testing.T, interviewID int) []time.Time {
+	t.Helper()
+	rows, err := config.DB.Query(context.Background(), `
+		SELECT send_at FROM interview_reminders
+		WHERE interview_id = $1 AND sent_at IS NULL AND cancelled_at IS NULL
+		ORDER BY send_at`, interviewID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var due []time.Time
+	for rows.Next() {
+		var sendAt time.Time
+		if err := rows.Scan(&sendAt); err != nil {
+			t.Fatal(err)
+		}
+		due = append(due, sendAt)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return due
+}
+
+func countRows(t *testing.T, query string, args ...any) int {
+	t.Helper()
+	var n int
+	if err := config.DB.QueryRow(context.Background(), query, args...).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestRescheduleReplacesInterviewReminders(t *testing.T) {
+	dbtest.Require(t)
+	ctx := context.Background()
+
+	interviewerID := dbtest.Employer(t, dbtest.Company(t))
+	seekerID := dbtest.JobSeeker(t)
+	applicationID := dbtest.Application(t, seekerID, dbtest.Job(t, interviewerID), "Applied")
+	actor := schema.Actor{Type: "employer", ID: &interviewerID}
+
+	start := time.Now().Add(72 * time.Hour).Truncate(time.Minute)
+	interview, err := db.ScheduleInterview(ctx, schema.Interview{
+		ApplicationID: applicationID,
+		ScheduledDate: start,
+		InterviewMode: "Video",
+		InterviewerID: &interviewerID,
+	}, actor, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	due := pendingReminders(t, interview.ID)
+	if len(due) != 2 || !due[0].Equal(start.Add(-24*time.Hour)) || !due[1].Equal(start.Add(-time.Hour)) {
+		t.Fatalf("reminders = %v, want a day and an hour before %v", due, start)
+	}
+
+	// Moved to two hours from now, only the hour-before reminder is still ahead
+	moved := time.Now().Add(2 * time.Hour).Truncate(time.Minute)
+	if _, err := db.RescheduleInterview(ctx, interview.ID, moved, "", 0, actor, nil, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	due = pendingReminders(t, interview.ID)
+	if len(due) != 1 || !due[0].Equal(moved.Add(-time.Hour)) {
+		t.Fatalf("reminders = %v, want one an hour before %v", due, moved)
+	}
+	cancelled := countRows(t, `SELECT COUNT(*) FROM interview_reminders WHERE interview_id = $1 AND cancelled_at IS NOT NULL`, interview.ID)
+	if cancelled != 2 {
+		t.Errorf("cancelled reminders = %d, want 2", cancelled)
+	}
+
+	// Make every reminder due; only the replacement may go out, once to the
+	// candidate and once to the interviewer
+	if _, err := config.DB.Exec(ctx, `UPDATE interview_reminders SET send_at = NOW() - INTERVAL '1 minute' WHERE interview_id = $1`, interview.ID); err != nil {
+		t.Fatal(err)
+	}
+	sendInterviewReminders(ctx)
+	for _, to := range []struct {
+		userType string
+		userID   int
+	}{{"job_seeker", seekerID}, {"employer", interviewerID}} {
+		got := countRows(t, `SELECT COUNT(*) FROM notifications WHERE user_type = $1 AND user_id = $2 AND message LIKE 'Reminder:%starts in 1 hour%'`, to.userType, to.userID)
+		if got != 1 {
+			t.Errorf("%s got %d reminders, want 1", to.userType, got)
+		}
+	}
+	if n := countRows(t, `SELECT COUNT(*) FROM notifications WHERE message LIKE 'Reminder:%starts in 24 hours%'`); n != 0 {
+		t.Errorf("sent %d cancelled day-before reminders", n)
+	}
+
+	// Sent reminders are kept when the interview moves again
+	if _, err := db.RescheduleInterview(ctx, interview.ID, moved.Add(24*time.Hour), "", 0, actor, nil, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	sent := countRows(t, `SELECT COUNT(*) FROM interview_reminders WHERE interview_id = $1 AND sent_at IS NOT NULL AND cancelled_at IS NULL`, interview.ID)
+	if sent != 1 {
+		t.Errorf("sent reminders = %d, want 1", sent)
+	}
+	if due = pendingReminders(t, interview.ID); len(due) != 2 {
+		t.Errorf("reminders = %v, want two for the new time", due)
+	}
+	sendInterviewReminders(ctx)
+	if n := countRows(t, `SELECT COUNT(*) FROM notifications WHERE message LIKE 'Reminder:%'`); n != 2 {
+		t.Errorf("notifications = %d, want the 2 sent before", n)
+	}
+}
@@ -0,0 +1,18 @@
+package worker
+
+import (
+	"Backend/internal/notify"
+	"Backend/internal/schema"
+	"context"
+)
+
+// userNotification builds a notification to one user, to be stored in the
+// transaction of the change it is about
+func userNotification(ctx context.Context, event notify.Event, userType string, userID int, message string) (schema.Outbox, error) {
+	return notify.Prepare(ctx, notify.Notification{
+		Event:    event,
+		UserType: userType,
+		UserID:   userID,
+		Message:  message,
+	})
+}
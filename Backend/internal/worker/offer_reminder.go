@@ -0,0 +1,61 @@
+package worker
+
+import (
+	"Backend/internal/db"
+	"Backend/internal/notify"
+	"Backend/internal/schema"
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+const (
+	// offerCheckInterval is how often offers are checked for expiry
+	offerCheckInterval = 15 * time.Minute
+	// offerReminderWindow is how long before expiry the candidate is reminded
+	offerReminderWindow = 48 * time.Hour
+)
+
+// RunOfferReminders expires lapsed offers and reminds candidates of offers
+// that are about to expire. It blocks until ctx is cancelled.
+func RunOfferReminders(ctx context.Context) {
+	ticker := time.NewTicker(offerCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		checkOffers(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func checkOffers(ctx context.Context) {
+	_, err := db.ExpireOffers(ctx, func(offer schema.Offer) (schema.Outbox, error) {
+		message := fmt.Sprintf("Your offer for %s has expired without a response.", offer.JobTitle)
+		outbox, err := userNotification(ctx, notify.EventOfferExpired, "job_seeker", offer.JobSeekerID, message)
+		if err != nil || offer.EmployerID == nil {
+			return outbox, err
+		}
+		message = fmt.Sprintf("The offer on application %d for %s expired without a response.", offer.ApplicationID, offer.JobTitle)
+		employer, err := userNotification(ctx, notify.EventOfferExpired, "employer", *offer.EmployerID, message)
+		outbox.Add(employer)
+		return outbox, err
+	})
+	if err != nil {
+		log.Println("Failed to expire offers:", err)
+	}
+
+	_, err = db.ClaimOfferReminders(ctx, offerReminderWindow, func(offer schema.Offer) (schema.Outbox, error) {
+		message := fmt.Sprintf("Reminder: your offer for %s expires on %s. Please accept or decline it before then.",
+			offer.JobTitle, offer.ExpiresAt.Format("January 2, 2006 15:04"))
+		return userNotification(ctx, notify.EventOfferReminder, "job_seeker", offer.JobSeekerID, message)
+	})
+	if err != nil {
+		log.Println("Failed to send offer reminders:", err)
+	}
+}
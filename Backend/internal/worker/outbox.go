@@ -0,0 +1,106 @@
+package worker
+
+import (
+	"Backend/internal/db"
+	"Backend/internal/notify"
+	"context"
+	"log"
+	"time"
+)
+
+const (
+	// outboxCheckInterval is how often the outbox is checked for due messages
+	outboxCheckInterval = 10 * time.Second
+	// outboxBatchSize is how many messages are claimed at a time
+	outboxBatchSize = 50
+	// outboxLease is how long a claimed message is left alone before it is
+	// tried again, in case its delivery never reported back
+	outboxLease = 5 * time.Minute
+	// outboxMaxAttempts is how many times a message is tried before it is
+	// moved to the dead letters
+	outboxMaxAttempts = 8
+	// outboxBaseBackoff is the wait after the first failure; it doubles with
+	// each further failure up to outboxMaxBackoff
+	outboxBaseBackoff = time.Minute
+	outboxMaxBackoff  = 6 * time.Hour
+	// outboxSentTTL is how long delivered messages are kept for inspection
+	outboxSentTTL = 7 * 24 * time.Hour
+)
+
+// RunOutbox delivers queued emails, webhooks and texts, retrying failures
+// with exponential backoff. It blocks until ctx is cancelled.
+func RunOutbox(ctx context.Context) {
+	ticker := time.NewTicker(outboxCheckInterval)
+	defer ticker.Stop()
+
+	lastPurge := time.Time{}
+	for {
+		deliverOutbox(ctx)
+		if time.Since(lastPurge) >= time.Hour {
+			if _, err := db.PurgeSentOutboxMessages(ctx, outboxSentTTL); err != nil {
+				log.Println("Failed to purge sent outbox messages:", err)
+			}
+			lastPurge = time.Now()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func deliverOutbox(ctx context.Context) {
+	for {
+		due, err := db.ClaimOutboxMessages(ctx, outboxBatchSize, outboxLease)
+		if err != nil {
+			log.Println("Failed to load outbox messages:", err)
+			return
+		}
+		for _, message := range due {
+			err := notify.Deliver(ctx, message)
+			if err == nil {
+				err = db.MarkOutboxMessageSent(ctx, message.ID)
+				if err != nil {
+					log.Println("Failed to mark outbox message as sent:", err)
+				}
+				continue
+			}
+
+			retryAt := outboxRetryAt(message.Attempts, time.Now())
+			if retryAt == nil {
+				log.Printf("Outbox message %d (%s) failed %d times and was moved to the dead letters: %v\n",
+					message.ID, message.Channel, message.Attempts, err)
+			}
+			if err := db.FailOutboxMessage(ctx, message.ID, err.Error(), retryAt); err != nil {
+				log.Println("Failed to record outbox failure:", err)
+			}
+		}
+		if len(due) < outboxBatchSize {
+			return
+		}
+	}
+}
+
+// outboxRetryAt is when a message that failed attempts times is tried again,
+// or nil once it has run out of attempts and becomes a dead letter
+func outboxRetryAt(attempts int, now time.Time) *time.Time {
+	if attempts >= outboxMaxAttempts {
+		return nil
+	}
+	next := now.Add(outboxBackoff(attempts))
+	return &next
+}
+
+// outboxBackoff is the wait before the next try after a message failed attempts times
+func outboxBackoff(attempts int) time.Duration {
+	backoff := outboxBaseBackoff
+	for i := 1; i < attempts && backoff < outboxMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > outboxMaxBackoff {
+		backoff = outboxMaxBackoff
+	}
+	return backoff
+}
@@ -0,0 +1,50 @@
+package worker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOutboxBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{-1, time.Minute},
+		{0, time.Minute},
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{3, 4 * time.Minute},
+		{7, 64 * time.Minute},
+		{9, 256 * time.Minute},
+		{10, outboxMaxBackoff}, // 512 minutes is over the cap
+		{1000, outboxMaxBackoff},
+	}
+
+	for _, tt := range tests {
+		if got := outboxBackoff(tt.attempts); got != tt.want {
+			t.Errorf("outboxBackoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestOutboxRetryAt(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 8, 0, 0, 0, time.UTC)
+
+	for attempts := 1; attempts < outboxMaxAttempts; attempts++ {
+		got := outboxRetryAt(attempts, now)
+		if got == nil {
+			t.Fatalf("attempt %d: message became a dead letter, want a retry", attempts)
+		}
+		if want := now.Add(outboxBackoff(attempts)); !got.Equal(want) {
+			t.Errorf("attempt %d: retry at %v, want %v", attempts, got, want)
+		}
+	}
+
+	// The last allowed attempt moves the message to the dead letters
+	for _, attempts := range []int{outboxMaxAttempts, outboxMaxAttempts + 1} {
+		if got := outboxRetryAt(attempts, now); got != nil {
+			t.Errorf("attempt %d: retry at %v, want a dead letter", attempts, got)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package worker
+
+import (
+	"Backend/internal/db"
+	"Backend/internal/notify"
+	"Backend/internal/schema"
+	"context"
+	"log"
+	"time"
+)
+
+const (
+	// scheduledCheckInterval is how often held-back notifications are checked
+	scheduledCheckInterval = time.Minute
+	// scheduledBatchSize caps how many notifications one check delivers
+	scheduledBatchSize = 100
+)
+
+// RunScheduledNotifications delivers notifications whose send time has come.
+// It blocks until ctx is cancelled.
+func RunScheduledNotifications(ctx context.Context) {
+	ticker := time.NewTicker(scheduledCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		deliverScheduled(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func deliverScheduled(ctx context.Context) {
+	for {
+		due, err := db.ClaimDueNotifications(ctx, scheduledBatchSize, func(notification schema.ScheduledNotification) (schema.Outbox, error) {
+			return userNotification(ctx, notify.EventApplicationStatusChanged, notification.UserType, notification.UserID, notification.Message)
+		})
+		if err != nil {
+			log.Println("Failed to deliver scheduled notifications:", err)
+			return
+		}
+		if len(due) < scheduledBatchSize {
+			return
+		}
+	}
+}
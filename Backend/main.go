@@ -1,12 +1,15 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
+	_ "time/tzdata" // Time zone names work on hosts without a zoneinfo database
 
 	"Backend/config"
 	"Backend/internal/router"
+	"Backend/internal/worker"
 
 	"github.com/joho/godotenv"
 )
@@ -22,6 +25,15 @@ func main() {
 	config.ConnectPSQL()
 	defer config.CloseDB()
 
+	// Background jobs stop with the server
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go worker.RunOfferReminders(ctx)
+	go worker.RunScheduledNotifications(ctx)
+	go worker.RunIdempotencyPurge(ctx)
+	go worker.RunInterviewReminders(ctx)
+	go worker.RunOutbox(ctx)
+
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
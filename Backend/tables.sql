@@ -10,9 +10,25 @@ CREATE TABLE job_seekers (
     profile_picture TEXT DEFAULT NULL,
     phone_number VARCHAR(15) DEFAULT NULL,
     linkedin_url VARCHAR(255) DEFAULT NULL,
+    time_zone VARCHAR(64) NOT NULL DEFAULT 'UTC', -- IANA zone used to show times, e.g. Europe/Berlin
     application_count INT DEFAULT 0,
     interview_count INT DEFAULT 0,
-    result_count INT DEFAULT 0
+    result_count INT DEFAULT 0,
+    discoverable BOOLEAN DEFAULT FALSE -- Opted in to employer candidate search
+);
+
+-- Resumes Table (every uploaded version; at most one default per job seeker)
+CREATE TABLE resumes (
+    id SERIAL PRIMARY KEY,
+    job_seeker_id INT NOT NULL REFERENCES job_seekers(id) ON DELETE CASCADE,
+    version INT NOT NULL,
+    file_name VARCHAR(255) NOT NULL, -- Original name of the uploaded file
+    file_path TEXT NOT NULL, -- Location under uploads/Resumes
+    mime_type VARCHAR(100) NOT NULL CHECK (mime_type IN ('application/pdf', 'application/vnd.openxmlformats-officedocument.wordprocessingml.document')),
+    size_bytes BIGINT NOT NULL,
+    is_default BOOLEAN DEFAULT FALSE,
+    uploaded_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (job_seeker_id, version)
 );
 
 -- Companies Table
@@ -32,7 +48,8 @@ CREATE TABLE employers (
     password VARCHAR(255) NOT NULL,
     description TEXT,
     contact_person VARCHAR(255),
-    contact_number VARCHAR(15)
+    contact_number VARCHAR(15),
+    time_zone VARCHAR(64) NOT NULL DEFAULT 'UTC' -- IANA zone used to show times, e.g. Europe/Berlin
 );
 
 -- Job Listings Table
@@ -90,25 +107,340 @@ CREATE TABLE experience (
     end_date DATE -- NULL if the job is ongoing
 );
 
+-- Pipeline Stages Table (company-wide stages, optionally overridden per job)
+CREATE TABLE pipeline_stages (
+    id SERIAL PRIMARY KEY,
+    company_id INT REFERENCES company(id) ON DELETE CASCADE,
+    job_listing_id INT REFERENCES job_listings(id) ON DELETE CASCADE,
+    stage_name VARCHAR(100) NOT NULL,
+    position INT NOT NULL,
+    mapped_status VARCHAR(50) NOT NULL CHECK (mapped_status IN ('Applied', 'Interview Scheduled', 'Rejected', 'Accepted')),
+    CHECK ((company_id IS NULL) <> (job_listing_id IS NULL))
+);
+
 -- Applications Table (🔹 Status constraint)
 CREATE TABLE applications (
     id SERIAL PRIMARY KEY,
     job_seeker_id INT REFERENCES job_seekers(id) ON DELETE CASCADE,
     job_listing_id INT REFERENCES job_listings(id) ON DELETE CASCADE,
-    application_status VARCHAR(50) DEFAULT 'Applied' CHECK (application_status IN ('Applied', 'Interview Scheduled', 'Rejected', 'Accepted')),
+    application_status VARCHAR(50) DEFAULT 'Applied' CHECK (application_status IN ('Applied', 'Interview Scheduled', 'Rejected', 'Accepted', 'Withdrawn')),
     applied_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    cover_letter TEXT
+    cover_letter TEXT,
+    stage_id INT REFERENCES pipeline_stages(id) ON DELETE SET NULL,
+    reapply_allowed BOOLEAN DEFAULT FALSE, -- Set on withdrawal when the seeker may apply to the job again
+    resume_id INT REFERENCES resumes(id) ON DELETE SET NULL, -- Resume version attached when applying
+    rejection_reason_code VARCHAR(50) CHECK (rejection_reason_code IN (
+        'skills_mismatch', 'insufficient_experience', 'overqualified', 'position_filled',
+        'location_mismatch', 'salary_mismatch', 'interview_performance', 'other'
+    )) -- Set while the application is rejected, for reporting
+);
+
+-- Job Listing Settings Table (per-job review options; kept apart from job_listings so SELECT * readers are unaffected)
+CREATE TABLE job_listing_settings (
+    job_listing_id INT PRIMARY KEY REFERENCES job_listings(id) ON DELETE CASCADE,
+    blind_review BOOLEAN DEFAULT FALSE,
+    reveal_stage_id INT REFERENCES pipeline_stages(id) ON DELETE SET NULL, -- Identity is revealed from this stage onwards
+    openings INT DEFAULT 1 CHECK (openings > 0),
+    fill_on_accepted BOOLEAN DEFAULT FALSE -- Mark the job Filled once offers for all openings are accepted
+);
+
+-- Application Identity Reveals Table (audit of when blind-reviewed candidates were revealed)
+CREATE TABLE application_identity_reveals (
+    id SERIAL PRIMARY KEY,
+    application_id INT UNIQUE NOT NULL REFERENCES applications(id) ON DELETE CASCADE,
+    stage_id INT,
+    trigger VARCHAR(20) NOT NULL CHECK (trigger IN ('stage', 'accepted', 'settings')),
+    actor_type VARCHAR(50) NOT NULL CHECK (actor_type IN ('job_seeker', 'employer', 'system')),
+    actor_id INT,
+    revealed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Saved Candidate Searches Table
+CREATE TABLE saved_candidate_searches (
+    id SERIAL PRIMARY KEY,
+    employer_id INT NOT NULL REFERENCES employers(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    criteria JSONB NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Candidate Invitations Table (one invitation per seeker and job)
+CREATE TABLE candidate_invitations (
+    id SERIAL PRIMARY KEY,
+    employer_id INT NOT NULL REFERENCES employers(id) ON DELETE CASCADE,
+    job_seeker_id INT NOT NULL REFERENCES job_seekers(id) ON DELETE CASCADE,
+    job_listing_id INT NOT NULL REFERENCES job_listings(id) ON DELETE CASCADE,
+    message TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (job_seeker_id, job_listing_id)
+);
+
+-- Message Threads Table (conversations between an applicant and the listing's employer)
+CREATE TABLE message_threads (
+    id SERIAL PRIMARY KEY,
+    application_id INT NOT NULL REFERENCES applications(id) ON DELETE CASCADE,
+    subject VARCHAR(255) NOT NULL,
+    created_by_type VARCHAR(50) NOT NULL CHECK (created_by_type IN ('job_seeker', 'employer')),
+    created_by_id INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Messages Table (read_at is the recipient's read receipt)
+CREATE TABLE messages (
+    id SERIAL PRIMARY KEY,
+    thread_id INT NOT NULL REFERENCES message_threads(id) ON DELETE CASCADE,
+    sender_type VARCHAR(50) NOT NULL CHECK (sender_type IN ('job_seeker', 'employer')),
+    sender_id INT NOT NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    read_at TIMESTAMP DEFAULT NULL
+);
+
+-- Message Attachments Table
+CREATE TABLE message_attachments (
+    id SERIAL PRIMARY KEY,
+    message_id INT NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    file_name VARCHAR(255) NOT NULL,
+    file_path TEXT NOT NULL,
+    mime_type VARCHAR(100) NOT NULL,
+    size_bytes BIGINT NOT NULL
+);
+
+-- Offers Table (job offers made on accepted applications)
+CREATE TABLE offers (
+    id SERIAL PRIMARY KEY,
+    application_id INT NOT NULL REFERENCES applications(id) ON DELETE CASCADE,
+    employer_id INT REFERENCES employers(id) ON DELETE SET NULL,
+    salary NUMERIC NOT NULL CHECK (salary > 0),
+    currency CHAR(3) NOT NULL,
+    start_date DATE NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL, -- Compared against NOW(), so it keeps its offset
+    notes TEXT,
+    letter_file_name VARCHAR(255),
+    letter_path TEXT,
+    status VARCHAR(20) DEFAULT 'Draft' CHECK (status IN ('Draft', 'Sent', 'Accepted', 'Declined', 'Expired', 'Withdrawn')),
+    decline_reason TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    sent_at TIMESTAMPTZ,
+    responded_at TIMESTAMPTZ,
+    reminder_sent_at TIMESTAMPTZ -- Set once the expiry reminder has gone out
+);
+
+-- Message Templates Table (per-company candidate messages with {{placeholders}})
+CREATE TABLE message_templates (
+    id SERIAL PRIMARY KEY,
+    company_id INT NOT NULL REFERENCES company(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    body TEXT NOT NULL,
+    created_by INT REFERENCES employers(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (company_id, name)
+);
+
+-- Scheduled Notifications Table (candidate messages held back until send_at)
+CREATE TABLE scheduled_notifications (
+    id SERIAL PRIMARY KEY,
+    application_id INT REFERENCES applications(id) ON DELETE CASCADE,
+    user_id INT NOT NULL,
+    user_type VARCHAR(50) NOT NULL CHECK (user_type IN ('job_seeker', 'employer')),
+    message TEXT NOT NULL,
+    send_at TIMESTAMPTZ NOT NULL,
+    sent_at TIMESTAMPTZ,
+    cancelled_at TIMESTAMPTZ, -- Set when the application's status changed before sending
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Idempotency Keys Table (stored responses of POST requests sent with an Idempotency-Key header)
+CREATE TABLE idempotency_keys (
+    idempotency_key VARCHAR(255) NOT NULL,
+    scope CHAR(64) NOT NULL, -- SHA-256 of the caller's token, method and path
+    request_hash CHAR(64) NOT NULL, -- SHA-256 of the request body
+    status_code INT, -- NULL while the first request is still running
+    content_type VARCHAR(255),
+    response_body BYTEA,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (idempotency_key, scope)
+);
+
+-- Application History Table (every transition of an application, oldest first)
+CREATE TABLE application_history (
+    id SERIAL PRIMARY KEY,
+    application_id INT NOT NULL REFERENCES applications(id) ON DELETE CASCADE,
+    event_type VARCHAR(50) NOT NULL,
+    from_status VARCHAR(50),
+    to_status VARCHAR(50),
+    stage_id INT,
+    stage_name VARCHAR(100), -- Stage name at the time of the event
+    actor_type VARCHAR(50) NOT NULL CHECK (actor_type IN ('job_seeker', 'employer', 'system')),
+    actor_id INT,
+    reason TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Application Notes Table (private to the hiring team, never shown to the job seeker)
+CREATE TABLE application_notes (
+    id SERIAL PRIMARY KEY,
+    application_id INT NOT NULL REFERENCES applications(id) ON DELETE CASCADE,
+    employer_id INT NOT NULL REFERENCES employers(id) ON DELETE CASCADE,
+    note TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Colleagues @mentioned in an application note
+CREATE TABLE application_note_mentions (
+    note_id INT NOT NULL REFERENCES application_notes(id) ON DELETE CASCADE,
+    employer_id INT NOT NULL REFERENCES employers(id) ON DELETE CASCADE,
+    PRIMARY KEY (note_id, employer_id)
+);
+
+-- Application Ratings Table (one rating per reviewer)
+CREATE TABLE application_ratings (
+    application_id INT NOT NULL REFERENCES applications(id) ON DELETE CASCADE,
+    employer_id INT NOT NULL REFERENCES employers(id) ON DELETE CASCADE,
+    rating INT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    rated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (application_id, employer_id)
+);
+
+-- Application Tags Table (free-form labels set by the hiring team)
+CREATE TABLE application_tags (
+    application_id INT NOT NULL REFERENCES applications(id) ON DELETE CASCADE,
+    tag VARCHAR(50) NOT NULL,
+    PRIMARY KEY (application_id, tag)
+);
+
+-- Interview Plan Rounds Table (ordered interview rounds of a job)
+CREATE TABLE interview_plan_rounds (
+    id SERIAL PRIMARY KEY,
+    job_listing_id INT NOT NULL REFERENCES job_listings(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    position INT NOT NULL,
+    duration_minutes INT CHECK (duration_minutes BETWEEN 5 AND 480),
+    description TEXT
+);
+
+-- Scorecard Competencies Table (what interviewers rate candidates on for a job)
+CREATE TABLE scorecard_competencies (
+    id SERIAL PRIMARY KEY,
+    job_listing_id INT NOT NULL REFERENCES job_listings(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    position INT NOT NULL,
+    scale_max INT NOT NULL DEFAULT 5 CHECK (scale_max BETWEEN 2 AND 10) -- Ratings run from 1 to scale_max
 );
 
 -- Interviews Table (🔹 Status constraint)
 CREATE TABLE interviews (
     id SERIAL PRIMARY KEY,
     application_id INT REFERENCES applications(id) ON DELETE CASCADE,
-    scheduled_date TIMESTAMP,
+    scheduled_date TIMESTAMPTZ, -- Stored in UTC
+    time_zone VARCHAR(64) NOT NULL DEFAULT 'UTC', -- Zone the interview was scheduled in
     interview_mode VARCHAR(50),
-    status VARCHAR(50) DEFAULT 'Scheduled' CHECK (status IN ('Scheduled', 'Completed', 'Cancelled')),
+    status VARCHAR(50) DEFAULT 'Scheduled' CHECK (status IN ('Scheduled', 'Completed', 'Cancelled', 'No Show')),
     interviewer_name VARCHAR(255),
-    interview_link TEXT
+    interview_link TEXT,
+    round_id INT REFERENCES interview_plan_rounds(id), -- Round of the job's interview plan
+    outcome VARCHAR(20) CHECK (outcome IN ('Passed', 'Failed', 'Undecided')),
+    feedback TEXT,
+    completed_at TIMESTAMPTZ,
+    interviewer_id INT REFERENCES employers(id) ON DELETE SET NULL, -- Team member conducting the interview
+    duration_minutes INT DEFAULT 60 CHECK (duration_minutes BETWEEN 5 AND 480),
+    ics_sequence INT NOT NULL DEFAULT 0, -- iCalendar SEQUENCE, raised when the interview changes
+    status_reason TEXT, -- Why the interview was cancelled or missed
+    status_changed_by VARCHAR(20) CHECK (status_changed_by IN ('job_seeker', 'employer', 'system')),
+    no_show_party VARCHAR(20) CHECK (no_show_party IN ('job_seeker', 'employer')), -- Who did not attend a No Show interview
+    meeting_provider VARCHAR(50), -- Provider that generated interview_link; NULL when the employer entered it
+    meeting_id VARCHAR(255) -- Meeting ID at the provider, used to revoke the link
+);
+
+-- Interview Interviewers Table (the panel of an interview; interviews.interviewer_id is its lead)
+CREATE TABLE interview_interviewers (
+    interview_id INT NOT NULL REFERENCES interviews(id) ON DELETE CASCADE,
+    employer_id INT NOT NULL REFERENCES employers(id) ON DELETE CASCADE,
+    PRIMARY KEY (interview_id, employer_id)
+);
+
+-- Interview Reschedule Requests Table (a candidate asking to move an interview; at most one pending per interview)
+CREATE TABLE interview_reschedule_requests (
+    id SERIAL PRIMARY KEY,
+    interview_id INT NOT NULL REFERENCES interviews(id) ON DELETE CASCADE,
+    job_seeker_id INT NOT NULL REFERENCES job_seekers(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    proposed_times TIMESTAMPTZ[] NOT NULL DEFAULT '{}', -- Times that would suit the candidate
+    status VARCHAR(20) NOT NULL DEFAULT 'Pending' CHECK (status IN ('Pending', 'Accepted', 'Declined', 'Closed')), -- Closed when the interview ended otherwise
+    responded_by INT REFERENCES employers(id) ON DELETE SET NULL,
+    response TEXT,
+    responded_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Interview Reminders Table (reminders due before an interview; replaced when it is rescheduled)
+CREATE TABLE interview_reminders (
+    id SERIAL PRIMARY KEY,
+    interview_id INT NOT NULL REFERENCES interviews(id) ON DELETE CASCADE,
+    minutes_before INT NOT NULL CHECK (minutes_before > 0),
+    send_at TIMESTAMPTZ NOT NULL,
+    sent_at TIMESTAMPTZ,
+    cancelled_at TIMESTAMPTZ, -- Set when the interview was rescheduled, cancelled or held
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Interview Feedback Table (one scorecard per interviewer and interview)
+CREATE TABLE interview_feedback (
+    id SERIAL PRIMARY KEY,
+    interview_id INT NOT NULL REFERENCES interviews(id) ON DELETE CASCADE,
+    interviewer_id INT NOT NULL REFERENCES employers(id) ON DELETE CASCADE,
+    recommendation VARCHAR(20) NOT NULL CHECK (recommendation IN ('Strong Hire', 'Hire', 'No Hire', 'Strong No Hire')),
+    notes TEXT,
+    submitted_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (interview_id, interviewer_id)
+);
+
+-- Interview Feedback Scores Table (the competency ratings of a scorecard)
+CREATE TABLE interview_feedback_scores (
+    feedback_id INT NOT NULL REFERENCES interview_feedback(id) ON DELETE CASCADE,
+    competency_id INT NOT NULL REFERENCES scorecard_competencies(id),
+    rating INT NOT NULL CHECK (rating >= 1),
+    comment TEXT,
+    PRIMARY KEY (feedback_id, competency_id)
+);
+
+-- Interviewer Availability Table (windows in which a team member can interview)
+CREATE TABLE interviewer_availability (
+    id SERIAL PRIMARY KEY,
+    employer_id INT NOT NULL REFERENCES employers(id) ON DELETE CASCADE,
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (ends_at > starts_at)
+);
+
+-- Interview Booking Links Table (expiring links for candidates to pick an interview slot)
+CREATE TABLE interview_booking_links (
+    id SERIAL PRIMARY KEY,
+    token VARCHAR(64) NOT NULL UNIQUE,
+    application_id INT NOT NULL REFERENCES applications(id) ON DELETE CASCADE,
+    round_id INT REFERENCES interview_plan_rounds(id) ON DELETE CASCADE,
+    interviewer_id INT NOT NULL REFERENCES employers(id) ON DELETE CASCADE,
+    created_by INT REFERENCES employers(id) ON DELETE SET NULL,
+    duration_minutes INT NOT NULL CHECK (duration_minutes BETWEEN 5 AND 480),
+    interview_mode VARCHAR(50) NOT NULL,
+    interview_link TEXT,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    interview_id INT REFERENCES interviews(id) ON DELETE SET NULL, -- Interview booked through the link
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Calendar Feed Tokens Table (private iCal subscription URLs)
+CREATE TABLE calendar_feed_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL,
+    user_type VARCHAR(20) NOT NULL CHECK (user_type IN ('job_seeker', 'employer')),
+    token VARCHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, user_type)
 );
 
 -- Notifications Table
@@ -121,6 +453,23 @@ CREATE TABLE notifications (
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Outbox Messages Table (emails, webhooks and texts waiting for the outbox worker,
+-- written in the same transaction as the change they announce)
+CREATE TABLE outbox_messages (
+    id SERIAL PRIMARY KEY,
+    channel VARCHAR(20) NOT NULL, -- email, webhook or sms
+    event VARCHAR(60) NOT NULL,
+    user_id INT NOT NULL,
+    user_type VARCHAR(50) NOT NULL CHECK (user_type IN ('job_seeker', 'employer')),
+    payload JSONB NOT NULL, -- Recipient address and content, as the channel sends them
+    status VARCHAR(10) NOT NULL DEFAULT 'Pending' CHECK (status IN ('Pending', 'Sent', 'Dead')),
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    sent_at TIMESTAMPTZ
+);
+
 --  Ensure existing `applicant_count` values are 0 where NULL
 UPDATE job_listings SET applicant_count = 0 WHERE applicant_count IS NULL;
 
@@ -177,18 +526,9 @@ CREATE OR REPLACE FUNCTION apply_for_job(
 ) RETURNS INT AS $$
 DECLARE 
     new_application_id INT;
-    already_applied BOOLEAN;
 BEGIN
-    -- Check if the user has already applied
-    SELECT EXISTS (
-        SELECT 1 FROM applications 
-        WHERE applications.job_seeker_id = p_job_seeker_id 
-        AND applications.job_listing_id = p_job_listing_id
-    ) INTO already_applied;
-
-    IF already_applied THEN
-        RAISE EXCEPTION 'User has already applied for this job';
-    END IF;
+    -- Duplicates are rejected by the uq_applications_seeker_job index, which
+    -- unlike a check before the insert also holds under concurrent requests
 
     -- Insert application
     INSERT INTO applications (
@@ -309,18 +649,24 @@ END;
 $$ LANGUAGE plpgsql;
 
 
---  Trigger to Increment Applicant Count
+--  Trigger to Increment Applicant Count (and decrement it again on withdrawal)
 CREATE OR REPLACE FUNCTION update_applicant_count() RETURNS TRIGGER AS $$
 BEGIN
-    UPDATE job_listings
-    SET applicant_count = COALESCE(applicant_count, 0) + 1
-    WHERE id = NEW.job_listing_id;
+    IF TG_OP = 'INSERT' THEN
+        UPDATE job_listings
+        SET applicant_count = COALESCE(applicant_count, 0) + 1
+        WHERE id = NEW.job_listing_id;
+    ELSIF TG_OP = 'UPDATE' AND OLD.application_status <> 'Withdrawn' AND NEW.application_status = 'Withdrawn' THEN
+        UPDATE job_listings
+        SET applicant_count = GREATEST(COALESCE(applicant_count, 0) - 1, 0)
+        WHERE id = NEW.job_listing_id;
+    END IF;
     RETURN NEW;
 END;
 $$ LANGUAGE plpgsql;
 
 CREATE TRIGGER trigger_update_applicant_count
-AFTER INSERT ON applications
+AFTER INSERT OR UPDATE ON applications
 FOR EACH ROW
 EXECUTE FUNCTION update_applicant_count();
 
@@ -362,26 +708,6 @@ FOR EACH ROW
 EXECUTE FUNCTION close_expired_jobs();
 
 
---  Trigger to Prevent Duplicate Applications
-CREATE OR REPLACE FUNCTION prevent_duplicate_applications() RETURNS TRIGGER AS $$
-BEGIN
-    IF EXISTS (
-        SELECT 1 FROM applications 
-        WHERE job_seeker_id = NEW.job_seeker_id 
-        AND job_listing_id = NEW.job_listing_id
-    ) THEN
-        RAISE EXCEPTION 'You have already applied for this job';
-    END IF;
-    RETURN NEW;
-END;
-$$ LANGUAGE plpgsql;
-
-CREATE TRIGGER trigger_prevent_duplicate_applications
-BEFORE INSERT ON applications
-FOR EACH ROW
-EXECUTE FUNCTION prevent_duplicate_applications();
-
-
 CREATE OR REPLACE FUNCTION update_application_counts()
 RETURNS TRIGGER AS $$
 BEGIN
@@ -391,12 +717,18 @@ BEGIN
         SET application_count = application_count + 1
         WHERE id = NEW.job_seeker_id;
     
-    -- Case 2: Application Status Updated from 'Applied' to 'Approved' or 'Rejected'
-    ELSIF TG_OP = 'UPDATE' AND OLD.application_status = 'Applied' AND NEW.application_status IN ('Approved', 'Rejected') THEN
+    -- Case 2: Pending application ('Applied' or 'Interview Scheduled') updated to 'Accepted' or 'Rejected'
+    ELSIF TG_OP = 'UPDATE' AND OLD.application_status IN ('Applied', 'Interview Scheduled') AND NEW.application_status IN ('Accepted', 'Rejected') THEN
         UPDATE job_seekers
         SET application_count = application_count - 1,
             result_count = result_count + 1
         WHERE id = NEW.job_seeker_id;
+
+    -- Case 3: Pending application withdrawn by the job seeker
+    ELSIF TG_OP = 'UPDATE' AND OLD.application_status IN ('Applied', 'Interview Scheduled') AND NEW.application_status = 'Withdrawn' THEN
+        UPDATE job_seekers
+        SET application_count = GREATEST(application_count - 1, 0)
+        WHERE id = NEW.job_seeker_id;
     END IF;
 
     RETURN NEW;
@@ -422,6 +754,12 @@ BEGIN
         UPDATE job_seekers
         SET interview_count = interview_count - 1
         WHERE id = (SELECT job_seeker_id FROM applications WHERE id = OLD.application_id);
+
+    -- Case 3: Scheduled Interview Cancelled
+    ELSIF TG_OP = 'UPDATE' AND OLD.status = 'Scheduled' AND NEW.status = 'Cancelled' THEN
+        UPDATE job_seekers
+        SET interview_count = GREATEST(interview_count - 1, 0)
+        WHERE id = (SELECT job_seeker_id FROM applications WHERE id = NEW.application_id);
     END IF;
 
     RETURN NEW;
@@ -429,7 +767,7 @@ END;
 $$ LANGUAGE plpgsql;
 
 CREATE TRIGGER interview_count_trigger
-AFTER INSERT OR DELETE ON interviews
+AFTER INSERT OR UPDATE OR DELETE ON interviews
 FOR EACH ROW
 EXECUTE FUNCTION update_interview_counts();
 
@@ -653,3 +991,69 @@ CREATE INDEX IF NOT EXISTS idx_education_institution ON education(institution_na
 
 CREATE INDEX IF NOT EXISTS idx_experience_company_name ON experience(company_name);
 
+CREATE INDEX IF NOT EXISTS idx_pipeline_stages_company ON pipeline_stages(company_id);
+
+CREATE INDEX IF NOT EXISTS idx_pipeline_stages_job ON pipeline_stages(job_listing_id);
+
+CREATE INDEX IF NOT EXISTS idx_applications_stage ON applications(stage_id);
+
+CREATE INDEX IF NOT EXISTS idx_application_history_application ON application_history(application_id, created_at);
+
+CREATE INDEX IF NOT EXISTS idx_application_notes_application ON application_notes(application_id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_resumes_one_default ON resumes(job_seeker_id) WHERE is_default;
+
+CREATE INDEX IF NOT EXISTS idx_applications_resume ON applications(resume_id);
+
+CREATE INDEX IF NOT EXISTS idx_job_seekers_discoverable ON job_seekers(id) WHERE discoverable;
+
+CREATE INDEX IF NOT EXISTS idx_saved_candidate_searches_employer ON saved_candidate_searches(employer_id);
+
+CREATE INDEX IF NOT EXISTS idx_message_threads_application ON message_threads(application_id);
+
+CREATE INDEX IF NOT EXISTS idx_messages_thread ON messages(thread_id, created_at);
+
+CREATE INDEX IF NOT EXISTS idx_messages_unread ON messages(thread_id, sender_type) WHERE read_at IS NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_offers_one_open ON offers(application_id) WHERE status IN ('Draft', 'Sent', 'Accepted');
+
+CREATE INDEX IF NOT EXISTS idx_offers_sent_expiry ON offers(expires_at) WHERE status = 'Sent';
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_notifications_due ON scheduled_notifications(send_at) WHERE sent_at IS NULL AND cancelled_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_applications_rejection_reason ON applications(job_listing_id, rejection_reason_code) WHERE rejection_reason_code IS NOT NULL;
+
+-- One active application per seeker and job; withdrawn applications that allow re-applying don't count
+CREATE UNIQUE INDEX IF NOT EXISTS uq_applications_seeker_job ON applications(job_seeker_id, job_listing_id)
+WHERE NOT (application_status = 'Withdrawn' AND COALESCE(reapply_allowed, FALSE));
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_created ON idempotency_keys(created_at);
+
+CREATE INDEX IF NOT EXISTS idx_interview_plan_rounds_job ON interview_plan_rounds(job_listing_id, position);
+
+-- A round is scheduled once per application; cancelled interviews can be rescheduled
+CREATE UNIQUE INDEX IF NOT EXISTS uq_interviews_application_round ON interviews(application_id, round_id)
+WHERE round_id IS NOT NULL AND status <> 'Cancelled';
+
+CREATE INDEX IF NOT EXISTS idx_interviewer_availability_employer ON interviewer_availability(employer_id, starts_at);
+
+CREATE INDEX IF NOT EXISTS idx_interviews_interviewer ON interviews(interviewer_id, scheduled_date) WHERE status = 'Scheduled';
+
+CREATE INDEX IF NOT EXISTS idx_interview_interviewers_employer ON interview_interviewers(employer_id);
+
+CREATE INDEX IF NOT EXISTS idx_interview_reminders_due ON interview_reminders(send_at) WHERE sent_at IS NULL AND cancelled_at IS NULL;
+
+-- One pending reminder per interview and offset, however many instances sync it
+CREATE UNIQUE INDEX IF NOT EXISTS uq_interview_reminders_pending ON interview_reminders(interview_id, minutes_before)
+WHERE sent_at IS NULL AND cancelled_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_scorecard_competencies_job ON scorecard_competencies(job_listing_id, position);
+
+CREATE INDEX IF NOT EXISTS idx_interview_feedback_scores_competency ON interview_feedback_scores(competency_id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_interview_reschedule_requests_pending ON interview_reschedule_requests(interview_id)
+WHERE status = 'Pending';
+
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_due ON outbox_messages(next_attempt_at) WHERE status = 'Pending';
+
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_status ON outbox_messages(status, created_at);